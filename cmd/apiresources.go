@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var apiResourcesCmd = &cobra.Command{
+	Use:   "api-resources",
+	Short: "Run kubectl api-resources against all contexts",
+	Long: `Run kubectl api-resources against all contexts in parallel. With --matrix, print which resources, CRDs
+included, exist in which context instead, to spot clusters missing an operator or CRD.`,
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if resourceMatrix {
+			return runResourceMatrix(args)
+		}
+		return runCommand("api-resources", args)
+	},
+}
+
+// runResourceMatrix lists the resources of every context with api-resources
+// -o name and prints a RESOURCE row per resource with a column per context
+func runResourceMatrix(args []string) error {
+	for _, arg := range args {
+		if arg == "-o" || arg == "--output" || strings.HasPrefix(arg, "--output=") || strings.HasPrefix(arg, "-o=") {
+			return fmt.Errorf("--matrix: -o is not supported, the matrix is printed as a table")
+		}
+	}
+	contexts, finish, err := setupRun("api-resources", args)
+	if err != nil {
+		return err
+	}
+	defer finish()
+	rewrite, _ := parseContextRewrite(contextRewriteExpr) // validated by setupRun
+
+	var names []string
+	var resources []map[string]bool
+	results := runContexts(contexts, "api-resources", append(append([]string{}, args...), "-o", "name"))
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		found := make(map[string]bool)
+		for _, line := range strings.Split(result.output, "\n") {
+			if name := strings.TrimSpace(line); name != "" {
+				found[name] = true
+			}
+		}
+		names = append(names, rewrite.apply(result.context))
+		resources = append(resources, found)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("--matrix: no context listed its resources")
+	}
+
+	printTable(resourceMatrixRows(names, resources))
+	return checkFailThreshold(results)
+}
+
+// resourceMatrixRows returns the matrix of resources, sorted by name, with
+// yes where a context has the resource, - where it doesn't, and in a final
+// CONTEXTS column how many contexts have it
+func resourceMatrixRows(names []string, resources []map[string]bool) [][]string {
+	seen := make(map[string]bool)
+	var all []string
+	for _, found := range resources {
+		for name := range found {
+			if !seen[name] {
+				seen[name] = true
+				all = append(all, name)
+			}
+		}
+	}
+	sort.Strings(all)
+
+	rows := [][]string{append(append([]string{"RESOURCE"}, names...), "CONTEXTS")}
+	for _, name := range all {
+		row := []string{name}
+		count := 0
+		for _, found := range resources {
+			if found[name] {
+				row = append(row, "yes")
+				count++
+			} else {
+				row = append(row, "-")
+			}
+		}
+		rows = append(rows, append(row, strconv.Itoa(count)+"/"+strconv.Itoa(len(resources))))
+	}
+	return rows
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestResourceMatrixRows(t *testing.T) {
+	rows := resourceMatrixRows([]string{"ctx1", "ctx2"}, []map[string]bool{
+		{"pods": true, "certificates.cert-manager.io": true},
+		{"pods": true},
+	})
+	expected := [][]string{
+		{"RESOURCE", "ctx1", "ctx2", "CONTEXTS"},
+		{"certificates.cert-manager.io", "yes", "-", "1/2"},
+		{"pods", "yes", "yes", "2/2"},
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(expected) {
+		t.Errorf("resourceMatrixRows() = %v, want %v", rows, expected)
+	}
+}
+
+func TestRunResourceMatrix(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2", "ctx3")
+	setGlobal(t, &resourceMatrix, true)
+	fakeKubectl(t, func(args []string) (string, error) {
+		if !strings.Contains(strings.Join(args, " "), "api-resources --namespaced=true -o name") {
+			t.Errorf("kubectl ran %v, want api-resources -o name", args)
+		}
+		switch argValue(args, "--context") {
+		case "ctx1":
+			return "pods\ncertificates.cert-manager.io\n", nil
+		case "ctx2":
+			return "pods\n", nil
+		default:
+			return "Unable to connect to the server", fmt.Errorf("exit status 1")
+		}
+	})
+
+	var output string
+	stderr := captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := apiResourcesCmd.RunE(apiResourcesCmd, []string{"--namespaced=true"}); err != nil {
+				t.Errorf("api-resources --matrix error = %v", err)
+			}
+		})
+	})
+	expected := "RESOURCE                      ctx1  ctx2  CONTEXTS\n" +
+		"certificates.cert-manager.io  yes   -     1/2\n" +
+		"pods                          yes   yes   2/2\n"
+	if output != expected {
+		t.Errorf("api-resources --matrix output =\n%s\nwant\n%s", output, expected)
+	}
+	if !strings.Contains(stderr, "Context ctx3: Error") {
+		t.Errorf("stderr = %q, want the ctx3 error", stderr)
+	}
+}
+
+func TestRunResourceMatrixOutputFlag(t *testing.T) {
+	setGlobal(t, &resourceMatrix, true)
+	if err := apiResourcesCmd.RunE(apiResourcesCmd, []string{"-o", "wide"}); err == nil || !strings.Contains(err.Error(), "-o is not supported") {
+		t.Errorf("api-resources --matrix -o wide error = %v, want -o rejected", err)
+	}
+}
+
+func TestRunResourceMatrixSharedSetup(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	setGlobal(t, &resourceMatrix, true)
+	fakeKubectl(t, func(args []string) (string, error) {
+		if argValue(args, "--context") == "ctx2" {
+			return "Unable to connect to the server", fmt.Errorf("exit status 1")
+		}
+		return "pods\n", nil
+	})
+
+	setGlobal(t, &failThreshold, "0")
+	var err error
+	captureStderr(t, func() {
+		captureStdout(t, func() {
+			err = apiResourcesCmd.RunE(apiResourcesCmd, nil)
+		})
+	})
+	if err == nil || !strings.Contains(err.Error(), "more than --fail-threshold 0") {
+		t.Errorf("api-resources --matrix error = %v, want the --fail-threshold error", err)
+	}
+
+	setGlobal(t, &backend, backendNative)
+	if err := apiResourcesCmd.RunE(apiResourcesCmd, nil); err == nil || !strings.Contains(err.Error(), "--backend=native") {
+		t.Errorf("api-resources --matrix error = %v, want --backend=native rejected", err)
+	}
+}
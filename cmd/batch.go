@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// runBatchesWithDeadline runs the contexts in consecutive batches of
+// --batch-size, in dispatch order, giving each batch --batch-deadline to
+// finish. Contexts still running at the deadline are cancelled, which kills
+// their kubectl, and reported as timed out. The next batch starts once they
+// have exited, so batches never overlap. Unless --kubectl-arg or the command
+// sets its own --request-timeout, kubectl is also given the deadline as its
+// request timeout.
+func runBatchesWithDeadline(contexts []string, subcommand string, argsFor func(context string) []string, onResult func(contextResult), progress *spinner, policy *errorPolicy) []contextResult {
+	size := batchSize
+	if size < 1 {
+		size = 1
+	}
+	locks := clusterLocks(contexts)
+	ready := barrier == barrierNone
+
+	type indexedResult struct {
+		index  int
+		result contextResult
+	}
+	results := make([]contextResult, len(contexts))
+	order := dispatchOrder(contexts)
+	for start := 0; start < len(order); start += size {
+		batch := order[start:min(start+size, len(order))]
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan indexedResult, len(batch))
+		var wg sync.WaitGroup
+		for _, index := range batch {
+			wg.Add(1)
+			go func(index int) {
+				defer wg.Done()
+				if err := policy.skip(); err != nil {
+					done <- indexedResult{index, contextResult{context: contexts[index], err: err}}
+					return
+				}
+				progress.contextStarted()
+				defer progress.contextFinished()
+				extraArgs := withBatchDeadline(argsFor(contexts[index]))
+				done <- indexedResult{index, runContext(ctx, contexts[index], subcommand, extraArgs, locks[contexts[index]])}
+			}(index)
+		}
+
+		finished := make(map[int]bool, len(batch))
+		timer := time.NewTimer(batchDeadline)
+	wait:
+		for len(finished) < len(batch) {
+			select {
+			case r := <-done:
+				finished[r.index] = true
+				results[r.index] = r.result
+				policy.failed(r.result)
+				if onResult != nil && ready {
+					onResult(r.result)
+				}
+			case <-timer.C:
+				break wait
+			}
+		}
+		timer.Stop()
+		cancel()
+		wg.Wait()
+
+		for _, index := range batch {
+			if finished[index] {
+				continue
+			}
+			runLog.Error("context timed out", "context", contexts[index], "batch_deadline", batchDeadline.String())
+			results[index] = contextResult{
+				context: contexts[index],
+				err:     fmt.Errorf("timed out after --batch-deadline %s", batchDeadline),
+			}
+			if onResult != nil && ready {
+				onResult(results[index])
+			}
+		}
+	}
+
+	if onResult != nil && !ready {
+		for _, result := range results {
+			onResult(result)
+		}
+	}
+	return results
+}
+
+// withBatchDeadline adds --batch-deadline as the request timeout to
+// extraArgs, unless a request timeout is already set. Flags after a "--"
+// belong to the command run by exec, so the timeout goes before it.
+func withBatchDeadline(extraArgs []string) []string {
+	flags, command := extraArgs, []string(nil)
+	if dash := slices.Index(extraArgs, "--"); dash != -1 {
+		flags, command = extraArgs[:dash], extraArgs[dash:]
+	}
+	if requestTimeout(append(append([]string{}, kubectlArgs...), flags...)) != "none" {
+		return extraArgs
+	}
+	return append(append(append([]string{}, flags...), "--request-timeout="+batchDeadline.String()), command...)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunBatchesWithDeadline(t *testing.T) {
+	setGlobal(t, &batchSize, 2)
+	setGlobal(t, &batchDeadline, 50*time.Millisecond)
+
+	var mu sync.Mutex
+	timeouts := make(map[string]string)
+	setGlobal(t, &kubectlExec, func(ctx context.Context, args []string) (string, string, error) {
+		context := argValue(args, "--context")
+		mu.Lock()
+		for _, arg := range args {
+			if value, ok := strings.CutPrefix(arg, "--request-timeout="); ok {
+				timeouts[context] = value
+			}
+		}
+		mu.Unlock()
+		if context == "ctx2" {
+			<-ctx.Done()
+			return "", "", ctx.Err()
+		}
+		return "NAME\npod1\n", "", nil
+	})
+
+	var emitted []string
+	results := runContextsWithEmitter([]string{"ctx1", "ctx2", "ctx3", "ctx4"}, "get", []string{"pods"}, func(result contextResult) {
+		emitted = append(emitted, result.context)
+	})
+
+	for _, result := range results {
+		if result.context == "ctx2" {
+			if result.err == nil || !strings.Contains(result.err.Error(), "timed out after --batch-deadline 50ms") {
+				t.Errorf("ctx2 error = %v, want batch deadline timeout", result.err)
+			}
+			continue
+		}
+		if result.err != nil || result.output != "NAME\npod1\n" {
+			t.Errorf("context %s = %q, %v, want output", result.context, result.output, result.err)
+		}
+	}
+	// The first batch, including the timed-out ctx2, is reported before the second starts
+	sort.Strings(emitted[2:])
+	if !reflect.DeepEqual(emitted, []string{"ctx1", "ctx2", "ctx3", "ctx4"}) {
+		t.Errorf("emitted = %v, want ctx1 and ctx2 before ctx3 and ctx4", emitted)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if timeouts["ctx3"] != "50ms" {
+		t.Errorf("--request-timeout = %q, want 50ms", timeouts["ctx3"])
+	}
+}
+
+func TestRunBatchesWithDeadlineKeepsRequestTimeout(t *testing.T) {
+	setGlobal(t, &batchDeadline, time.Second)
+	setGlobal(t, &kubectlArgs, []string{"--request-timeout=5s"})
+	var got []string
+	fakeKubectl(t, func(args []string) (string, error) {
+		got = args
+		return "", nil
+	})
+
+	runContexts([]string{"ctx1"}, "get", []string{"pods"})
+	if strings.Count(strings.Join(got, " "), "--request-timeout") != 1 {
+		t.Errorf("kubectl args = %v, want only the configured --request-timeout", got)
+	}
+}
+
+func TestWithBatchDeadline(t *testing.T) {
+	setGlobal(t, &batchDeadline, time.Second)
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{args: []string{"pods"}, expected: []string{"pods", "--request-timeout=1s"}},
+		{args: []string{"web-0", "--", "date"}, expected: []string{"web-0", "--request-timeout=1s", "--", "date"}},
+		{args: []string{"web-0", "--", "curl", "--request-timeout", "5"}, expected: []string{"web-0", "--request-timeout=1s", "--", "curl", "--request-timeout", "5"}},
+		{args: []string{"pods", "--request-timeout=5s"}, expected: []string{"pods", "--request-timeout=5s"}},
+	}
+	for _, tt := range tests {
+		if got := withBatchDeadline(tt.args); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("withBatchDeadline(%v) = %v, want %v", tt.args, got, tt.expected)
+		}
+	}
+}
+
+func TestRunBatchesWithDeadlineCancelsBeforeNextBatch(t *testing.T) {
+	setGlobal(t, &batchSize, 2)
+	setGlobal(t, &batchDeadline, 50*time.Millisecond)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	var cancelled []string
+	setGlobal(t, &kubectlExec, func(ctx context.Context, args []string) (string, string, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		if context := argValue(args, "--context"); context == "slow1" || context == "slow2" {
+			<-ctx.Done()
+			mu.Lock()
+			cancelled = append(cancelled, context)
+			mu.Unlock()
+			// Exiting takes a while, as a killed kubectl does
+			time.Sleep(20 * time.Millisecond)
+			return "", "", ctx.Err()
+		}
+		time.Sleep(10 * time.Millisecond)
+		return "NAME\npod1\n", "", nil
+	})
+
+	results := runContexts([]string{"slow1", "slow2", "ctx3", "ctx4"}, "get", []string{"pods"})
+	if results[0].err == nil || results[1].err == nil {
+		t.Errorf("slow contexts = %v, %v, want timeouts", results[0].err, results[1].err)
+	}
+	if results[2].err != nil || results[3].err != nil {
+		t.Errorf("second batch = %v, %v, want success", results[2].err, results[3].err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Strings(cancelled)
+	if !reflect.DeepEqual(cancelled, []string{"slow1", "slow2"}) {
+		t.Errorf("cancelled = %v, want both slow contexts", cancelled)
+	}
+	if peak > 2 {
+		t.Errorf("%d contexts ran at once, want at most --batch-size 2", peak)
+	}
+}
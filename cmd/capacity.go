@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var topCmd = &cobra.Command{
+	Use:                "top",
+	Short:              "Run kubectl top against all contexts",
+	Long:               `Run kubectl top command against all contexts in parallel. With --fleet-capacity, top nodes prints the used and allocatable CPU and memory of every context instead.`,
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCommand("top", args)
+	},
+}
+
+// clusterCapacity is the CPU (in millicores) and memory (in bytes) of a
+// context's nodes. The used values are only known when metrics are.
+type clusterCapacity struct {
+	nodes             int
+	cpuAllocatable    int64
+	memoryAllocatable int64
+	cpuUsed           int64
+	memoryUsed        int64
+	hasMetrics        bool
+}
+
+// topOnlyFlags are the top nodes flags get nodes does not accept, or that
+// would change the output --fleet-capacity parses, with whether they take a
+// separate value
+var topOnlyFlags = map[string]bool{
+	"--sort-by":              true,
+	"--show-capacity":        false,
+	"--use-protocol-buffers": false,
+	"--no-headers":           false,
+}
+
+// isTopNodes reports whether args are those of top nodes
+func isTopNodes(args []string) bool {
+	return len(args) > 0 && (args[0] == "nodes" || args[0] == "node" || args[0] == "no")
+}
+
+// withoutTopFlags returns args without topOnlyFlags, in any of their forms
+func withoutTopFlags(args []string) []string {
+	var kept []string
+	for i := 0; i < len(args); i++ {
+		name, _, hasValue := strings.Cut(args[i], "=")
+		takesValue, ok := topOnlyFlags[name]
+		if !ok {
+			kept = append(kept, args[i])
+			continue
+		}
+		if takesValue && !hasValue {
+			i++
+		}
+	}
+	return kept
+}
+
+// printFleetCapacity combines top nodes (usage) and get nodes (allocatable) of
+// every context into a utilization table with a fleet total. Both are given
+// the user's args, such as a node selector. The get nodes results are
+// returned, since a context without them is a failed one.
+func printFleetCapacity(contexts []string, args []string, rewrite *contextRewrite) ([]contextResult, error) {
+	nodeArgs := append(removeOutputFlag(withoutTopFlags(args)), "-o", "json")
+	usage := runContexts(contexts, "top", withoutTopFlags(args))
+	nodes := runContexts(contexts, "get", nodeArgs)
+	nodes = rerunFailedContexts(nodes, "get", nodeArgs)
+
+	var capacities []clusterCapacity
+	var names []string
+	for i, result := range nodes {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		capacity, err := parseAllocatable(result.output)
+		if err != nil {
+			return nil, fmt.Errorf("context %s: %w", result.context, err)
+		}
+		if usage[i].err != nil {
+			_, message := parseKubectlError(usage[i].output, usage[i].err)
+			notef("Context %s: showing capacity only, no metrics: %s", result.context, message)
+		} else {
+			if capacity.cpuUsed, capacity.memoryUsed, err = parseTopUsage(usage[i].output); err != nil {
+				return nil, fmt.Errorf("context %s: %w", result.context, err)
+			}
+			capacity.hasMetrics = true
+		}
+		capacities = append(capacities, capacity)
+		names = append(names, rewrite.apply(result.context))
+	}
+
+	printTable(capacityRows(names, capacities))
+	return nodes, nil
+}
+
+// capacityRows returns the utilization table of the contexts and a TOTAL row.
+// The total usage covers the contexts with metrics only, and so do its
+// percentages.
+func capacityRows(names []string, capacities []clusterCapacity) [][]string {
+	rows := [][]string{{contextHeader, "NODES", "CPU USED", "CPU ALLOCATABLE", "CPU%", "MEMORY USED", "MEMORY ALLOCATABLE", "MEMORY%"}}
+	var total, measured clusterCapacity
+	for i, c := range capacities {
+		rows = append(rows, capacityRow(names[i], c))
+		total.nodes += c.nodes
+		total.cpuAllocatable += c.cpuAllocatable
+		total.memoryAllocatable += c.memoryAllocatable
+		if c.hasMetrics {
+			measured.cpuAllocatable += c.cpuAllocatable
+			measured.memoryAllocatable += c.memoryAllocatable
+			total.cpuUsed += c.cpuUsed
+			total.memoryUsed += c.memoryUsed
+			total.hasMetrics = true
+		}
+	}
+
+	row := capacityRow("TOTAL", total)
+	if total.hasMetrics {
+		row[4] = percent(total.cpuUsed, measured.cpuAllocatable)
+		row[7] = percent(total.memoryUsed, measured.memoryAllocatable)
+	}
+	return append(rows, row)
+}
+
+func capacityRow(name string, c clusterCapacity) []string {
+	row := []string{name, strconv.Itoa(c.nodes), "-", formatMillicores(c.cpuAllocatable), "-", "-", formatMebibytes(c.memoryAllocatable), "-"}
+	if c.hasMetrics {
+		row[2] = formatMillicores(c.cpuUsed)
+		row[4] = percent(c.cpuUsed, c.cpuAllocatable)
+		row[5] = formatMebibytes(c.memoryUsed)
+		row[7] = percent(c.memoryUsed, c.memoryAllocatable)
+	}
+	return row
+}
+
+// parseAllocatable sums the allocatable CPU and memory of get nodes -o json output
+func parseAllocatable(output string) (clusterCapacity, error) {
+	var list struct {
+		Items []struct {
+			Status struct {
+				Allocatable map[string]string `json:"allocatable"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return clusterCapacity{}, fmt.Errorf("failed to parse nodes: %w", err)
+	}
+
+	capacity := clusterCapacity{nodes: len(list.Items)}
+	for _, node := range list.Items {
+		cpu, err := parseQuantity(node.Status.Allocatable["cpu"])
+		if err != nil {
+			return clusterCapacity{}, err
+		}
+		memory, err := parseQuantity(node.Status.Allocatable["memory"])
+		if err != nil {
+			return clusterCapacity{}, err
+		}
+		capacity.cpuAllocatable += cpu.MilliValue()
+		capacity.memoryAllocatable += memory.Value()
+	}
+	return capacity, nil
+}
+
+// parseTopUsage sums the CPU (in millicores) and memory (in bytes) columns of
+// top nodes or top pods output
+func parseTopUsage(output string) (cpu, memory int64, err error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	columns := parseHeader(lines[0])
+	cpuIdx, memoryIdx := columnIndex(columns, "CPU(cores)"), columnIndex(columns, "MEMORY(bytes)")
+	if cpuIdx == -1 || memoryIdx == -1 {
+		return 0, 0, fmt.Errorf("unexpected top output: %q", lines[0])
+	}
+	for _, line := range lines[1:] {
+		cells := splitRow(columns, line)
+		if cells[cpuIdx] == "<unknown>" || cells[memoryIdx] == "<unknown>" {
+			continue // nodes without metrics yet
+		}
+		c, err := parseQuantity(cells[cpuIdx])
+		if err != nil {
+			return 0, 0, err
+		}
+		m, err := parseQuantity(cells[memoryIdx])
+		if err != nil {
+			return 0, 0, err
+		}
+		cpu += c.MilliValue()
+		memory += m.Value()
+	}
+	return cpu, memory, nil
+}
+
+// appendTopTotals returns a context's top nodes or top pods table with a
+// TOTAL row of its CPU and memory, labeled in the NAME column so it does not
+// pose as a namespace with -A. Other output is returned unchanged.
+func appendTopTotals(output string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return output, nil
+	}
+	columns := parseHeader(lines[0])
+	cpuIdx, memoryIdx := columnIndex(columns, "CPU(cores)"), columnIndex(columns, "MEMORY(bytes)")
+	if cpuIdx == -1 || memoryIdx == -1 {
+		return output, nil
+	}
+	cpu, memory, err := parseTopUsage(output)
+	if err != nil {
+		return "", err
+	}
+
+	total := make([]string, len(columns))
+	total[max(columnIndex(columns, "NAME"), 0)] = "TOTAL"
+	total[cpuIdx] = formatMillicores(cpu)
+	total[memoryIdx] = formatMebibytes(memory)
+	rows := [][]string{columnNames(columns)}
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) != "" {
+			rows = append(rows, splitRow(columns, line))
+		}
+	}
+	return strings.Join(renderTableRows(append(rows, total)), "\n") + "\n", nil
+}
+
+// parseQuantity parses a Kubernetes quantity such as 3920m or 16Gi; an
+// empty value is zero
+func parseQuantity(value string) (resource.Quantity, error) {
+	if value == "" {
+		return resource.Quantity{}, nil
+	}
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return q, fmt.Errorf("invalid quantity %q: %w", value, err)
+	}
+	return q, nil
+}
+
+func formatMillicores(m int64) string {
+	return fmt.Sprintf("%dm", m)
+}
+
+func formatMebibytes(bytes int64) string {
+	return fmt.Sprintf("%dMi", bytes/(1<<20))
+}
+
+// percent returns used as a rounded percentage of total, like kubectl top
+func percent(used, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%%", (used*100+total/2)/total)
+}
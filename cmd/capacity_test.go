@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testNodesJSON = `{"items": [
+	{"status": {"allocatable": {"cpu": "3920m", "memory": "16Gi"}}},
+	{"status": {"allocatable": {"cpu": "4", "memory": "16384Mi"}}}
+]}`
+
+func TestParseAllocatable(t *testing.T) {
+	capacity, err := parseAllocatable(testNodesJSON)
+	if err != nil {
+		t.Fatalf("parseAllocatable() error = %v", err)
+	}
+	expected := clusterCapacity{nodes: 2, cpuAllocatable: 7920, memoryAllocatable: 32 << 30}
+	if capacity != expected {
+		t.Errorf("parseAllocatable() = %+v, want %+v", capacity, expected)
+	}
+}
+
+func TestParseTopUsage(t *testing.T) {
+	output := "NAME     CPU(cores)   CPU%   MEMORY(bytes)   MEMORY%\n" +
+		"node-a   250m         6%     4096Mi          25%\n" +
+		"node-b   1            25%    2Gi             12%\n" +
+		"node-c   <unknown>    <unknown>   <unknown>   <unknown>\n"
+	cpu, memory, err := parseTopUsage(output)
+	if err != nil {
+		t.Fatalf("parseTopUsage() error = %v", err)
+	}
+	if cpu != 1250 || memory != 6<<30 {
+		t.Errorf("parseTopUsage() = %d, %d, want 1250 and %d", cpu, memory, int64(6<<30))
+	}
+
+	if _, _, err := parseTopUsage("NAME   STATUS\nnode-a   Ready"); err == nil {
+		t.Errorf("parseTopUsage() expected error for non-top output")
+	}
+}
+
+func TestCapacityRows(t *testing.T) {
+	capacities := []clusterCapacity{
+		{nodes: 2, cpuAllocatable: 8000, memoryAllocatable: 32 << 30, cpuUsed: 2000, memoryUsed: 8 << 30, hasMetrics: true},
+		{nodes: 1, cpuAllocatable: 2000, memoryAllocatable: 8 << 30, cpuUsed: 1500, memoryUsed: 6 << 30, hasMetrics: true},
+		{nodes: 3, cpuAllocatable: 12000, memoryAllocatable: 48 << 30},
+	}
+
+	rows := capacityRows([]string{"ctx1", "ctx2", "ctx3"}, capacities)
+	expected := [][]string{
+		{"CONTEXT", "NODES", "CPU USED", "CPU ALLOCATABLE", "CPU%", "MEMORY USED", "MEMORY ALLOCATABLE", "MEMORY%"},
+		{"ctx1", "2", "2000m", "8000m", "25%", "8192Mi", "32768Mi", "25%"},
+		{"ctx2", "1", "1500m", "2000m", "75%", "6144Mi", "8192Mi", "75%"},
+		{"ctx3", "3", "-", "12000m", "-", "-", "49152Mi", "-"},
+		// The fleet usage is measured against the contexts with metrics only
+		{"TOTAL", "6", "3500m", "22000m", "35%", "14336Mi", "90112Mi", "35%"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("capacityRows() =\n%v\nwant\n%v", rows, expected)
+	}
+}
+
+func TestRunFleetCapacity(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	setGlobal(t, &fleetCapacity, true)
+	fakeKubectl(t, func(args []string) (string, error) {
+		context := argValue(args, "--context")
+		if argValue(args, "-l") != "role=worker" || argValue(args, "--sort-by") != "" {
+			t.Errorf("kubectl args = %v, want the selector without --sort-by", args)
+		}
+		if strings.Contains(strings.Join(args, " "), "top nodes") {
+			if context == "ctx2" {
+				return "error: Metrics API not available\n", fmt.Errorf("exit status 1")
+			}
+			return "NAME     CPU(cores)   CPU%   MEMORY(bytes)   MEMORY%\nnode-a   3960m        50%    16Gi            50%\n", nil
+		}
+		return testNodesJSON, nil
+	})
+
+	var output string
+	stderr := captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := runCommand("top", []string{"nodes", "-l", "role=worker", "--sort-by=cpu"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("output =\n%s\nwant a header, two contexts and a total", output)
+	}
+	if fields := strings.Fields(lines[1]); fields[4] != "50%" || fields[7] != "50%" {
+		t.Errorf("ctx1 row = %q, want 50%% CPU and memory", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); fields[2] != "-" || fields[3] != "7920m" {
+		t.Errorf("ctx2 row = %q, want capacity only", lines[2])
+	}
+	if !strings.Contains(stderr, "Context ctx2: showing capacity only") {
+		t.Errorf("stderr = %q, want a note about missing metrics", stderr)
+	}
+
+	if err := runCommand("top", []string{"pods"}); err == nil {
+		t.Errorf("runCommand(top pods) expected error")
+	}
+}
+
+func TestRunFleetCapacityFailThreshold(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	setGlobal(t, &fleetCapacity, true)
+	setGlobal(t, &failThreshold, "0")
+	fakeKubectl(t, func(args []string) (string, error) {
+		if argValue(args, "--context") == "ctx2" {
+			return "Unable to connect to the server\n", fmt.Errorf("exit status 1")
+		}
+		if strings.Contains(strings.Join(args, " "), "top nodes") {
+			return "NAME     CPU(cores)   CPU%   MEMORY(bytes)   MEMORY%\nnode-a   3960m        50%    16Gi            50%\n", nil
+		}
+		return testNodesJSON, nil
+	})
+
+	var err error
+	captureStderr(t, func() {
+		captureStdout(t, func() {
+			err = runCommand("top", []string{"nodes"})
+		})
+	})
+	if err == nil || !strings.Contains(err.Error(), "fail-threshold") {
+		t.Errorf("runCommand() error = %v, want the --fail-threshold error", err)
+	}
+}
+
+func TestAppendTopTotals(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name:   "top nodes",
+			output: "NAME     CPU(cores)   CPU%   MEMORY(bytes)   MEMORY%\nnode-1   250m         12%    1024Mi          25%\nnode-2   1            50%    2Gi             50%\n",
+			expected: "NAME     CPU(cores)   CPU%   MEMORY(bytes)   MEMORY%\n" +
+				"node-1   250m         12%    1024Mi          25%\n" +
+				"node-2   1            50%    2Gi             50%\n" +
+				"TOTAL    1250m               3072Mi\n",
+		},
+		{
+			name:   "top pods in all namespaces",
+			output: "NAMESPACE   NAME    CPU(cores)   MEMORY(bytes)\napps        web-0   5m           64Mi\n",
+			expected: "NAMESPACE   NAME    CPU(cores)   MEMORY(bytes)\n" +
+				"apps        web-0   5m           64Mi\n" +
+				"            TOTAL   5m           64Mi\n",
+		},
+		{name: "not a top table", output: "NAME    READY\nweb-0   1/1\n", expected: "NAME    READY\nweb-0   1/1\n"},
+		{name: "header only", output: "NAME   CPU(cores)   MEMORY(bytes)\n", expected: "NAME   CPU(cores)   MEMORY(bytes)\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := appendTopTotals(tt.output)
+			if err != nil {
+				t.Fatalf("appendTopTotals() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("appendTopTotals() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunCommandTopTotals(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	setGlobal(t, &topTotals, true)
+	fakeKubectl(t, func(args []string) (string, error) {
+		return "NAME    CPU(cores)   MEMORY(bytes)\nweb-0   5m           64Mi\nweb-1   15m          64Mi\n", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("top", []string{"pods"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+	if strings.Count(output, "TOTAL") != 2 || !strings.Contains(output, "ctx2     TOTAL   20m          128Mi") {
+		t.Errorf("runCommand() output =\n%s\nwant a TOTAL row per context", output)
+	}
+
+	if err := runCommand("get", []string{"pods"}); err == nil || !strings.Contains(err.Error(), "--totals is only supported by the top command") {
+		t.Errorf("runCommand(get) error = %v, want --totals rejected", err)
+	}
+}
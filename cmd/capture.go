@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// kubectlCapture is the file written by --capture-raw and read by
+// --input-file: the contexts of a run and every kubectl invocation
+type kubectlCapture struct {
+	Contexts []string       `json:"contexts"`
+	Calls    []recordedCall `json:"calls"`
+}
+
+// recordedCall is one kubectl invocation and what it returned
+type recordedCall struct {
+	Args   []string `json:"args"`
+	Stdout string   `json:"stdout"`
+	Stderr string   `json:"stderr,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// startCapture records the kubectl invocations of a run against contexts.
+// The returned function stops recording and writes them to --capture-raw.
+func startCapture(contexts []string) func() error {
+	capture := &kubectlCapture{Contexts: contexts, Calls: []recordedCall{}}
+	var mu sync.Mutex
+	run := kubectlExec
+	kubectlExec = func(ctx context.Context, args []string) (string, string, error) {
+		stdout, stderr, err := run(ctx, args)
+		call := recordedCall{Args: args, Stdout: stdout, Stderr: stderr}
+		if err != nil {
+			call.Error = err.Error()
+		}
+		mu.Lock()
+		capture.Calls = append(capture.Calls, call)
+		mu.Unlock()
+		return stdout, stderr, err
+	}
+
+	return func() error {
+		defer func() { kubectlExec = run }()
+		data, err := json.MarshalIndent(capture, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal capture: %w", err)
+		}
+		if err := os.WriteFile(captureRaw, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write --capture-raw file: %w", err)
+		}
+		return nil
+	}
+}
+
+// replayCapture makes kubectl invocations return what was recorded in a
+// --capture-raw file instead of contacting any cluster, and returns the
+// recorded contexts. Repeated invocations, such as reruns, replay the
+// recordings in turn, repeating the last one. The caller restores kubectlExec
+// when the run ends, as loadContexts does.
+func replayCapture(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --input-file: %w", err)
+	}
+	var capture kubectlCapture
+	if err := json.Unmarshal(data, &capture); err != nil {
+		return nil, fmt.Errorf("failed to parse --input-file %s: %w", path, err)
+	}
+	if len(capture.Contexts) == 0 {
+		return nil, fmt.Errorf("--input-file %s records no contexts", path)
+	}
+
+	recorded := make(map[string][]recordedCall)
+	for _, call := range capture.Calls {
+		key := strings.Join(call.Args, "\x00")
+		recorded[key] = append(recorded[key], call)
+	}
+	var mu sync.Mutex
+	kubectlExec = func(_ context.Context, args []string) (string, string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		key := strings.Join(args, "\x00")
+		calls := recorded[key]
+		if len(calls) == 0 {
+			return "", "", fmt.Errorf("kubectl %s was not recorded in %s", strings.Join(args, " "), path)
+		}
+		if len(calls) > 1 {
+			recorded[key] = calls[1:]
+		}
+		call := calls[0]
+		if call.Error != "" {
+			return call.Stdout, call.Stderr, errors.New(call.Error)
+		}
+		return call.Stdout, call.Stderr, nil
+	}
+	return capture.Contexts, nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCaptureReplayRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "table", args: []string{"pods"}},
+		{name: "json", args: []string{"pods", "-o", "json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "capture.json")
+			writeKubeconfig(t, "ctx1", "ctx2", "ctx3")
+			fakeKubectl(t, func(args []string) (string, error) {
+				switch argValue(args, "--context") {
+				case "ctx3":
+					return "Unable to connect to the server", fmt.Errorf("exit status 1")
+				case "ctx2":
+					if tt.name == "json" {
+						return `{"items": [{"metadata": {"name": "api"}}]}`, nil
+					}
+					return "NAME   READY\napi    1/1\n", nil
+				}
+				if tt.name == "json" {
+					return `{"items": [{"metadata": {"name": "web"}}]}`, nil
+				}
+				return "NAME   READY\nweb    1/1\n", nil
+			})
+
+			run := func() (string, string) {
+				before := fmt.Sprintf("%p", kubectlExec)
+				var stderr string
+				stdout := captureStdout(t, func() {
+					stderr = captureStderr(t, func() {
+						if err := runCommand("get", tt.args); err != nil {
+							t.Errorf("runCommand() error = %v", err)
+						}
+					})
+				})
+				if fmt.Sprintf("%p", kubectlExec) != before {
+					t.Errorf("kubectlExec was not restored after the run")
+				}
+				return stdout, stderr
+			}
+
+			setGlobal(t, &captureRaw, path)
+			captured, capturedErr := run()
+			captureRaw = ""
+
+			setGlobal(t, &inputFile, path)
+			// No cluster or kubeconfig is needed to replay
+			t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+			fakeKubectl(t, func(args []string) (string, error) {
+				t.Errorf("kubectl ran during replay: %v", args)
+				return "", nil
+			})
+			replayed, replayedErr := run()
+
+			if replayed != captured || replayedErr != capturedErr {
+				t.Errorf("replayed output =\n%s%s\nwant\n%s%s", replayed, replayedErr, captured, capturedErr)
+			}
+			if !strings.Contains(captured, "ctx1") || !strings.Contains(capturedErr, "Context ctx3") {
+				t.Errorf("captured output = %q, stderr = %q, want results of every context", captured, capturedErr)
+			}
+		})
+	}
+}
+
+func TestReplayCaptureNotRecorded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.json")
+	writeKubeconfig(t, "ctx1")
+	fakeKubectl(t, func(args []string) (string, error) { return "NAME\nweb\n", nil })
+	setGlobal(t, &captureRaw, path)
+	captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods"}); err != nil {
+			t.Fatalf("runCommand() error = %v", err)
+		}
+	})
+	captureRaw = ""
+
+	setGlobal(t, &inputFile, path)
+	fakeKubectl(t, func(args []string) (string, error) { return "", nil })
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() {
+			if err := runCommand("get", []string{"nodes"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+	})
+	if !strings.Contains(stderr, "kubectl --context ctx1 get nodes was not recorded") {
+		t.Errorf("stderr = %q, want a not recorded error", stderr)
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextField is the pseudo field that puts the context in a custom column,
+// as in -o custom-columns=CTX:CONTEXT,NAME:.metadata.name
+const contextField = "CONTEXT"
+
+// contextColumn is a custom column showing the context, at index of the
+// full column spec
+type contextColumn struct {
+	index  int
+	header string
+}
+
+// splitContextColumns removes CONTEXT pseudo columns from a custom-columns
+// spec in args, since kubectl can't evaluate them, and returns the args for
+// kubectl with the removed columns. It returns args unchanged when the spec
+// has no CONTEXT column.
+func splitContextColumns(args []string) ([]string, []contextColumn, error) {
+	for i, arg := range args {
+		valueIdx, prefix := -1, ""
+		if (arg == "-o" || arg == "--output") && i+1 < len(args) && strings.HasPrefix(args[i+1], "custom-columns=") {
+			valueIdx, prefix = i+1, "custom-columns="
+		}
+		for _, flag := range []string{"-o=", "--output=", "-o"} {
+			if valueIdx == -1 && strings.HasPrefix(arg, flag+"custom-columns=") {
+				valueIdx, prefix = i, flag+"custom-columns="
+			}
+		}
+		if valueIdx == -1 {
+			continue
+		}
+
+		var kept []string
+		var columns []contextColumn
+		for j, column := range strings.Split(strings.TrimPrefix(args[valueIdx], prefix), ",") {
+			header, field, _ := strings.Cut(column, ":")
+			if strings.TrimSpace(field) == contextField {
+				columns = append(columns, contextColumn{index: j, header: header})
+				continue
+			}
+			kept = append(kept, column)
+		}
+		if len(columns) == 0 {
+			return args, nil, nil
+		}
+		if len(kept) == 0 {
+			return nil, nil, fmt.Errorf("-o custom-columns needs at least one column besides %s", contextField)
+		}
+		rewritten := append([]string{}, args...)
+		rewritten[valueIdx] = prefix + strings.Join(kept, ",")
+		return rewritten, columns, nil
+	}
+	return args, nil, nil
+}
+
+// insertContextColumns adds the context columns to each context's
+// custom-columns table, filling empty cells with <none>
+func insertContextColumns(results []contextResult, columns []contextColumn) []contextResult {
+	inserted := make([]contextResult, len(results))
+	for i, result := range results {
+		inserted[i] = result
+		output := strings.TrimSpace(result.output)
+		if result.err != nil || output == "" {
+			continue
+		}
+		lines := strings.Split(output, "\n")
+		header := parseHeader(lines[0])
+		rows := [][]string{withContextCells(columnNames(header), columns, func(c contextColumn) string { return c.header })}
+		for _, line := range lines[1:] {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			cells := splitRow(header, line)
+			for j := range cells {
+				if cells[j] == "" {
+					cells[j] = "<none>"
+				}
+			}
+			rows = append(rows, withContextCells(cells, columns, func(contextColumn) string { return result.context }))
+		}
+		inserted[i].output = strings.Join(renderTableRows(rows), "\n")
+	}
+	return inserted
+}
+
+// withContextCells returns cells with value(column) inserted at the index of
+// every context column
+func withContextCells(cells []string, columns []contextColumn, value func(contextColumn) string) []string {
+	row := make([]string, 0, len(cells)+len(columns))
+	next := 0
+	for _, column := range columns {
+		for len(row) < column.index && next < len(cells) {
+			row = append(row, cells[next])
+			next++
+		}
+		row = append(row, value(column))
+	}
+	return append(row, cells[next:]...)
+}
+
+// formatContextColumnsOutput merges custom-columns tables that place the
+// context themselves into one aligned table, without the CONTEXT column the
+// other table formats add
+func formatContextColumnsOutput(results []contextResult) error {
+	var rows [][]string
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		output := strings.TrimSpace(result.output)
+		if output == "" {
+			continue
+		}
+		lines := strings.Split(output, "\n")
+		header := parseHeader(lines[0])
+		if rows == nil {
+			rows = append(rows, columnNames(header))
+		}
+		for _, line := range lines[1:] {
+			rows = append(rows, splitRow(header, line))
+		}
+	}
+	for _, line := range renderTableRows(rows) {
+		fmt.Println(line)
+	}
+	return nil
+}
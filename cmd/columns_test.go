@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitContextColumns(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantArgs    []string
+		wantColumns []contextColumn
+		wantErr     bool
+	}{
+		{
+			name:     "no context column",
+			args:     []string{"pods", "-o", "custom-columns=NAME:.metadata.name"},
+			wantArgs: []string{"pods", "-o", "custom-columns=NAME:.metadata.name"},
+		},
+		{
+			name:        "context first",
+			args:        []string{"pods", "-o", "custom-columns=CTX:CONTEXT,NAME:.metadata.name"},
+			wantArgs:    []string{"pods", "-o", "custom-columns=NAME:.metadata.name"},
+			wantColumns: []contextColumn{{index: 0, header: "CTX"}},
+		},
+		{
+			name:        "context in the middle with equals",
+			args:        []string{"pods", "--output=custom-columns=NAME:.metadata.name,CLUSTER:CONTEXT,NODE:.spec.nodeName"},
+			wantArgs:    []string{"pods", "--output=custom-columns=NAME:.metadata.name,NODE:.spec.nodeName"},
+			wantColumns: []contextColumn{{index: 1, header: "CLUSTER"}},
+		},
+		{
+			name:    "only a context column",
+			args:    []string{"pods", "-ocustom-columns=CTX:CONTEXT"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, columns, err := splitContextColumns(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("splitContextColumns(%v) expected error", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitContextColumns(%v) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) || !reflect.DeepEqual(columns, tt.wantColumns) {
+				t.Errorf("splitContextColumns(%v) = %v, %v, want %v, %v", tt.args, args, columns, tt.wantArgs, tt.wantColumns)
+			}
+		})
+	}
+}
+
+func TestRunCommandCustomColumnsContext(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "production")
+	fakeKubectl(t, func(args []string) (string, error) {
+		if spec := args[len(args)-1]; spec != "custom-columns=NAME:.metadata.name,NODE:.spec.nodeName" {
+			t.Errorf("kubectl custom-columns = %q, want the CONTEXT column removed", spec)
+		}
+		if argValue(args, "--context") == "ctx1" {
+			return "NAME   NODE\npod1   node-a\npod2   \n", nil
+		}
+		return "NAME           NODE\nlong-pod-name  node-b\n", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods", "-o", "custom-columns=NAME:.metadata.name,CLUSTER:CONTEXT,NODE:.spec.nodeName"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	expected := strings.Join([]string{
+		"NAME            CLUSTER      NODE",
+		"pod1            ctx1         node-a",
+		"pod2            ctx1         <none>",
+		"long-pod-name   production   node-b",
+	}, "\n") + "\n"
+	if output != expected {
+		t.Errorf("output =\n%s\nwant\n%s", output, expected)
+	}
+}
+
+func TestRunCommandCustomColumns(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "production")
+	fakeKubectl(t, func(args []string) (string, error) {
+		if spec := args[len(args)-1]; spec != "custom-columns=NAME:.metadata.name,NODE:.spec.nodeName" {
+			t.Errorf("kubectl custom-columns = %q, want it passed through", spec)
+		}
+		if argValue(args, "--context") == "ctx1" {
+			return "NAME   NODE\npod1   node-a\npod2   \n", nil
+		}
+		return "NAME           NODE\nlong-pod-name  node-b\n", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods", "-o", "custom-columns=NAME:.metadata.name,NODE:.spec.nodeName"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	expected := strings.Join([]string{
+		"CONTEXT     NAME            NODE",
+		"ctx1        pod1            node-a",
+		"ctx1        pod2            <none>",
+		"production  long-pod-name   node-b",
+	}, "\n") + "\n"
+	if output != expected {
+		t.Errorf("output =\n%s\nwant\n%s", output, expected)
+	}
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// lastAppliedAnnotation is the copy of the applied manifest kubectl apply
+// keeps, which repeats the rest of the object
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+var compareCmd = &cobra.Command{
+	Use:   "compare TYPE NAME",
+	Short: "Diff an object between contexts",
+	Long: `Get an object from every context and print a unified diff of each context against the base context,
+the first one or --base. Status and server-set metadata such as resourceVersion are ignored.`,
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCompare(args)
+	},
+}
+
+// runCompare gets the object from every context and diffs each context's
+// normalized YAML against the base context's. A context that cannot return
+// the object fails the run.
+func runCompare(args []string) error {
+	positional := 0
+	for i, arg := range args {
+		if arg == "-o" || arg == "--output" || strings.HasPrefix(arg, "--output=") || strings.HasPrefix(arg, "-o=") {
+			return fmt.Errorf("compare: -o is not supported, objects are compared as YAML")
+		}
+		if !strings.HasPrefix(arg, "-") && (i == 0 || !strings.HasPrefix(args[i-1], "-") || strings.Contains(args[i-1], "=")) {
+			positional++
+		}
+	}
+	if positional == 0 {
+		return fmt.Errorf("compare needs an object, e.g. compare deployment web or compare deployment/web")
+	}
+	contexts, finish, err := setupRun("get", args)
+	if err != nil {
+		return err
+	}
+	defer finish()
+	rewrite, _ := parseContextRewrite(contextRewriteExpr) // validated by setupRun
+
+	var names, kubeconfigNames, objects []string
+	results := runContexts(contexts, "get", append(append([]string{}, args...), "-o", "json"))
+	for i, result := range results {
+		result = rewrite.result(result)
+		results[i] = result
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		object, err := normalizeObject(result.output)
+		if err != nil {
+			return fmt.Errorf("context %s: %w", result.context, err)
+		}
+		names = append(names, result.context)
+		kubeconfigNames = append(kubeconfigNames, result.kubeconfigName())
+		objects = append(objects, object)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("compare: no context returned the object")
+	}
+
+	base := 0
+	if compareBase != "" {
+		base = -1
+		// --base may name the context as in kubeconfig or after --context-rewrite
+		for i, name := range names {
+			if name == compareBase || kubeconfigNames[i] == compareBase {
+				base = i
+			}
+		}
+		if base == -1 {
+			return fmt.Errorf("--base context %q did not return the object", compareBase)
+		}
+	}
+
+	baseLines := strings.Split(objects[base], "\n")
+	for i, name := range names {
+		if i == base {
+			continue
+		}
+		diff := unifiedDiff(baseLines, strings.Split(objects[i], "\n"), names[base], name)
+		if diff == "" {
+			notef("Context %s matches %s", name, names[base])
+			continue
+		}
+		fmt.Print(diff)
+	}
+	return checkFailures(results)
+}
+
+// normalizeObject returns get -o json output as YAML without the fields that
+// differ between clusters for identical objects
+func normalizeObject(output string) (string, error) {
+	var item, object map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &item); err != nil {
+		return "", fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if err := json.Unmarshal([]byte(itemIdentity(item)), &object); err != nil {
+		return "", err
+	}
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, lastAppliedAnnotation)
+			if len(annotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+	}
+	data, err := yaml.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// diffOp is a line of a diff: ' ' kept, '-' only in a or '+' only in b
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns the unified diff of the lines a and b, labeled with
+// from and to, or "" when they are equal
+func unifiedDiff(a, b []string, from, to string) string {
+	ops := diffLines(a, b)
+	// aPos and bPos count the lines of a and b before each op
+	aPos := make([]int, len(ops)+1)
+	bPos := make([]int, len(ops)+1)
+	for k, op := range ops {
+		aPos[k+1], bPos[k+1] = aPos[k], bPos[k]
+		if op.kind != '+' {
+			aPos[k+1]++
+		}
+		if op.kind != '-' {
+			bPos[k+1]++
+		}
+	}
+
+	var out strings.Builder
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+		// Changes closer than twice the context share a hunk
+		end := k
+		for j := k + 1; j < len(ops) && j <= end+2*diffContextLines; j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			}
+		}
+		start, stop := max(0, k-diffContextLines), min(len(ops), end+diffContextLines+1)
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", from, to)
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aPos[start], aPos[stop]-aPos[start]), hunkRange(bPos[start], bPos[stop]-bPos[start]))
+		for _, op := range ops[start:stop] {
+			fmt.Fprintf(&out, "%c%s\n", op.kind, op.line)
+		}
+		k = stop
+	}
+	return out.String()
+}
+
+// hunkRange formats the start line and length of one side of a hunk. An
+// empty side starts at the line before it.
+func hunkRange(before, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, length)
+}
+
+// diffLines returns the ops turning a into b along a longest common
+// subsequence of their lines
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb",
+			b:    "a\nb",
+		},
+		{
+			name:     "changed line",
+			a:        "a\nb\nc",
+			b:        "a\nx\nc",
+			expected: "--- base\n+++ other\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name:     "added lines at the end",
+			a:        "a",
+			b:        "a\nb\nc",
+			expected: "--- base\n+++ other\n@@ -1,1 +1,3 @@\n a\n+b\n+c\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12",
+			b:    "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny",
+			expected: "--- base\n+++ other\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := unifiedDiff(strings.Split(tt.a, "\n"), strings.Split(tt.b, "\n"), "base", "other")
+			if diff != tt.expected {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", diff, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeObject(t *testing.T) {
+	object, err := normalizeObject(`{"kind":"ConfigMap","metadata":{"name":"app","uid":"1","resourceVersion":"5",` +
+		`"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}"}},"data":{"b":"2","a":"1"},"status":{}}`)
+	if err != nil {
+		t.Fatalf("normalizeObject() error = %v", err)
+	}
+	expected := "data:\n    a: \"1\"\n    b: \"2\"\nkind: ConfigMap\nmetadata:\n    name: app"
+	if object != expected {
+		t.Errorf("normalizeObject() =\n%s\nwant\n%s", object, expected)
+	}
+}
+
+func TestRunCompare(t *testing.T) {
+	configMap := func(uid, value string) string {
+		return fmt.Sprintf(`{"kind":"ConfigMap","metadata":{"name":"app","uid":%q},"data":{"mode":%q}}`, uid, value)
+	}
+	tests := []struct {
+		name           string
+		base           string
+		rewrite        string
+		expected       string
+		expectedStderr string
+		expectedErr    string
+	}{
+		{
+			name: "first context is the base",
+			expected: "--- ctx1\n+++ ctx3\n@@ -1,5 +1,5 @@\n data:\n-    mode: fast\n+    mode: slow\n" +
+				" kind: ConfigMap\n metadata:\n     name: app\n",
+			expectedStderr: "Context ctx2 matches ctx1",
+		},
+		{
+			name: "--base",
+			base: "ctx3",
+			expected: "--- ctx3\n+++ ctx1\n@@ -1,5 +1,5 @@\n data:\n-    mode: slow\n+    mode: fast\n" +
+				" kind: ConfigMap\n metadata:\n     name: app\n" +
+				"--- ctx3\n+++ ctx2\n@@ -1,5 +1,5 @@\n data:\n-    mode: slow\n+    mode: fast\n" +
+				" kind: ConfigMap\n metadata:\n     name: app\n",
+		},
+		{
+			name:    "--base with --context-rewrite",
+			base:    "ctx3",
+			rewrite: "s/^ctx/cluster-/",
+			expected: "--- cluster-3\n+++ cluster-1\n@@ -1,5 +1,5 @@\n data:\n-    mode: slow\n+    mode: fast\n" +
+				" kind: ConfigMap\n metadata:\n     name: app\n" +
+				"--- cluster-3\n+++ cluster-2\n@@ -1,5 +1,5 @@\n data:\n-    mode: slow\n+    mode: fast\n" +
+				" kind: ConfigMap\n metadata:\n     name: app\n",
+		},
+		{
+			name:    "rewritten --base",
+			base:    "cluster-3",
+			rewrite: "s/^ctx/cluster-/",
+			expected: "--- cluster-3\n+++ cluster-1\n@@ -1,5 +1,5 @@\n data:\n-    mode: slow\n+    mode: fast\n" +
+				" kind: ConfigMap\n metadata:\n     name: app\n" +
+				"--- cluster-3\n+++ cluster-2\n@@ -1,5 +1,5 @@\n data:\n-    mode: slow\n+    mode: fast\n" +
+				" kind: ConfigMap\n metadata:\n     name: app\n",
+		},
+		{
+			name:        "unknown --base",
+			base:        "ctx9",
+			expectedErr: `--base context "ctx9" did not return the object`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeKubeconfig(t, "ctx1", "ctx2", "ctx3")
+			setGlobal(t, &compareBase, tt.base)
+			setGlobal(t, &contextRewriteExpr, tt.rewrite)
+			fakeKubectl(t, func(args []string) (string, error) {
+				if !strings.Contains(strings.Join(args, " "), "get configmap app -o json") {
+					t.Errorf("kubectl ran %v, want get configmap app -o json", args)
+				}
+				switch argValue(args, "--context") {
+				case "ctx1":
+					return configMap("1", "fast"), nil
+				case "ctx2":
+					return configMap("2", "fast"), nil
+				default:
+					return configMap("3", "slow"), nil
+				}
+			})
+
+			var output string
+			var err error
+			stderr := captureStderr(t, func() {
+				output = captureStdout(t, func() {
+					err = compareCmd.RunE(compareCmd, []string{"configmap", "app"})
+				})
+			})
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Errorf("compare error = %v, want %s", err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("compare error = %v", err)
+			}
+			if output != tt.expected {
+				t.Errorf("compare output =\n%s\nwant\n%s", output, tt.expected)
+			}
+			if !strings.Contains(stderr, tt.expectedStderr) {
+				t.Errorf("stderr = %q, want %q", stderr, tt.expectedStderr)
+			}
+		})
+	}
+}
+
+func TestRunCompareOutputFlag(t *testing.T) {
+	if err := runCompare([]string{"configmap", "app", "-o", "yaml"}); err == nil {
+		t.Error("compare -o yaml succeeded, want an error")
+	}
+}
+
+func TestRunCompareFailedContext(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	fakeKubectl(t, func(args []string) (string, error) {
+		if argValue(args, "--context") == "ctx2" {
+			return `Error from server (NotFound): configmaps "app" not found`, fmt.Errorf("exit status 1")
+		}
+		return `{"kind":"ConfigMap","metadata":{"name":"app"}}`, nil
+	})
+
+	var err error
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() {
+			err = runCompare([]string{"configmap", "app"})
+		})
+	})
+	if err == nil || err.Error() != "1 of 2 contexts failed" {
+		t.Errorf("compare error = %v, want the failed context to fail the run", err)
+	}
+	if !strings.Contains(stderr, "not found") {
+		t.Errorf("stderr = %q, want the context's error", stderr)
+	}
+}
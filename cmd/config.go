@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// userConfig is the optional config file of the plugin
+type userConfig struct {
+	// Groups maps a group name to the contexts in it, for --group
+	Groups map[string][]string `yaml:"groups"`
+}
+
+// configFilePath returns the path of the config file,
+// $XDG_CONFIG_HOME/kubectl-multi-context/config.yaml or under ~/.config
+func configFilePath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find the config directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "kubectl-multi-context", "config.yaml"), nil
+}
+
+func readUserConfig() (userConfig, string, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return userConfig{}, "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return userConfig{}, path, fmt.Errorf("--group needs a config file defining groups at %s", path)
+		}
+		return userConfig{}, path, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var config userConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return userConfig{}, path, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return config, path, nil
+}
+
+// resolveGroups returns the contexts of the named config file groups, in
+// the order listed, without duplicates
+func resolveGroups(names []string) ([]string, error) {
+	config, path, err := readUserConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		members, ok := config.Groups[strings.TrimSpace(name)]
+		if !ok {
+			available := make([]string, 0, len(config.Groups))
+			for group := range config.Groups {
+				available = append(available, group)
+			}
+			sort.Strings(available)
+			return nil, fmt.Errorf("group %q is not defined in %s (groups: %s)", name, path, strings.Join(available, ", "))
+		}
+		for _, member := range members {
+			if !seen[member] {
+				seen[member] = true
+				contexts = append(contexts, member)
+			}
+		}
+	}
+	return contexts, nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeUserConfig writes the plugin config file under a temporary XDG_CONFIG_HOME
+func writeUserConfig(t *testing.T, config string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "kubectl-multi-context"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "kubectl-multi-context", "config.yaml"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetContextsGroup(t *testing.T) {
+	writeKubeconfig(t, "prod-us", "prod-eu", "staging", "dev")
+	writeUserConfig(t, "groups:\n  prod: [prod-us, prod-eu]\n  preprod: [staging, dev]\n  typo: [prod-uk]\n")
+
+	tests := []struct {
+		name    string
+		groups  []string
+		filters []string
+		want    []string
+		wantErr string
+	}{
+		{name: "one group", groups: []string{"prod"}, want: []string{"prod-us", "prod-eu"}},
+		{name: "several groups", groups: []string{"preprod", "prod"}, want: []string{"staging", "dev", "prod-us", "prod-eu"}},
+		{name: "narrowed by filter", groups: []string{"prod"}, filters: []string{"eu"}, want: []string{"prod-eu"}},
+		{name: "unknown group", groups: []string{"qa"}, wantErr: `group "qa" is not defined`},
+		{name: "unknown context", groups: []string{"typo"}, wantErr: "contexts from --group typo not found in kubeconfig: prod-uk"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGlobal(t, &groupNames, tt.groups)
+			setGlobal(t, &filterPatterns, tt.filters)
+			contexts, err := getContexts()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("getContexts() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getContexts() error = %v", err)
+			}
+			if !reflect.DeepEqual(contexts, tt.want) {
+				t.Errorf("getContexts() = %v, want %v", contexts, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveGroupsMissingConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if _, err := resolveGroups([]string{"prod"}); err == nil || !strings.Contains(err.Error(), "needs a config file") {
+		t.Errorf("resolveGroups() error = %v, want a missing config file error", err)
+	}
+}
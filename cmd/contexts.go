@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -12,99 +17,747 @@ import (
 
 // Kubeconfig represents the minimal structure needed to read contexts from a kubeconfig file
 type Kubeconfig struct {
-	Contexts []ContextEntry `yaml:"contexts"`
+	CurrentContext string         `yaml:"current-context"`
+	Contexts       []ContextEntry `yaml:"contexts"`
 }
 
 // ContextEntry represents a single context entry in the kubeconfig
 type ContextEntry struct {
-	Name string `yaml:"name"`
+	Name    string         `yaml:"name"`
+	Context ContextDetails `yaml:"context"`
+	// Source is the kubeconfig file the context was read from
+	Source string `yaml:"-"`
 }
 
-func getContexts() ([]string, error) {
+// ContextDetails holds the cluster, user and namespace a context refers to
+type ContextDetails struct {
+	Cluster   string `yaml:"cluster"`
+	User      string `yaml:"user"`
+	Namespace string `yaml:"namespace"`
+}
+
+// readKubeconfig reads and merges the kubeconfig files. Like kubectl, the
+// first file to define a context or current-context wins, and missing files
+// in a KUBECONFIG list are skipped.
+func readKubeconfig() (Kubeconfig, error) {
+	var merged Kubeconfig
 	kubeconfigPath := getKubeconfigPath()
 	if kubeconfigPath == "" {
-		return nil, fmt.Errorf("could not determine kubeconfig path")
+		return merged, fmt.Errorf("could not determine kubeconfig path")
+	}
+
+	paths := kubeconfigPaths(kubeconfigPath)
+	seen := make(map[string]bool)
+	read := 0
+	for _, path := range paths {
+		config, err := readKubeconfigFile(path)
+		if err != nil {
+			if len(paths) > 1 && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return merged, err
+		}
+		read++
+
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = config.CurrentContext
+		}
+		for _, entry := range config.Contexts {
+			if entry.Name == "" || seen[entry.Name] {
+				continue
+			}
+			seen[entry.Name] = true
+			entry.Source = path
+			merged.Contexts = append(merged.Contexts, entry)
+		}
 	}
+	if read == 0 {
+		return merged, fmt.Errorf("failed to read kubeconfig: none of %s exist", kubeconfigPath)
+	}
+	if err := applyContextKubeconfigs(&merged); err != nil {
+		return merged, err
+	}
+	return merged, nil
+}
 
-	file, err := os.ReadFile(kubeconfigPath)
+// parseContextKubeconfigs parses the --context-kubeconfig CONTEXT=PATH values
+func parseContextKubeconfigs(values []string) (map[string]string, error) {
+	paths := make(map[string]string, len(values))
+	for _, value := range values {
+		name, path, ok := strings.Cut(value, "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if !ok || name == "" || path == "" {
+			return nil, fmt.Errorf("invalid --context-kubeconfig %q: must be CONTEXT=PATH", value)
+		}
+		paths[name] = path
+	}
+	return paths, nil
+}
+
+// contextKubeconfig returns the --context-kubeconfig file of a context, or ""
+// when it uses the default kubeconfig
+func contextKubeconfig(context string) string {
+	paths, _ := parseContextKubeconfigs(contextKubeconfigs) // validated by readKubeconfig
+	return paths[context]
+}
+
+// applyContextKubeconfigs adds the contexts mapped by --context-kubeconfig to
+// config, taking each from its own file in place of any default definition
+func applyContextKubeconfigs(config *Kubeconfig) error {
+	paths, err := parseContextKubeconfigs(contextKubeconfigs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+		return err
+	}
+	names := make([]string, 0, len(paths))
+	for name := range paths {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	var config Kubeconfig
-	if err := yaml.Unmarshal(file, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	for _, name := range names {
+		path := paths[name]
+		file, err := readKubeconfigFile(path)
+		if err != nil {
+			return err
+		}
+		var entry *ContextEntry
+		for i := range file.Contexts {
+			if file.Contexts[i].Name == name {
+				entry = &file.Contexts[i]
+				break
+			}
+		}
+		if entry == nil {
+			return fmt.Errorf("context %s not found in its --context-kubeconfig %s", name, path)
+		}
+		entry.Source = path
+
+		replaced := false
+		for i := range config.Contexts {
+			if config.Contexts[i].Name == name {
+				config.Contexts[i] = *entry
+				replaced = true
+			}
+		}
+		if !replaced {
+			config.Contexts = append(config.Contexts, *entry)
+		}
 	}
+	return nil
+}
 
-	var contexts []string
-	for _, entry := range config.Contexts {
-		if entry.Name != "" {
-			contexts = append(contexts, entry.Name)
+// kubeconfigPaths splits a KUBECONFIG-style list into its file paths
+func kubeconfigPaths(kubeconfigPath string) []string {
+	var paths []string
+	for _, path := range filepath.SplitList(kubeconfigPath) {
+		if path != "" {
+			paths = append(paths, path)
 		}
 	}
+	return paths
+}
 
-	if len(contexts) == 0 {
+// validateKubeconfig loads the kubeconfig files with clientcmd, as kubectl
+// does, and runs its full validation, e.g. that every context refers to a
+// defined cluster and user
+func validateKubeconfig() error {
+	rules := &clientcmd.ClientConfigLoadingRules{Precedence: kubeconfigPaths(getKubeconfigPath())}
+	config, err := rules.Load()
+	if err != nil {
+		return fmt.Errorf("kubeconfig validation failed: %w", err)
+	}
+	if err := clientcmd.Validate(*config); err != nil {
+		return fmt.Errorf("kubeconfig validation failed: %w", err)
+	}
+	return nil
+}
+
+// readKubeconfigFile parses a single kubeconfig file
+func readKubeconfigFile(path string) (Kubeconfig, error) {
+	var config Kubeconfig
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	if err := yaml.Unmarshal(file, &config); err != nil {
+		return config, fmt.Errorf("failed to parse kubeconfig %s: %w", path, err)
+	}
+
+	if len(config.Contexts) == 0 {
 		// Fallback to clientcmd if YAML parsing doesn't find contexts
-		kubeconfig, err := clientcmd.LoadFromFile(kubeconfigPath)
+		kubeconfig, err := clientcmd.LoadFromFile(path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+			return config, fmt.Errorf("no contexts found in kubeconfig %s, and loading it with clientcmd failed: %w", path, err)
+		}
+		for name, context := range kubeconfig.Contexts {
+			config.Contexts = append(config.Contexts, ContextEntry{
+				Name: name,
+				Context: ContextDetails{
+					Cluster:   context.Cluster,
+					User:      context.AuthInfo,
+					Namespace: context.Namespace,
+				},
+			})
 		}
+		sort.Slice(config.Contexts, func(i, j int) bool {
+			return config.Contexts[i].Name < config.Contexts[j].Name
+		})
+	}
+	return config, nil
+}
 
-		for name := range kubeconfig.Contexts {
-			contexts = append(contexts, name)
+func getContexts() ([]string, error) {
+	config, err := readKubeconfig()
+	if err != nil {
+		return nil, err
+	}
+	if validateKubeconfigFlag {
+		if err := validateKubeconfig(); err != nil {
+			return nil, err
 		}
 	}
 
+	var contexts []string
+	for _, entry := range config.Contexts {
+		contexts = append(contexts, entry.Name)
+	}
+
 	if len(contexts) == 0 {
 		return nil, fmt.Errorf("no contexts found in kubeconfig")
 	}
 
+	// --context names the exact contexts to use, so no other selection applies
+	if len(explicitContexts) > 0 {
+		if len(groupNames) > 0 || len(filterPatterns) > 0 || len(prefixFilters) > 0 || len(suffixFilters) > 0 || len(clusterFilters) > 0 || len(userFilters) > 0 || len(excludePatterns) > 0 {
+			return nil, fmt.Errorf("--context cannot be combined with --group, --filter, --prefix, --suffix, --cluster-filter, --user-filter or --exclude")
+		}
+		return selectContexts(contexts, explicitContexts, "--context")
+	}
+
+	if len(groupNames) > 0 {
+		members, err := resolveGroups(groupNames)
+		if err != nil {
+			return nil, err
+		}
+		if contexts, err = selectContexts(contexts, members, "--group "+strings.Join(groupNames, ",")); err != nil {
+			return nil, err
+		}
+	}
+
+	// Without filters or groups, take the contexts listed in the --kube-context-env variable
+	if len(groupNames) == 0 && len(filterPatterns) == 0 && len(prefixFilters) == 0 && len(suffixFilters) == 0 && len(clusterFilters) == 0 && len(userFilters) == 0 {
+		if value := contextsFromEnv(); value != "" {
+			selected, err := selectContexts(contexts, strings.Split(value, ","), kubeContextEnv)
+			if err != nil {
+				return nil, err
+			}
+			return applyExcludes(selected)
+		}
+	}
+
 	// Apply filters if specified
 	if len(filterPatterns) > 0 {
 		var err error
 		contexts, err = filterContexts(contexts, filterPatterns)
 		if err != nil {
-			return nil, fmt.Errorf("invalid filter pattern: %w", err)
+			return nil, err
 		}
 		if len(contexts) == 0 {
 			return nil, fmt.Errorf("no contexts match filter patterns: %s", strings.Join(filterPatterns, ", "))
 		}
 	}
 
-	return contexts, nil
+	if len(prefixFilters) > 0 || len(suffixFilters) > 0 {
+		contexts = filterAffixes(contexts, prefixFilters, suffixFilters)
+		if len(contexts) == 0 {
+			return nil, fmt.Errorf("no contexts match --prefix %s --suffix %s", strings.Join(prefixFilters, ","), strings.Join(suffixFilters, ","))
+		}
+	}
+
+	if len(clusterFilters) > 0 || len(userFilters) > 0 {
+		var err error
+		if contexts, err = filterByEntry(contexts, config.Contexts); err != nil {
+			return nil, err
+		}
+	}
+
+	return applyExcludes(contexts)
+}
+
+// applyExcludes removes the --exclude matches from the selected contexts
+func applyExcludes(contexts []string) ([]string, error) {
+	if len(excludePatterns) == 0 {
+		return contexts, nil
+	}
+	kept, err := excludeContexts(contexts, excludePatterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("--exclude %s excludes every selected context", strings.Join(excludePatterns, ", "))
+	}
+	return kept, nil
+}
+
+// filterByEntry keeps the contexts whose kubeconfig cluster matches any of
+// the --cluster-filter patterns and whose user matches any of the
+// --user-filter patterns (case-insensitive regexes). An empty list matches all.
+func filterByEntry(contexts []string, entries []ContextEntry) ([]string, error) {
+	clusters, err := compilePatterns("--cluster-filter", clusterFilters, false)
+	if err != nil {
+		return nil, err
+	}
+	users, err := compilePatterns("--user-filter", userFilters, false)
+	if err != nil {
+		return nil, err
+	}
+	details := make(map[string]ContextDetails, len(entries))
+	for _, entry := range entries {
+		details[entry.Name] = entry.Context
+	}
+
+	var filtered []string
+	for _, ctx := range contexts {
+		d := details[ctx]
+		if (len(clusters) == 0 || matchesAnyPattern(d.Cluster, clusters)) && (len(users) == 0 || matchesAnyPattern(d.User, users)) {
+			filtered = append(filtered, ctx)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("no contexts match --cluster-filter %s --user-filter %s", strings.Join(clusterFilters, ","), strings.Join(userFilters, ","))
+	}
+	return filtered, nil
+}
+
+// filterAffixes keeps the contexts that start with any of the prefixes and
+// end with any of the suffixes (case-insensitive). An empty list matches all.
+func filterAffixes(contexts []string, prefixes, suffixes []string) []string {
+	matchesAny := func(name string, affixes []string, match func(s, affix string) bool) bool {
+		if len(affixes) == 0 {
+			return true
+		}
+		for _, affix := range affixes {
+			if match(name, strings.ToLower(strings.TrimSpace(affix))) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var filtered []string
+	for _, ctx := range contexts {
+		name := strings.ToLower(strings.TrimSpace(ctx))
+		if matchesAny(name, prefixes, strings.HasPrefix) && matchesAny(name, suffixes, strings.HasSuffix) {
+			filtered = append(filtered, ctx)
+		}
+	}
+	return filtered
+}
+
+// contextsFromEnv returns the value of the --kube-context-env variable
+func contextsFromEnv() string {
+	if kubeContextEnv == "" {
+		return ""
+	}
+	return strings.TrimSpace(os.Getenv(kubeContextEnv))
+}
+
+// contextsNarrowed reports whether the contexts were explicitly selected by
+// a filter flag or the --kube-context-env variable
+func contextsNarrowed() bool {
+	return interactive || len(explicitContexts) > 0 || len(groupNames) > 0 || len(filterPatterns) > 0 || len(prefixFilters) > 0 || len(suffixFilters) > 0 || len(clusterFilters) > 0 || len(userFilters) > 0 || contextsFromEnv() != ""
+}
+
+// selectContexts returns the named contexts in the given order, failing on
+// names that are not in the kubeconfig
+func selectContexts(contexts []string, names []string, source string) ([]string, error) {
+	known := make(map[string]bool, len(contexts))
+	for _, ctx := range contexts {
+		known[ctx] = true
+	}
+
+	var selected, unknown []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			unknown = append(unknown, name)
+			continue
+		}
+		selected = append(selected, name)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("contexts from %s not found in kubeconfig: %s", source, strings.Join(unknown, ", "))
+	}
+	return selected, nil
+}
+
+// getContextClusters maps each context in the kubeconfig to the name of the
+// cluster it points at
+func getContextClusters() (map[string]string, error) {
+	config, err := readKubeconfig()
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make(map[string]string)
+	for _, entry := range config.Contexts {
+		clusters[entry.Name] = entry.Context.Cluster
+	}
+	return clusters, nil
+}
+
+// getContextSources maps each context in the kubeconfig to the file it was
+// read from
+func getContextSources() (map[string]string, error) {
+	config, err := readKubeconfig()
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]string)
+	for _, entry := range config.Contexts {
+		sources[entry.Name] = entry.Source
+	}
+	return sources, nil
 }
 
 // filterContexts filters contexts by regex pattern matching (case-insensitive)
 // Multiple patterns are OR'd together - a context matches if it matches any of the patterns
+// Surrounding whitespace is ignored in patterns and context names. With
+// --filter-exact, patterns must equal the whole context name instead.
 func filterContexts(contexts []string, patterns []string) ([]string, error) {
 	if len(patterns) == 0 {
 		return contexts, nil
 	}
+	regexes, err := compilePatterns("--filter", patterns, filterExact)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []string
+	for _, ctx := range contexts {
+		if matchesAnyPattern(ctx, regexes) {
+			filtered = append(filtered, ctx)
+		}
+	}
+	return filtered, nil
+}
+
+// excludeContexts removes the contexts matching any of the --exclude regex
+// patterns (case-insensitive)
+func excludeContexts(contexts []string, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return contexts, nil
+	}
+	regexes, err := compilePatterns("--exclude", patterns, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []string
+	for _, ctx := range contexts {
+		if !matchesAnyPattern(ctx, regexes) {
+			kept = append(kept, ctx)
+		}
+	}
+	return kept, nil
+}
 
-	// Compile regex patterns
+// compilePatterns compiles case-insensitive context name patterns of flag,
+// ignoring surrounding whitespace. Exact patterns must equal the whole name.
+func compilePatterns(flag string, patterns []string, exact bool) ([]*regexp.Regexp, error) {
 	regexes := make([]*regexp.Regexp, 0, len(patterns))
 	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if exact {
+			pattern = "^" + regexp.QuoteMeta(pattern) + "$"
+		}
 		// Add case-insensitive flag (?i) to the pattern
 		regex, err := regexp.Compile("(?i)" + pattern)
 		if err != nil {
-			return nil, fmt.Errorf("invalid regex pattern %q: %w", pattern, err)
+			return nil, fmt.Errorf("invalid %s regex pattern %q: %w", flag, pattern, err)
 		}
 		regexes = append(regexes, regex)
 	}
+	return regexes, nil
+}
 
-	var filtered []string
-	for _, ctx := range contexts {
-		for _, regex := range regexes {
-			if regex.MatchString(ctx) {
-				filtered = append(filtered, ctx)
-				break // Match found, no need to check other patterns for this context
+// matchesAnyPattern reports whether a context name, without surrounding
+// whitespace, matches any of the regexes
+func matchesAnyPattern(ctx string, regexes []*regexp.Regexp) bool {
+	name := strings.TrimSpace(ctx)
+	for _, regex := range regexes {
+		if regex.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileContextGroup compiles the --context-group pattern, which must have
+// at least one named capture group. It returns nil when no pattern is set.
+func compileContextGroup() (*regexp.Regexp, error) {
+	if contextGroup == "" {
+		return nil, nil
+	}
+	regex, err := regexp.Compile(contextGroup)
+	if err != nil {
+		return nil, fmt.Errorf("invalid context group pattern %q: %w", contextGroup, err)
+	}
+	for _, name := range regex.SubexpNames() {
+		if name != "" {
+			return regex, nil
+		}
+	}
+	return nil, fmt.Errorf("context group pattern %q has no named capture groups", contextGroup)
+}
+
+// contextGroupLabels returns the named capture groups of regex matched
+// against the context name, or nil if the name does not match
+func contextGroupLabels(regex *regexp.Regexp, context string) map[string]string {
+	if regex == nil {
+		return nil
+	}
+	match := regex.FindStringSubmatch(context)
+	if match == nil {
+		return nil
+	}
+	labels := make(map[string]string)
+	for i, name := range regex.SubexpNames() {
+		if name != "" {
+			labels[name] = match[i]
+		}
+	}
+	return labels
+}
+
+// groupContexts orders contexts by their group labels, in capture group
+// order, so contexts of the same group are adjacent. Contexts that don't
+// match the pattern keep their relative order at the end.
+func groupContexts(regex *regexp.Regexp, contexts []string) []string {
+	var names []string
+	for _, name := range regex.SubexpNames() {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	key := func(context string) []string {
+		labels := contextGroupLabels(regex, context)
+		if labels == nil {
+			return nil
+		}
+		values := make([]string, len(names))
+		for i, name := range names {
+			values[i] = labels[name]
+		}
+		return values
+	}
+
+	grouped := append([]string(nil), contexts...)
+	sort.SliceStable(grouped, func(i, j int) bool {
+		a, b := key(grouped[i]), key(grouped[j])
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
 			}
 		}
+		return false
+	})
+	return grouped
+}
+
+// Orders for --sort-contexts
+const (
+	sortByName         = "name"
+	sortByCluster      = "cluster"
+	sortByCurrentFirst = "current-first"
+)
+
+// sortContexts orders contexts by name, by the cluster they point at (then
+// by name), or by name with the current context first
+func sortContexts(contexts []string, order string) ([]string, error) {
+	sorted := append([]string(nil), contexts...)
+	sort.Strings(sorted)
+
+	switch order {
+	case sortByName:
+	case sortByCluster:
+		clusters, err := getContextClusters()
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return clusters[sorted[i]] < clusters[sorted[j]]
+		})
+	case sortByCurrentFirst:
+		config, err := readKubeconfig()
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i] == config.CurrentContext && sorted[j] != config.CurrentContext
+		})
+	default:
+		return nil, fmt.Errorf("invalid --sort-contexts %q: must be %s, %s or %s", order, sortByName, sortByCluster, sortByCurrentFirst)
 	}
-	return filtered, nil
+	if contextOrderFile != "" {
+		return orderContextsByFile(sorted, contextOrderFile)
+	}
+	return sorted, nil
 }
 
+// orderContextsByFile puts the contexts listed in the --context-order-file
+// first, in the order of the file, followed by the other contexts in their
+// current order
+func orderContextsByFile(contexts []string, path string) ([]string, error) {
+	listed, err := readContextList(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --context-order-file: %w", err)
+	}
+	present := make(map[string]bool, len(contexts))
+	for _, name := range contexts {
+		present[name] = true
+	}
+
+	ordered := make([]string, 0, len(contexts))
+	placed := make(map[string]bool, len(listed))
+	for _, name := range listed {
+		if present[name] && !placed[name] {
+			placed[name] = true
+			ordered = append(ordered, name)
+		}
+	}
+	var unlisted []string
+	for _, name := range contexts {
+		if !placed[name] {
+			unlisted = append(unlisted, name)
+		}
+	}
+	if len(unlisted) > 0 {
+		notef("Contexts not listed in --context-order-file %s follow the listed ones: %s", path, strings.Join(unlisted, ", "))
+	}
+	return append(ordered, unlisted...), nil
+}
+
+// resumeContexts returns the next chunk to process: the contexts after
+// startAfter, at most limit of them when limit is positive. They are sorted
+// by name, so a removed context still works as a resume point, unless
+// --sort-contexts, --context-order-file or --priority set an order; that
+// order is then kept and startAfter must be one of the contexts.
+func resumeContexts(contexts []string, startAfter string, limit int) []string {
+	ordered := append([]string(nil), contexts...)
+	start := 0
+	if customContextOrder() {
+		if startAfter != "" {
+			start = len(ordered)
+			if i := slices.Index(ordered, startAfter); i != -1 {
+				start = i + 1
+			}
+		}
+	} else {
+		sort.Strings(ordered)
+		if startAfter != "" {
+			start = sort.Search(len(ordered), func(i int) bool { return ordered[i] > startAfter })
+		}
+	}
+	ordered = ordered[start:]
+
+	if limit > 0 && len(ordered) > limit {
+		ordered = ordered[:limit]
+	}
+	return ordered
+}
+
+// customContextOrder reports whether the contexts are run in an order other
+// than by name
+func customContextOrder() bool {
+	return sortOrder != sortByName || contextOrderFile != "" || len(priorityContexts) > 0
+}
+
+// readContextList reads a file of context names, such as an
+// --expect-contexts-file: one name per line, ignoring blank lines and
+// # comments, or the table printed by the contexts command
+func readContextList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if columns := parseHeader(lines[0]); columnIndex(columns, "CURRENT") == 0 && columnIndex(columns, "NAME") == 1 {
+		var names []string
+		for _, line := range lines[1:] {
+			if cells := splitRow(columns, line); cells[1] != "" {
+				names = append(names, cells[1])
+			}
+		}
+		return names, nil
+	}
+
+	var names []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+// checkExpectedContexts returns an error listing the contexts added and
+// removed compared to the --expect-contexts-file baseline
+func checkExpectedContexts(contexts []string, path string) error {
+	expected, err := readContextList(path)
+	if err != nil {
+		return fmt.Errorf("failed to read --expect-contexts-file: %w", err)
+	}
+	want := make(map[string]bool, len(expected))
+	for _, name := range expected {
+		want[name] = true
+	}
+	got := make(map[string]bool, len(contexts))
+	var added, removed []string
+	for _, name := range contexts {
+		got[name] = true
+		if !want[name] {
+			added = append(added, name)
+		}
+	}
+	for name := range want {
+		if !got[name] {
+			removed = append(removed, name)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	var b strings.Builder
+	fmt.Fprintf(&b, "contexts differ from --expect-contexts-file %s", path)
+	if len(added) > 0 {
+		fmt.Fprintf(&b, "\n  added: %s", strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		fmt.Fprintf(&b, "\n  removed: %s", strings.Join(removed, ", "))
+	}
+	return errors.New(b.String())
+}
+
+// getKubeconfigPath returns the kubeconfig path from --kubeconfig, KUBECONFIG
+// or the default ~/.kube/config, in that order
 func getKubeconfigPath() string {
+	if kubeconfigFlag != "" {
+		return kubeconfigFlag
+	}
 	path := os.Getenv("KUBECONFIG")
 	if path != "" {
 		return path
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var contextsCmd = &cobra.Command{
+	Use:   "contexts",
+	Short: "List the contexts commands would run against",
+	Long:  `List the contexts selected by the filters, in the order commands run against them, with the cluster, user and namespace of each.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listContexts()
+	},
+}
+
+func listContexts() error {
+	contexts, err := getContexts()
+	if err != nil {
+		return err
+	}
+	contexts, err = sortContexts(contexts, sortOrder)
+	if err != nil {
+		return err
+	}
+
+	config, err := readKubeconfig()
+	if err != nil {
+		return err
+	}
+	entries := make(map[string]ContextEntry, len(config.Contexts))
+	for _, entry := range config.Contexts {
+		entries[entry.Name] = entry
+	}
+
+	header := []string{"CURRENT", "NAME", "CLUSTER", "USER", "NAMESPACE"}
+	if showSource {
+		header = append(header, "SOURCE")
+	}
+	rows := [][]string{header}
+	for _, name := range contexts {
+		entry := entries[name]
+		current := ""
+		if name == config.CurrentContext {
+			current = "*"
+		}
+		row := []string{current, name, entry.Context.Cluster, entry.Context.User, entry.Context.Namespace}
+		if showSource {
+			row = append(row, entry.Source)
+		}
+		rows = append(rows, row)
+	}
+	printTable(rows)
+	return nil
+}
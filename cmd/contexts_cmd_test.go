@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeMergedKubeconfigs writes two kubeconfig files that both define the
+// shared context and points KUBECONFIG at the pair.
+func writeMergedKubeconfigs(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	configs := map[string]string{
+		first: `current-context: shared
+contexts:
+- name: shared
+  context:
+    cluster: first-cluster
+    user: admin
+- name: only-first
+  context:
+    cluster: first-cluster
+    user: admin
+    namespace: apps
+`,
+		second: `current-context: only-second
+contexts:
+- name: shared
+  context:
+    cluster: second-cluster
+    user: readonly
+- name: only-second
+  context:
+    cluster: second-cluster
+    user: readonly
+`,
+	}
+	for path, config := range configs {
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatalf("failed to write kubeconfig: %v", err)
+		}
+	}
+	t.Setenv("KUBECONFIG", first+string(filepath.ListSeparator)+second)
+	return first, second
+}
+
+func TestListContextsShowSource(t *testing.T) {
+	first, second := writeMergedKubeconfigs(t)
+	setGlobal(t, &showSource, true)
+
+	output := captureStdout(t, func() {
+		if err := listContexts(); err != nil {
+			t.Errorf("listContexts() error = %v", err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("listContexts() printed %d lines, want 4:\n%s", len(lines), output)
+	}
+	expected := [][]string{
+		{"CURRENT", "NAME", "CLUSTER", "USER", "NAMESPACE", "SOURCE"},
+		{"only-first", "first-cluster", "admin", "apps", first},
+		{"only-second", "second-cluster", "readonly", second},
+		{"*", "shared", "first-cluster", "admin", first},
+	}
+	for i, want := range expected {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("line %d = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestListContextsWithoutSource(t *testing.T) {
+	writeMergedKubeconfigs(t)
+
+	output := captureStdout(t, func() {
+		if err := listContexts(); err != nil {
+			t.Errorf("listContexts() error = %v", err)
+		}
+	})
+	if strings.Contains(output, "SOURCE") {
+		t.Errorf("listContexts() should not show SOURCE without --show-source:\n%s", output)
+	}
+}
+
+func TestFormatJSONOutputShowSource(t *testing.T) {
+	setGlobal(t, &keepContext, true)
+	_, second := writeMergedKubeconfigs(t)
+	setGlobal(t, &showSource, true)
+
+	results := []contextResult{{context: "only-second", output: `{"items":[{"metadata":{"name":"pod1"}}]}`}}
+	output := captureStdout(t, func() {
+		if err := formatJSONOutput(results, "get"); err != nil {
+			t.Errorf("formatJSONOutput() error = %v", err)
+		}
+	})
+	if !strings.Contains(output, `"multi/source-kubeconfig": "`+second+`"`) {
+		t.Errorf("formatJSONOutput() should annotate the source kubeconfig:\n%s", output)
+	}
+}
+
+func TestReadKubeconfigSkipsMissingFiles(t *testing.T) {
+	first, _ := writeMergedKubeconfigs(t)
+	missing := filepath.Join(t.TempDir(), "missing")
+	t.Setenv("KUBECONFIG", missing+string(filepath.ListSeparator)+first)
+
+	config, err := readKubeconfig()
+	if err != nil {
+		t.Fatalf("readKubeconfig() error = %v", err)
+	}
+	if len(config.Contexts) != 2 || config.CurrentContext != "shared" {
+		t.Errorf("readKubeconfig() = %+v, want the two contexts of %s", config, first)
+	}
+}
+
+func TestGetContextsMergedKubeconfigs(t *testing.T) {
+	writeMergedKubeconfigs(t)
+
+	contexts, err := getContexts()
+	if err != nil {
+		t.Fatalf("getContexts() error = %v", err)
+	}
+	if strings.Join(contexts, ",") != "shared,only-first,only-second" {
+		t.Errorf("getContexts() = %v, want each context of both files once", contexts)
+	}
+
+	// The first file's definition of shared wins, as with kubectl
+	setGlobal(t, &clusterFilters, []string{"second-cluster"})
+	contexts, err = getContexts()
+	if err != nil {
+		t.Fatalf("getContexts() error = %v", err)
+	}
+	if strings.Join(contexts, ",") != "only-second" {
+		t.Errorf("getContexts() with --cluster-filter = %v, want [only-second]", contexts)
+	}
+}
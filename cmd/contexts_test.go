@@ -72,6 +72,14 @@ func TestGetKubeconfigPath(t *testing.T) {
 	}
 }
 
+func TestGetKubeconfigPathFlag(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/from/env")
+	setGlobal(t, &kubeconfigFlag, "/from/flag")
+	if got := getKubeconfigPath(); got != "/from/flag" {
+		t.Errorf("getKubeconfigPath() = %q, want --kubeconfig to override KUBECONFIG", got)
+	}
+}
+
 func TestFilterContexts(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -123,6 +131,12 @@ func TestFilterContexts(t *testing.T) {
 			patterns: []string{"prod(uction)?"},
 			want:     []string{"prod-cluster", "production-cluster"},
 		},
+		{
+			name:     "full regex for a naming convention",
+			contexts: []string{"prod-us-east1", "prod-eu-west1", "prod-ap-south1", "staging-prod-us-east1", "prod-us"},
+			patterns: []string{"^prod-(us|eu)-.*$"},
+			want:     []string{"prod-us-east1", "prod-eu-west1"},
+		},
 		{
 			name:     "regex pattern with character class",
 			contexts: []string{"prod-cluster", "dev-cluster", "staging-cluster"},
@@ -158,14 +172,14 @@ func TestFilterContexts(t *testing.T) {
 			contexts:  []string{"prod-cluster", "dev-cluster"},
 			patterns:  []string{"[invalid"},
 			wantError: true,
-			errorMsg:  "invalid regex pattern",
+			errorMsg:  "invalid --filter regex pattern \"[invalid\"",
 		},
 		{
 			name:      "invalid regex pattern in multiple patterns",
 			contexts:  []string{"prod-cluster", "dev-cluster"},
 			patterns:  []string{"prod", "[invalid", "dev"},
 			wantError: true,
-			errorMsg:  "invalid regex pattern",
+			errorMsg:  "invalid --filter regex pattern \"[invalid\"",
 		},
 		{
 			name:     "complex regex pattern",
@@ -179,6 +193,18 @@ func TestFilterContexts(t *testing.T) {
 			patterns: []string{"\\bprod\\b"},
 			want:     []string{"prod-cluster"},
 		},
+		{
+			name:     "pattern with surrounding whitespace",
+			contexts: []string{"prod-cluster", "dev-cluster"},
+			patterns: []string{" prod "},
+			want:     []string{"prod-cluster"},
+		},
+		{
+			name:     "anchored pattern against context with whitespace",
+			contexts: []string{" prod-cluster", "dev-cluster"},
+			patterns: []string{"^prod-cluster$"},
+			want:     []string{" prod-cluster"},
+		},
 		{
 			name:     "pattern with quantifier",
 			contexts: []string{"prod-cluster", "prodd-cluster", "dev-cluster"},
@@ -222,3 +248,541 @@ func TestFilterContexts(t *testing.T) {
 		})
 	}
 }
+
+func TestContextGroupLabels(t *testing.T) {
+	setGlobal(t, &contextGroup, `^(?P<env>\w+)-(?P<region>[\w-]+)$`)
+	regex, err := compileContextGroup()
+	if err != nil {
+		t.Fatalf("compileContextGroup() error = %v", err)
+	}
+
+	got := contextGroupLabels(regex, "prod-us-east-1")
+	want := map[string]string{"env": "prod", "region": "us-east-1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("contextGroupLabels() = %v, want %v", got, want)
+	}
+
+	if got := contextGroupLabels(regex, "minikube"); got != nil {
+		t.Errorf("contextGroupLabels() for non-matching context = %v, want nil", got)
+	}
+}
+
+func TestCompileContextGroupErrors(t *testing.T) {
+	setGlobal(t, &contextGroup, `^(\w+)-`)
+	if _, err := compileContextGroup(); err == nil {
+		t.Errorf("compileContextGroup() expected error for pattern without named groups")
+	}
+
+	setGlobal(t, &contextGroup, `(?P<env>`)
+	if _, err := compileContextGroup(); err == nil {
+		t.Errorf("compileContextGroup() expected error for invalid pattern")
+	}
+}
+
+func TestGroupContexts(t *testing.T) {
+	setGlobal(t, &contextGroup, `^(?P<env>\w+)-(?P<region>[\w-]+)$`)
+	regex, err := compileContextGroup()
+	if err != nil {
+		t.Fatalf("compileContextGroup() error = %v", err)
+	}
+
+	contexts := []string{"prod-us-west-2", "minikube", "dev-us-east-1", "prod-eu-west-1", "kind", "dev-eu-west-1", "prod-us-east-1"}
+	got := groupContexts(regex, contexts)
+	want := []string{"dev-eu-west-1", "dev-us-east-1", "prod-eu-west-1", "prod-us-east-1", "prod-us-west-2", "minikube", "kind"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupContexts() = %v, want %v", got, want)
+	}
+}
+
+func TestFilterContextsExact(t *testing.T) {
+	setGlobal(t, &filterExact, true)
+	contexts := []string{"prod", "prod-eu", "Prod.us", "staging"}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{name: "exact name only", patterns: []string{"prod"}, want: []string{"prod"}},
+		{name: "case-insensitive", patterns: []string{"PROD-EU"}, want: []string{"prod-eu"}},
+		{name: "trimmed", patterns: []string{"  staging "}, want: []string{"staging"}},
+		{name: "regex characters are literal", patterns: []string{"prod.us"}, want: []string{"Prod.us"}},
+		{name: "no substring match", patterns: []string{"stag"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterContexts(contexts, tt.patterns)
+			if err != nil {
+				t.Fatalf("filterContexts() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterContexts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResumeContexts(t *testing.T) {
+	contexts := []string{"ctx-e", "ctx-a", "ctx-d", "ctx-b", "ctx-c"}
+	tests := []struct {
+		name       string
+		startAfter string
+		limit      int
+		want       []string
+	}{
+		{name: "first chunk", limit: 2, want: []string{"ctx-a", "ctx-b"}},
+		{name: "second chunk", startAfter: "ctx-b", limit: 2, want: []string{"ctx-c", "ctx-d"}},
+		{name: "last partial chunk", startAfter: "ctx-d", limit: 2, want: []string{"ctx-e"}},
+		{name: "resume without limit", startAfter: "ctx-c", want: []string{"ctx-d", "ctx-e"}},
+		{name: "resume point no longer exists", startAfter: "ctx-bb", limit: 1, want: []string{"ctx-c"}},
+		{name: "nothing left", startAfter: "ctx-e", limit: 2, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resumeContexts(contexts, tt.startAfter, tt.limit)
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("resumeContexts(%q, %d) = %v, want %v", tt.startAfter, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResumeContextsCustomOrder(t *testing.T) {
+	contexts := []string{"ctx-e", "ctx-a", "ctx-d", "ctx-b", "ctx-c"}
+	tests := []struct {
+		name       string
+		set        func(t *testing.T)
+		startAfter string
+		limit      int
+		want       []string
+	}{
+		{name: "sort-contexts", set: func(t *testing.T) { setGlobal(t, &sortOrder, sortByCluster) }, limit: 2, want: []string{"ctx-e", "ctx-a"}},
+		{name: "context-order-file", set: func(t *testing.T) { setGlobal(t, &contextOrderFile, "order.txt") }, startAfter: "ctx-a", limit: 2, want: []string{"ctx-d", "ctx-b"}},
+		{name: "priority", set: func(t *testing.T) { setGlobal(t, &priorityContexts, []string{"ctx-c"}) }, startAfter: "ctx-b", want: []string{"ctx-c"}},
+		{name: "unknown resume point", set: func(t *testing.T) { setGlobal(t, &priorityContexts, []string{"ctx-c"}) }, startAfter: "ctx-bb", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.set(t)
+			got := resumeContexts(contexts, tt.startAfter, tt.limit)
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("resumeContexts(%q, %d) = %v, want %v", tt.startAfter, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetContextsFromEnv(t *testing.T) {
+	writeKubeconfig(t, "dev", "staging", "prod")
+
+	t.Run("env selects contexts", func(t *testing.T) {
+		t.Setenv("KUBECTL_MULTI_CONTEXTS", "prod, dev")
+		contexts, err := getContexts()
+		if err != nil {
+			t.Fatalf("getContexts() error = %v", err)
+		}
+		if !reflect.DeepEqual(contexts, []string{"prod", "dev"}) {
+			t.Errorf("getContexts() = %v, want [prod dev]", contexts)
+		}
+	})
+
+	t.Run("filter overrides env", func(t *testing.T) {
+		t.Setenv("KUBECTL_MULTI_CONTEXTS", "prod,dev")
+		setGlobal(t, &filterPatterns, []string{"staging"})
+		contexts, err := getContexts()
+		if err != nil {
+			t.Fatalf("getContexts() error = %v", err)
+		}
+		if !reflect.DeepEqual(contexts, []string{"staging"}) {
+			t.Errorf("getContexts() = %v, want [staging]", contexts)
+		}
+	})
+
+	t.Run("custom env variable", func(t *testing.T) {
+		t.Setenv("KUBECTL_MULTI_CONTEXTS", "prod")
+		t.Setenv("CI_CLUSTERS", "staging")
+		setGlobal(t, &kubeContextEnv, "CI_CLUSTERS")
+		contexts, err := getContexts()
+		if err != nil {
+			t.Fatalf("getContexts() error = %v", err)
+		}
+		if !reflect.DeepEqual(contexts, []string{"staging"}) {
+			t.Errorf("getContexts() = %v, want [staging]", contexts)
+		}
+	})
+
+	t.Run("unknown context", func(t *testing.T) {
+		t.Setenv("KUBECTL_MULTI_CONTEXTS", "prod,qa")
+		_, err := getContexts()
+		if err == nil || !strings.Contains(err.Error(), "qa") {
+			t.Errorf("getContexts() error = %v, want unknown context qa", err)
+		}
+	})
+}
+
+func TestSortContexts(t *testing.T) {
+	config := `apiVersion: v1
+kind: Config
+current-context: staging
+contexts:
+- name: prod-us
+  context:
+    cluster: us
+- name: dev
+  context:
+    cluster: us
+- name: staging
+  context:
+    cluster: eu
+- name: prod-eu
+  context:
+    cluster: eu
+`
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	t.Setenv("KUBECONFIG", path)
+	contexts := []string{"prod-us", "dev", "staging", "prod-eu"}
+
+	tests := []struct {
+		order    string
+		expected []string
+	}{
+		{order: sortByName, expected: []string{"dev", "prod-eu", "prod-us", "staging"}},
+		{order: sortByCluster, expected: []string{"prod-eu", "staging", "dev", "prod-us"}},
+		{order: sortByCurrentFirst, expected: []string{"staging", "dev", "prod-eu", "prod-us"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			sorted, err := sortContexts(contexts, tt.order)
+			if err != nil {
+				t.Fatalf("sortContexts() error = %v", err)
+			}
+			if !reflect.DeepEqual(sorted, tt.expected) {
+				t.Errorf("sortContexts() = %v, want %v", sorted, tt.expected)
+			}
+		})
+	}
+
+	if _, err := sortContexts(contexts, "random"); err == nil {
+		t.Errorf("sortContexts() expected error for invalid order")
+	}
+}
+
+func TestSortContextsOrderFile(t *testing.T) {
+	orderFile := filepath.Join(t.TempDir(), "order")
+	if err := os.WriteFile(orderFile, []byte("# tier 0 first\nprod-us\nprod-eu\nretired\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	setGlobal(t, &contextOrderFile, orderFile)
+
+	var sorted []string
+	stderr := captureStderr(t, func() {
+		var err error
+		sorted, err = sortContexts([]string{"staging", "prod-eu", "dev", "prod-us"}, sortByName)
+		if err != nil {
+			t.Errorf("sortContexts() error = %v", err)
+		}
+	})
+
+	expected := []string{"prod-us", "prod-eu", "dev", "staging"}
+	if !reflect.DeepEqual(sorted, expected) {
+		t.Errorf("sortContexts() = %v, want %v", sorted, expected)
+	}
+	if !strings.Contains(stderr, "follow the listed ones: dev, staging") {
+		t.Errorf("stderr = %q, want a note about the unlisted contexts", stderr)
+	}
+
+	setGlobal(t, &contextOrderFile, filepath.Join(t.TempDir(), "missing"))
+	if _, err := sortContexts([]string{"dev"}, sortByName); err == nil {
+		t.Errorf("sortContexts() expected error for a missing order file")
+	}
+}
+
+func TestFilterAffixes(t *testing.T) {
+	contexts := []string{"prod-us", "prod-eu", "PROD-ap", "staging-eu", "dev-us"}
+	tests := []struct {
+		name     string
+		prefixes []string
+		suffixes []string
+		expected []string
+	}{
+		{
+			name:     "prefix only",
+			prefixes: []string{"prod-"},
+			expected: []string{"prod-us", "prod-eu", "PROD-ap"},
+		},
+		{
+			name:     "suffix only",
+			suffixes: []string{"-EU"},
+			expected: []string{"prod-eu", "staging-eu"},
+		},
+		{
+			name:     "prefix and suffix",
+			prefixes: []string{"prod-"},
+			suffixes: []string{"-eu", "-ap"},
+			expected: []string{"prod-eu", "PROD-ap"},
+		},
+		{
+			name:     "several prefixes",
+			prefixes: []string{"staging", "dev"},
+			expected: []string{"staging-eu", "dev-us"},
+		},
+		{
+			name:     "no match",
+			prefixes: []string{"qa-"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterAffixes(contexts, tt.prefixes, tt.suffixes); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("filterAffixes() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetContextsPrefixWithFilter(t *testing.T) {
+	writeKubeconfig(t, "prod-us-1", "prod-eu-1", "prod-eu-canary", "staging-eu-1")
+	setGlobal(t, &filterPatterns, []string{"canary"})
+	setGlobal(t, &prefixFilters, []string{"prod-"})
+	setGlobal(t, &suffixFilters, []string{"-1", "-canary"})
+
+	contexts, err := getContexts()
+	if err != nil {
+		t.Fatalf("getContexts() error = %v", err)
+	}
+	if !reflect.DeepEqual(contexts, []string{"prod-eu-canary"}) {
+		t.Errorf("getContexts() = %v, want [prod-eu-canary]", contexts)
+	}
+}
+
+func TestGetContextsExclude(t *testing.T) {
+	writeKubeconfig(t, "prod-us", "prod-eu", "sandbox-alice", "kind-local", "staging")
+
+	tests := []struct {
+		name     string
+		filters  []string
+		excludes []string
+		env      string
+		want     []string
+		wantErr  string
+	}{
+		{name: "no excludes", want: []string{"prod-us", "prod-eu", "sandbox-alice", "kind-local", "staging"}},
+		{name: "excludes sandbox and kind", excludes: []string{"^sandbox-", "^KIND-"}, want: []string{"prod-us", "prod-eu", "staging"}},
+		{name: "after the include filter", filters: []string{"prod"}, excludes: []string{"-eu$"}, want: []string{"prod-us"}},
+		{name: "after env selection", env: "staging,kind-local", excludes: []string{"kind"}, want: []string{"staging"}},
+		{name: "excludes everything", filters: []string{"prod"}, excludes: []string{"."}, wantErr: "excludes every selected context"},
+		{name: "invalid pattern", excludes: []string{"[kind"}, wantErr: `invalid --exclude regex pattern "[kind"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KUBECTL_MULTI_CONTEXTS", tt.env)
+			setGlobal(t, &filterPatterns, tt.filters)
+			setGlobal(t, &excludePatterns, tt.excludes)
+
+			contexts, err := getContexts()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("getContexts() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getContexts() error = %v", err)
+			}
+			if !reflect.DeepEqual(contexts, tt.want) {
+				t.Errorf("getContexts() = %v, want %v", contexts, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetContextsExplicit(t *testing.T) {
+	writeKubeconfig(t, "prod-us", "prod-eu", "staging")
+	t.Setenv("KUBECTL_MULTI_CONTEXTS", "staging")
+
+	tests := []struct {
+		name     string
+		contexts []string
+		filters  []string
+		want     []string
+		wantErr  string
+	}{
+		{name: "exact set in order", contexts: []string{"staging", "prod-eu"}, want: []string{"staging", "prod-eu"}},
+		{name: "unknown context", contexts: []string{"prod-us", "prod-uk"}, wantErr: "contexts from --context not found in kubeconfig: prod-uk"},
+		{name: "combined with a filter", contexts: []string{"prod-us"}, filters: []string{"prod"}, wantErr: "--context cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGlobal(t, &explicitContexts, tt.contexts)
+			setGlobal(t, &filterPatterns, tt.filters)
+			contexts, err := getContexts()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("getContexts() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getContexts() error = %v", err)
+			}
+			if !reflect.DeepEqual(contexts, tt.want) {
+				t.Errorf("getContexts() = %v, want %v", contexts, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextFlagParsing(t *testing.T) {
+	flag := rootCmd.PersistentFlags().Lookup("context")
+	setGlobal(t, &explicitContexts, []string{})
+	for _, value := range []string{"ctx1,ctx2", "ctx3"} {
+		if err := flag.Value.Set(value); err != nil {
+			t.Fatalf("Set(%q) error = %v", value, err)
+		}
+	}
+	if !reflect.DeepEqual(explicitContexts, []string{"ctx1", "ctx2", "ctx3"}) {
+		t.Errorf("--context ctx1,ctx2 --context ctx3 = %v, want [ctx1 ctx2 ctx3]", explicitContexts)
+	}
+}
+
+func TestGetContextsClusterUserFilter(t *testing.T) {
+	writeKubeconfigContent(t, `apiVersion: v1
+kind: Config
+contexts:
+- name: payments
+  context: {cluster: eks-payments, user: sso-admin}
+- name: search
+  context: {cluster: eks-search, user: sso-readonly}
+- name: legacy
+  context: {cluster: gke-legacy, user: sso-admin}
+`)
+
+	tests := []struct {
+		name     string
+		clusters []string
+		users    []string
+		filters  []string
+		want     []string
+		wantErr  string
+	}{
+		{name: "cluster", clusters: []string{"^eks-"}, want: []string{"payments", "search"}},
+		{name: "user", users: []string{"SSO-ADMIN"}, want: []string{"payments", "legacy"}},
+		{name: "cluster and user", clusters: []string{"^eks-"}, users: []string{"admin"}, want: []string{"payments"}},
+		{name: "several clusters", clusters: []string{"search", "legacy"}, want: []string{"search", "legacy"}},
+		{name: "with a name filter", clusters: []string{"eks"}, filters: []string{"^s"}, want: []string{"search"}},
+		{name: "no match", users: []string{"break-glass"}, wantErr: "no contexts match --cluster-filter  --user-filter break-glass"},
+		{name: "invalid pattern", clusters: []string{"(eks"}, wantErr: `invalid --cluster-filter regex pattern "(eks"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGlobal(t, &clusterFilters, tt.clusters)
+			setGlobal(t, &userFilters, tt.users)
+			setGlobal(t, &filterPatterns, tt.filters)
+			contexts, err := getContexts()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("getContexts() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getContexts() error = %v", err)
+			}
+			if !reflect.DeepEqual(contexts, tt.want) {
+				t.Errorf("getContexts() = %v, want %v", contexts, tt.want)
+			}
+		})
+	}
+}
+
+// writeKubeconfigContent writes config as the kubeconfig and points KUBECONFIG at it.
+func writeKubeconfigContent(t *testing.T, config string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	t.Setenv("KUBECONFIG", path)
+	return path
+}
+
+func TestValidateKubeconfig(t *testing.T) {
+	// The prod context refers to a cluster that is not defined, which the
+	// lightweight YAML parse doesn't notice
+	writeKubeconfigContent(t, `apiVersion: v1
+kind: Config
+clusters:
+- name: dev-cluster
+  cluster:
+    server: https://dev.example.com
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: dev
+  context:
+    cluster: dev-cluster
+    user: admin
+- name: prod
+  context:
+    cluster: prod-cluster
+    user: admin
+`)
+
+	contexts, err := getContexts()
+	if err != nil || len(contexts) != 2 {
+		t.Fatalf("getContexts() = %v, %v, want both contexts without validation", contexts, err)
+	}
+
+	setGlobal(t, &validateKubeconfigFlag, true)
+	_, err = getContexts()
+	if err == nil || !strings.Contains(err.Error(), "prod-cluster") {
+		t.Errorf("getContexts() error = %v, want validation error naming prod-cluster", err)
+	}
+}
+
+func TestValidateKubeconfigValid(t *testing.T) {
+	writeKubeconfigContent(t, `apiVersion: v1
+kind: Config
+clusters:
+- name: dev-cluster
+  cluster:
+    server: https://dev.example.com
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: dev
+  context:
+    cluster: dev-cluster
+    user: admin
+`)
+	setGlobal(t, &validateKubeconfigFlag, true)
+	if _, err := getContexts(); err != nil {
+		t.Errorf("getContexts() error = %v, want valid kubeconfig", err)
+	}
+}
+
+func TestGetContextsSurfacesClientcmdFallbackError(t *testing.T) {
+	// No contexts for the YAML parse, and clusters is not a list
+	path := writeKubeconfigContent(t, "apiVersion: v1\nkind: Config\ncontexts: []\nclusters: not-a-list\n")
+
+	_, err := getContexts()
+	if err == nil || !strings.Contains(err.Error(), path) || !strings.Contains(err.Error(), "clientcmd") {
+		t.Errorf("getContexts() error = %v, want the clientcmd error for %s", err, path)
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"os"
+	"slices"
+	"strings"
+)
+
+// formatDelimitedOutput prints the merged table of all contexts as CSV, or
+// TSV when comma is a tab, with the context as the first column. Tables with
+// other columns, such as the kinds of get all, are merged on the union of
+// their columns with empty cells where a table has no such column.
+func formatDelimitedOutput(results []contextResult, comma rune) error {
+	if _, _, err := parseTableOptions(); err != nil {
+		return err
+	}
+	type table struct {
+		context string
+		columns []string
+		rows    [][]string
+	}
+	var tables []table
+	var union []string
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		for _, block := range tableBlocks(result.output) {
+			lines := formatTableLines(strings.Split(block, "\n"), 0, false)
+			if len(lines) < 2 {
+				continue
+			}
+			columns := parseHeader(lines[0])
+			t := table{context: result.context, columns: columnNames(columns)}
+			for _, name := range t.columns {
+				if !seen[name] {
+					seen[name] = true
+					union = append(union, name)
+				}
+			}
+			for _, line := range lines[1:] {
+				if strings.TrimSpace(line) != "" {
+					t.rows = append(t.rows, splitRow(columns, line))
+				}
+			}
+			tables = append(tables, t)
+		}
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+
+	var records [][]string
+	for _, t := range tables {
+		for _, row := range t.rows {
+			cells := make(map[string]string, len(row))
+			for i, cell := range row {
+				cells[t.columns[i]] = cell
+			}
+			record := []string{t.context}
+			for _, name := range union {
+				record = append(record, cells[name])
+			}
+			records = append(records, record)
+		}
+	}
+	if sortColumn != "" {
+		if index := sortColumnIndex(union); index != -1 {
+			slices.SortStableFunc(records, func(a, b []string) int {
+				return compareCells(union[index], a[index+1], b[index+1])
+			})
+		}
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	w.Comma = comma
+	w.Write(append([]string{contextHeader}, union...))
+	w.WriteAll(records)
+	return w.Error()
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatDelimitedOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []contextResult
+		comma    rune
+		expected string
+	}{
+		{
+			name: "csv",
+			results: []contextResult{
+				{context: "ctx1", output: "NAME   READY   STATUS             RESTARTS     AGE\npod1   1/1     Running            0            5m\npod2   0/1     CrashLoopBackOff   7 (2m ago)   1h"},
+				{context: "ctx2", output: "NAME     READY   STATUS    RESTARTS   AGE\npod3,b   1/1     Running   0          3m"},
+				{context: "ctx3", output: "Unable to connect to the server", err: fmt.Errorf("exit status 1")},
+			},
+			comma: ',',
+			expected: "CONTEXT,NAME,READY,STATUS,RESTARTS,AGE\n" +
+				"ctx1,pod1,1/1,Running,0,5m\n" +
+				"ctx1,pod2,0/1,CrashLoopBackOff,7 (2m ago),1h\n" +
+				"ctx2,\"pod3,b\",1/1,Running,0,3m\n",
+		},
+		{
+			name: "tsv",
+			results: []contextResult{
+				{context: "ctx1", output: "NAME   STATUS\npod1   Running"},
+			},
+			comma:    '\t',
+			expected: "CONTEXT\tNAME\tSTATUS\nctx1\tpod1\tRunning\n",
+		},
+		{
+			name: "get all",
+			results: []contextResult{
+				{context: "ctx1", output: "NAME      READY\npod/web   1/1\n\nNAME      TYPE\nsvc/web   ClusterIP"},
+			},
+			comma:    ',',
+			expected: "CONTEXT,NAME,READY,TYPE\nctx1,pod/web,1/1,\nctx1,svc/web,,ClusterIP\n",
+		},
+		{
+			name: "no tables",
+			results: []contextResult{
+				{context: "ctx1", output: ""},
+			},
+			comma: ',',
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output string
+			captureStderr(t, func() {
+				output = captureStdout(t, func() {
+					if err := formatDelimitedOutput(tt.results, tt.comma); err != nil {
+						t.Errorf("formatDelimitedOutput() error = %v", err)
+					}
+				})
+			})
+			if output != tt.expected {
+				t.Errorf("formatDelimitedOutput() output =\n%s\nwant\n%s", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunCommandCSV(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	fakeKubectl(t, func(args []string) (string, error) {
+		if strings.Contains(strings.Join(args, " "), "csv") {
+			t.Errorf("kubectl ran %v, want the output flag removed", args)
+		}
+		return "NAME       STATUS\npod-" + argValue(args, "--context") + "   Running\n", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods", "-o=csv"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+	expected := "CONTEXT,NAME,STATUS\nctx1,pod-ctx1,Running\nctx2,pod-ctx2,Running\n"
+	if output != expected {
+		t.Errorf("output =\n%s\nwant\n%s", output, expected)
+	}
+}
+
+func TestRemoveOutputFlag(t *testing.T) {
+	for _, args := range [][]string{
+		{"pods", "-o", "csv", "-A"},
+		{"pods", "-o=csv", "-A"},
+		{"pods", "-ocsv", "-A"},
+		{"pods", "--output=csv", "-A"},
+	} {
+		if got := removeOutputFlag(args); !reflect.DeepEqual(got, []string{"pods", "-A"}) {
+			t.Errorf("removeOutputFlag(%v) = %v, want [pods -A]", args, got)
+		}
+	}
+}
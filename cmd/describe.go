@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe TYPE [NAME]",
+	Short: "Run kubectl describe against all contexts",
+	Long: `Run kubectl describe against all contexts in parallel, printing each context's output under a
+===== context: NAME ===== banner.`,
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCommand("describe", args)
+	},
+}
+
+// formatDescribeOutput prints each context's block-style output under a
+// "===== context: X =====" banner, separated by blank lines
+func formatDescribeOutput(results []contextResult) error {
+	first := true
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		output := strings.TrimSpace(result.output)
+		if output == "" {
+			continue
+		}
+		if !first {
+			fmt.Println()
+		}
+		first = false
+		fmt.Printf("===== context: %s =====\n%s\n", colorizeContext(result.context), output)
+	}
+	return nil
+}
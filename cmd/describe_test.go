@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRunCommandDescribe(t *testing.T) {
+	writeKubeconfig(t, "prod-eu", "prod-us", "staging")
+	fakeKubectl(t, func(args []string) (string, error) {
+		switch argValue(args, "--context") {
+		case "staging":
+			return `Error from server (NotFound): pods "web-0" not found`, fmt.Errorf("exit status 1")
+		default:
+			return "Name:         web-0\nNamespace:    default\nEvents:       <none>\n", nil
+		}
+	})
+
+	var stderr string
+	output := captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := describeCmd.RunE(describeCmd, []string{"pod", "web-0"}); err != nil {
+				t.Errorf("describe error = %v", err)
+			}
+		})
+	})
+
+	block := "Name:         web-0\nNamespace:    default\nEvents:       <none>\n"
+	expected := "===== context: prod-eu =====\n" + block + "\n===== context: prod-us =====\n" + block
+	if output != expected {
+		t.Errorf("describe output =\n%s\nwant\n%s", output, expected)
+	}
+	if !strings.Contains(stderr, "Context staging: Error") || !strings.Contains(stderr, "NotFound") {
+		t.Errorf("stderr = %q, want the staging error", stderr)
+	}
+}
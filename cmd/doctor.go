@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// lookPath finds executables on PATH. It is a variable so tests can simulate a missing kubectl.
+var lookPath = exec.LookPath
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check kubectl and kubeconfig setup",
+	Long:  `Check that kubectl is installed and that the kubeconfig can be found and parsed, printing hints for anything that is wrong.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctor()
+	},
+}
+
+// doctorCheck prints the result of a single doctor check and its hint on failure
+func doctorCheck(ok bool, message, hint string) {
+	if ok {
+		fmt.Printf("✓ %s\n", message)
+		return
+	}
+	fmt.Printf("✗ %s\n", message)
+	if hint != "" {
+		fmt.Printf("  hint: %s\n", hint)
+	}
+}
+
+func runDoctor() error {
+	problems := 0
+
+	kubectlPath, err := lookPath("kubectl")
+	if err != nil {
+		problems++
+		doctorCheck(false, "kubectl not found in PATH", "install kubectl: https://kubernetes.io/docs/tasks/tools/")
+	} else {
+		doctorCheck(true, fmt.Sprintf("kubectl found at %s", kubectlPath), "")
+		output, _, err := kubectlExec(context.Background(), []string{"version", "--client"})
+		version := strings.TrimSpace(strings.SplitN(strings.TrimSpace(output), "\n", 2)[0])
+		ok := err == nil && version != ""
+		if !ok {
+			problems++
+		}
+		doctorCheck(ok, fmt.Sprintf("kubectl version: %s", version), "check that the kubectl binary runs: kubectl version --client")
+	}
+
+	kubeconfigPath := getKubeconfigPath()
+	if kubeconfigPath == "" {
+		problems++
+		doctorCheck(false, "could not determine kubeconfig path", "set KUBECONFIG or make sure your home directory is set")
+		return fmt.Errorf("doctor found %d problem(s)", problems)
+	}
+	doctorCheck(true, fmt.Sprintf("kubeconfig path: %s", kubeconfigPath), "")
+
+	contexts := 0
+	var currentContext string
+	for _, path := range filepath.SplitList(kubeconfigPath) {
+		if path == "" {
+			continue
+		}
+		file, err := os.ReadFile(path)
+		if err != nil {
+			problems++
+			doctorCheck(false, fmt.Sprintf("cannot read %s: %v", path, err), "check that the file exists and is readable")
+			continue
+		}
+		var config Kubeconfig
+		if err := yaml.Unmarshal(file, &config); err != nil {
+			problems++
+			doctorCheck(false, fmt.Sprintf("cannot parse %s: %v", path, err), "fix the YAML syntax, e.g. with kubectl config view")
+			continue
+		}
+		doctorCheck(true, fmt.Sprintf("%s parsed (%d contexts)", path, len(config.Contexts)), "")
+		contexts += len(config.Contexts)
+		if currentContext == "" {
+			currentContext = config.CurrentContext
+		}
+	}
+
+	if contexts == 0 {
+		problems++
+		doctorCheck(false, "no contexts found", "add a context with kubectl config set-context")
+	} else {
+		doctorCheck(true, fmt.Sprintf("%d contexts discovered", contexts), "")
+	}
+
+	doctorCheck(currentContext != "", fmt.Sprintf("current-context: %s", currentContext), "set one with kubectl config use-context NAME")
+
+	if problems > 0 {
+		return fmt.Errorf("doctor found %d problem(s)", problems)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDoctor(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	setGlobal(t, &lookPath, func(file string) (string, error) {
+		return "/usr/local/bin/" + file, nil
+	})
+	fakeKubectl(t, func(args []string) (string, error) {
+		return "Client Version: v1.34.3\nKustomize Version: v5.7.1\n", nil
+	})
+
+	var err error
+	output := captureStdout(t, func() {
+		err = runDoctor()
+	})
+
+	if err != nil {
+		t.Errorf("runDoctor() error = %v, want nil", err)
+	}
+	for _, want := range []string{
+		"✓ kubectl found at /usr/local/bin/kubectl",
+		"✓ kubectl version: Client Version: v1.34.3",
+		"✓ 2 contexts discovered",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("runDoctor() output should contain %q, got:\n%s", want, output)
+		}
+	}
+	if !strings.Contains(output, "✗ current-context") {
+		t.Errorf("runDoctor() should report the missing current-context, got:\n%s", output)
+	}
+}
+
+func TestRunDoctorMissingKubectl(t *testing.T) {
+	writeKubeconfig(t, "ctx1")
+	setGlobal(t, &lookPath, func(file string) (string, error) {
+		return "", fmt.Errorf("executable file not found in $PATH")
+	})
+
+	var err error
+	output := captureStdout(t, func() {
+		err = runDoctor()
+	})
+
+	if err == nil {
+		t.Errorf("runDoctor() expected error when kubectl is missing")
+	}
+	if !strings.Contains(output, "✗ kubectl not found in PATH") || !strings.Contains(output, "hint: install kubectl") {
+		t.Errorf("runDoctor() should report the missing kubectl with a hint, got:\n%s", output)
+	}
+}
+
+func TestRunDoctorFailingKubectl(t *testing.T) {
+	writeKubeconfig(t, "ctx1")
+	setGlobal(t, &lookPath, func(file string) (string, error) {
+		return "/usr/local/bin/" + file, nil
+	})
+	fakeKubectl(t, func(args []string) (string, error) {
+		return "", fmt.Errorf("exec format error")
+	})
+
+	var err error
+	output := captureStdout(t, func() {
+		err = runDoctor()
+	})
+
+	if err == nil || err.Error() != "doctor found 1 problem(s)" {
+		t.Errorf("runDoctor() error = %v, want doctor found 1 problem(s)", err)
+	}
+	if !strings.Contains(output, "✗ kubectl version") {
+		t.Errorf("runDoctor() should report the failing kubectl, got:\n%s", output)
+	}
+}
+
+func TestRunDoctorUnparseableKubeconfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("contexts: [\n  - name: broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", path)
+	setGlobal(t, &lookPath, func(file string) (string, error) {
+		return "/usr/local/bin/" + file, nil
+	})
+	fakeKubectl(t, func(args []string) (string, error) {
+		return "Client Version: v1.34.3\n", nil
+	})
+
+	var err error
+	output := captureStdout(t, func() {
+		err = runDoctor()
+	})
+
+	if err == nil {
+		t.Errorf("runDoctor() expected error for an unparseable kubeconfig")
+	}
+	if !strings.Contains(output, "✗ cannot parse "+path) || !strings.Contains(output, "✗ no contexts found") {
+		t.Errorf("runDoctor() should report the parse failure, got:\n%s", output)
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// serverErrorPattern matches "Error from server (Forbidden): ..." style errors
+var serverErrorPattern = regexp.MustCompile(`^Error from server \(([^)]+)\): (.*)$`)
+
+// unsupportedSelectorPattern matches kubectl's error for a field selector the
+// resource does not support
+var unsupportedSelectorPattern = regexp.MustCompile(`field label not supported: (\S+)`)
+
+// parseKubectlError recognizes common kubectl error shapes in a failed
+// context's output and returns a short code and the message. Unrecognized
+// output falls back to code "Unknown" with the raw first line.
+func parseKubectlError(output string, err error) (code, message string) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if match := serverErrorPattern.FindStringSubmatch(line); match != nil {
+			return match[1], match[2]
+		}
+		if msg, ok := strings.CutPrefix(line, "Unable to connect to the server: "); ok {
+			return "Unreachable", msg
+		}
+		if msg, ok := strings.CutPrefix(line, "error: "); ok {
+			if strings.HasPrefix(msg, "the server doesn't have a resource type") {
+				return "UnknownResource", msg
+			}
+		}
+	}
+	return "Unknown", errorSummary(contextResult{output: output, err: err})
+}
+
+// printContextError reports a failed context on stderr. With --pretty-errors
+// failures are collected into a single table instead, see printErrorTable.
+func printContextError(context string, err error, output string) {
+	if prettyErrors {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Context %s: Error: %v\n", colorizeContext(context), err)
+	if output != "" {
+		fmt.Fprintf(os.Stderr, "Output: %s\n", output)
+	}
+}
+
+// printPlainContextError is printContextError for the version, JSON and YAML
+// formatters, which have always printed the context without color. The JSON
+// and YAML formatters pass no output: they add it to the items instead.
+func printPlainContextError(context string, err error, output string) {
+	if prettyErrors {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Context %s: Error: %v\n", context, err)
+	if output != "" {
+		fmt.Fprintf(os.Stderr, "Output: %s\n", output)
+	}
+}
+
+// printErrorTable prints a CONTEXT/CODE/MESSAGE table of the failed contexts on stderr
+func printErrorTable(results []contextResult) {
+	if rows := errorTableRows(results); len(rows) > 1 {
+		writeTable(os.Stderr, rows)
+	}
+}
+
+// errorTableRows returns the header and one parsed row per failed context
+func errorTableRows(results []contextResult) [][]string {
+	rows := [][]string{{contextHeader, "CODE", "MESSAGE"}}
+	for _, result := range results {
+		if result.err == nil {
+			continue
+		}
+		code, message := parseKubectlError(result.output, result.err)
+		rows = append(rows, []string{result.context, code, message})
+	}
+	return rows
+}
+
+// printWarnings prints a CONTEXT/WARNING table on stderr of what kubectl
+// printed on stderr for the contexts that succeeded
+func printWarnings(results []contextResult) {
+	rows := [][]string{{contextHeader, "WARNING"}}
+	for _, result := range results {
+		for _, line := range strings.Split(strings.TrimSpace(result.stderr), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				rows = append(rows, []string{result.context, line})
+			}
+		}
+	}
+	if len(rows) > 1 {
+		writeTable(os.Stderr, rows)
+	}
+}
+
+// warnUnsupportedSelectors prints a prominent warning on stderr for every
+// context that rejected a --field-selector, so its missing results are not
+// mistaken for an empty filtered list
+func warnUnsupportedSelectors(results []contextResult) {
+	for _, result := range results {
+		match := unsupportedSelectorPattern.FindStringSubmatch(result.output + "\n" + result.stderr)
+		if match == nil {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "WARNING: context %s does not support field selector %q; kubectl returned no results for it\n",
+			colorizeContext(result.context), match[1])
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseKubectlError(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		wantCode    string
+		wantMessage string
+	}{
+		{
+			name:        "forbidden",
+			output:      `Error from server (Forbidden): pods is forbidden: User "jane" cannot list resource "pods" in API group "" in the namespace "default"`,
+			wantCode:    "Forbidden",
+			wantMessage: `pods is forbidden: User "jane" cannot list resource "pods" in API group "" in the namespace "default"`,
+		},
+		{
+			name:        "not found",
+			output:      `Error from server (NotFound): namespaces "payments" not found`,
+			wantCode:    "NotFound",
+			wantMessage: `namespaces "payments" not found`,
+		},
+		{
+			name:        "unreachable",
+			output:      "Unable to connect to the server: dial tcp 10.0.0.1:443: i/o timeout\n",
+			wantCode:    "Unreachable",
+			wantMessage: "dial tcp 10.0.0.1:443: i/o timeout",
+		},
+		{
+			name:        "unknown resource type",
+			output:      `error: the server doesn't have a resource type "widgets"`,
+			wantCode:    "UnknownResource",
+			wantMessage: `the server doesn't have a resource type "widgets"`,
+		},
+		{
+			name:        "error after warning lines",
+			output:      "Warning: v1 ComponentStatus is deprecated\nError from server (Unauthorized): the server has asked for the client to provide credentials",
+			wantCode:    "Unauthorized",
+			wantMessage: "the server has asked for the client to provide credentials",
+		},
+		{
+			name:        "unrecognized output",
+			output:      "something unexpected happened\nmore detail",
+			wantCode:    "Unknown",
+			wantMessage: "something unexpected happened",
+		},
+		{
+			name:        "no output",
+			output:      "",
+			wantCode:    "Unknown",
+			wantMessage: "signal: killed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, message := parseKubectlError(tt.output, fmt.Errorf("signal: killed"))
+			if code != tt.wantCode || message != tt.wantMessage {
+				t.Errorf("parseKubectlError() = %q, %q, want %q, %q", code, message, tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestDefaultErrorRendering(t *testing.T) {
+	setGlobal(t, &colorByContext, colorAlways)
+	failed := []contextResult{{context: "ctx1", output: "Unable to connect to the server", err: fmt.Errorf("exit status 1")}}
+	tests := []struct {
+		name       string
+		format     outputFormat
+		subcommand string
+		expected   string
+	}{
+		{name: "table", format: formatDefault, subcommand: "get", expected: "Context " + colorizeContext("ctx1") + ": Error: exit status 1\nOutput: Unable to connect to the server\n"},
+		{name: "version", format: formatDefault, subcommand: "version", expected: "Context ctx1: Error: exit status 1\nOutput: Unable to connect to the server\n"},
+		{name: "json", format: formatJSON, subcommand: "get", expected: "Context ctx1: Error: exit status 1\n"},
+		{name: "yaml", format: formatYAML, subcommand: "get", expected: "Context ctx1: Error: exit status 1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := captureStderr(t, func() {
+				captureStdout(t, func() {
+					formatOutput(failed, tt.format, tt.subcommand)
+				})
+			})
+			if stderr != tt.expected {
+				t.Errorf("stderr = %q, want %q", stderr, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteErrorTable(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "NAME\npod1"},
+		{context: "ctx2", output: `Error from server (Forbidden): pods is forbidden`, err: fmt.Errorf("exit status 1")},
+		{context: "production", output: "Unable to connect to the server: EOF", err: fmt.Errorf("exit status 1")},
+	}
+
+	var buf bytes.Buffer
+	writeTable(&buf, errorTableRows(results))
+
+	expected := "CONTEXT     CODE         MESSAGE\n" +
+		"ctx2        Forbidden    pods is forbidden\n" +
+		"production  Unreachable  EOF\n"
+	if buf.String() != expected {
+		t.Errorf("error table = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestRunCommandWarnUnsupportedSelectors(t *testing.T) {
+	writeKubeconfig(t, "new", "old")
+	setGlobal(t, &warnSelectors, true)
+	fakeKubectl(t, func(args []string) (string, error) {
+		if argValue(args, "--context") == "old" {
+			return `Error from server (BadRequest): Unable to find "/v1, Resource=pods" that match label selector "", field selector "spec.nodeName=node-1": field label not supported: spec.nodeName`, fmt.Errorf("exit status 1")
+		}
+		return "NAME   READY\npod1   1/1\n", nil
+	})
+
+	var stderr string
+	captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := runCommand("get", []string{"pods", "--field-selector", "spec.nodeName=node-1"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+	})
+
+	warning := `WARNING: context old does not support field selector "spec.nodeName"; kubectl returned no results for it`
+	if !strings.Contains(stderr, warning) {
+		t.Errorf("stderr should contain %q, got:\n%s", warning, stderr)
+	}
+	if strings.Contains(stderr, "context new does not support") {
+		t.Errorf("stderr should not warn about the context that supports the selector:\n%s", stderr)
+	}
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Print the events of all contexts as one chronological stream",
+	Long: `Run kubectl get events against all contexts in parallel and merge the events into a single stream sorted
+by time, with a TIME and CONTEXT column. It is the same as --timeline get events; add --event-type Warning to
+only show warnings.`,
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		args, typ, err := cutEventType(args)
+		if err != nil {
+			return err
+		}
+		if typ != "" {
+			eventType = typ
+		}
+		timeline = true
+		return runCommand("get", append([]string{"events"}, args...))
+	},
+}
+
+// cutEventType removes --event-type from the events arguments, since kubectl get
+// events has no such flag, and returns its value
+func cutEventType(args []string) ([]string, string, error) {
+	var kept []string
+	typ := ""
+	for i := 0; i < len(args); i++ {
+		if value, ok := strings.CutPrefix(args[i], "--event-type="); ok {
+			typ = value
+			continue
+		}
+		if args[i] == "--event-type" {
+			if i+1 == len(args) {
+				return nil, "", fmt.Errorf("flag --event-type needs a value, e.g. --event-type Warning")
+			}
+			typ = args[i+1]
+			i++
+			continue
+		}
+		kept = append(kept, args[i])
+	}
+	return kept, typ, nil
+}
+
+// timelineEvent is a row of kubectl get events output with its resolved time
+type timelineEvent struct {
+	time    time.Time
+	context string
+	cells   map[string]string
+}
+
+// formatEventTimeline merges get events output from all contexts into one
+// chronological stream with a TIME and CONTEXT column, optionally keeping
+// only events of the --event-type given
+func formatEventTimeline(results []contextResult) error {
+	var events []timelineEvent
+	var columns []string
+	seenColumn := map[string]bool{"LAST SEEN": true}
+
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+
+		output := strings.TrimSpace(result.output)
+		if output == "" || strings.HasPrefix(output, "No resources found") {
+			continue
+		}
+		lines := strings.Split(output, "\n")
+		header := parseHeader(lines[0])
+		lastSeenIdx := columnIndex(header, "LAST SEEN")
+		if lastSeenIdx == -1 {
+			return fmt.Errorf("context %s: --timeline requires get events output with a LAST SEEN column", result.context)
+		}
+		for _, column := range header {
+			if !seenColumn[column.name] {
+				seenColumn[column.name] = true
+				columns = append(columns, column.name)
+			}
+		}
+
+		for _, line := range lines[1:] {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			cells := splitRow(header, line)
+			event := timelineEvent{context: result.context, cells: make(map[string]string)}
+			for i, column := range header {
+				event.cells[column.name] = cells[i]
+			}
+			if eventType != "" && !strings.EqualFold(event.cells["TYPE"], eventType) {
+				continue
+			}
+			if age, ok := parseKubeDuration(cells[lastSeenIdx]); ok {
+				event.time = now().Add(-age).UTC()
+			}
+			events = append(events, event)
+		}
+	}
+
+	// Events without a parseable LAST SEEN go last
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].time.IsZero() || events[j].time.IsZero() {
+			return !events[i].time.IsZero() && events[j].time.IsZero()
+		}
+		return events[i].time.Before(events[j].time)
+	})
+
+	rows := [][]string{append([]string{"TIME", contextHeader}, columns...)}
+	for _, event := range events {
+		timestamp := "<unknown>"
+		if !event.time.IsZero() {
+			timestamp = event.time.Format(time.RFC3339)
+		}
+		row := []string{timestamp, event.context}
+		for _, column := range columns {
+			row = append(row, event.cells[column])
+		}
+		rows = append(rows, row)
+	}
+	if len(events) > 0 {
+		printTable(rows)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFormatEventTimeline(t *testing.T) {
+	setGlobal(t, &now, func() time.Time {
+		return time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	})
+	results := []contextResult{
+		{
+			context: "ctx1",
+			output: "LAST SEEN   TYPE      REASON      OBJECT      MESSAGE\n" +
+				"10m         Normal    Scheduled   pod/api-1   Successfully assigned\n" +
+				"2m          Warning   BackOff     pod/api-1   Back-off restarting failed container\n",
+		},
+		{
+			context: "ctx2",
+			output: "LAST SEEN   TYPE      REASON        OBJECT     MESSAGE\n" +
+				"5m          Warning   FailedMount   pod/db-0   Unable to attach volume\n" +
+				"1h          Normal    Pulled        pod/db-0   Container image pulled\n",
+		},
+	}
+
+	t.Run("chronological across contexts", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			if err := formatEventTimeline(results); err != nil {
+				t.Errorf("formatEventTimeline() error = %v", err)
+			}
+		})
+
+		expected := "TIME                  CONTEXT  TYPE     REASON       OBJECT     MESSAGE\n" +
+			"2024-03-10T11:00:00Z  ctx2     Normal   Pulled       pod/db-0   Container image pulled\n" +
+			"2024-03-10T11:50:00Z  ctx1     Normal   Scheduled    pod/api-1  Successfully assigned\n" +
+			"2024-03-10T11:55:00Z  ctx2     Warning  FailedMount  pod/db-0   Unable to attach volume\n" +
+			"2024-03-10T11:58:00Z  ctx1     Warning  BackOff      pod/api-1  Back-off restarting failed container\n"
+		if output != expected {
+			t.Errorf("formatEventTimeline() output =\n%s\nwant\n%s", output, expected)
+		}
+	})
+
+	t.Run("filtered by type", func(t *testing.T) {
+		setGlobal(t, &eventType, "warning")
+		output := captureStdout(t, func() {
+			if err := formatEventTimeline(results); err != nil {
+				t.Errorf("formatEventTimeline() error = %v", err)
+			}
+		})
+
+		expected := "TIME                  CONTEXT  TYPE     REASON       OBJECT     MESSAGE\n" +
+			"2024-03-10T11:55:00Z  ctx2     Warning  FailedMount  pod/db-0   Unable to attach volume\n" +
+			"2024-03-10T11:58:00Z  ctx1     Warning  BackOff      pod/api-1  Back-off restarting failed container\n"
+		if output != expected {
+			t.Errorf("formatEventTimeline() output =\n%s\nwant\n%s", output, expected)
+		}
+	})
+}
+
+func TestFormatEventTimelineUnknownLastSeen(t *testing.T) {
+	setGlobal(t, &now, func() time.Time {
+		return time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	})
+	results := []contextResult{{
+		context: "ctx1",
+		output: "LAST SEEN   TYPE     REASON    OBJECT     MESSAGE\n" +
+			"<unknown>   Normal   Created   pod/db-0   Created container\n" +
+			"5m          Normal   Pulled    pod/db-0   Pulled image\n" +
+			"1h          Normal   Started   pod/db-0   Started container\n",
+	}}
+
+	output := captureStdout(t, func() {
+		if err := formatEventTimeline(results); err != nil {
+			t.Errorf("formatEventTimeline() error = %v", err)
+		}
+	})
+
+	expected := "TIME                  CONTEXT  TYPE    REASON   OBJECT    MESSAGE\n" +
+		"2024-03-10T11:00:00Z  ctx1     Normal  Started  pod/db-0  Started container\n" +
+		"2024-03-10T11:55:00Z  ctx1     Normal  Pulled   pod/db-0  Pulled image\n" +
+		"<unknown>             ctx1     Normal  Created  pod/db-0  Created container\n"
+	if output != expected {
+		t.Errorf("formatEventTimeline() output =\n%s\nwant\n%s", output, expected)
+	}
+}
+
+func TestFormatEventTimelineRequiresLastSeen(t *testing.T) {
+	results := []contextResult{{context: "ctx1", output: "NAME   STATUS\npod1   Running\n"}}
+	if err := formatEventTimeline(results); err == nil {
+		t.Errorf("formatEventTimeline() expected error for output without LAST SEEN column")
+	}
+}
+
+func TestEventsCommand(t *testing.T) {
+	setGlobal(t, &now, func() time.Time {
+		return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	})
+	setGlobal(t, &timeline, false)
+	writeKubeconfig(t, "prod-eu", "prod-us")
+	var commands []string
+	var mu sync.Mutex
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		commands = append(commands, strings.Join(args[2:], " "))
+		mu.Unlock()
+		if argValue(args, "--context") == "prod-eu" {
+			return "NAMESPACE   LAST SEEN   TYPE      REASON    OBJECT      MESSAGE\napps        2m          Warning   BackOff   pod/web-0   Back-off restarting\n", nil
+		}
+		return "NAMESPACE   LAST SEEN   TYPE     REASON   OBJECT      MESSAGE\napps        5m          Normal   Pulled   pod/web-1   Pulled image\n", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := eventsCmd.RunE(eventsCmd, []string{"-A"}); err != nil {
+			t.Errorf("events error = %v", err)
+		}
+	})
+
+	for _, command := range commands {
+		if command != "get events -A" {
+			t.Errorf("kubectl ran %q, want get events -A", command)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "2024-05-01T11:55:00Z  prod-us") || !strings.HasPrefix(lines[2], "2024-05-01T11:58:00Z  prod-eu") {
+		t.Errorf("events output =\n%s\nwant the prod-us event before the prod-eu one", output)
+	}
+}
+
+func TestEventsCommandType(t *testing.T) {
+	setGlobal(t, &timeline, false)
+	setGlobal(t, &eventType, "")
+	writeKubeconfig(t, "ctx1")
+	var command string
+	fakeKubectl(t, func(args []string) (string, error) {
+		command = strings.Join(args[2:], " ")
+		return "NAMESPACE   LAST SEEN   TYPE      REASON    OBJECT      MESSAGE\n" +
+			"apps        2m          Warning   BackOff   pod/web-0   Back-off restarting\n" +
+			"apps        5m          Normal    Pulled    pod/web-1   Pulled image\n", nil
+	})
+
+	for _, args := range [][]string{{"-A", "--event-type", "Warning"}, {"--event-type=Warning", "-A"}} {
+		output := captureStdout(t, func() {
+			if err := eventsCmd.RunE(eventsCmd, args); err != nil {
+				t.Errorf("events %v error = %v", args, err)
+			}
+		})
+		if command != "get events -A" {
+			t.Errorf("events %v ran kubectl %q, want get events -A", args, command)
+		}
+		if !strings.Contains(output, "BackOff") || strings.Contains(output, "Pulled") {
+			t.Errorf("events %v output =\n%s\nwant only the Warning event", args, output)
+		}
+	}
+
+	if err := eventsCmd.RunE(eventsCmd, []string{"--event-type"}); err == nil {
+		t.Errorf("events --event-type expected error for a missing value")
+	}
+}
@@ -1,61 +1,891 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 type contextResult struct {
 	context string
 	output  string
-	err     error
+	// stderr holds what kubectl printed on stderr, such as deprecation
+	// warnings, when it succeeded. On failure it is part of output.
+	stderr string
+	err    error
+	// original is the kubeconfig name of the context when --context-rewrite
+	// changed the displayed name in context
+	original string
+	// versionSkew notes that kubectl is too far from the server's version,
+	// with --check-version-skew
+	versionSkew string
+	// attempts is the number of times kubectl ran, more than one when
+	// --retries retried transient failures
+	attempts int
+	// throttled notes that a failed attempt's stderr showed throttling
+	throttled bool
 }
 
-func runCommand(subcommand string, extraArgs []string) error {
+// kubectlExec runs kubectl with the given arguments and returns its stdout and stderr.
+// kubectl is killed when ctx is cancelled. It is a variable so tests can
+// substitute a fake kubectl.
+var kubectlExec = func(ctx context.Context, args []string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait on pipes still held open by processes kubectl started,
+	// such as credential plugins, once kubectl itself was killed
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+// Barrier modes for --barrier
+const (
+	barrierNone     = ""
+	barrierDispatch = "dispatch"
+	barrierComplete = "complete"
+)
+
+// mutatingVerbs are kubectl subcommands that change cluster state. They are
+// refused unless every invocation is a dry run, keeping the tool read-only.
+var mutatingVerbs = map[string]bool{
+	"apply":    true,
+	"delete":   true,
+	"patch":    true,
+	"edit":     true,
+	"scale":    true,
+	"create":   true,
+	"replace":  true,
+	"annotate": true,
+	"label":    true,
+}
+
+// checkReadOnly returns an error if subcommand is a mutating verb and the
+// invocation does not carry --dry-run, or is exec without --allow-exec
+func checkReadOnly(subcommand string, extraArgs []string) error {
+	if subcommand == "exec" && !allowExec {
+		return fmt.Errorf("refusing to run commands in pods of multiple contexts without --allow-exec")
+	}
+	if !mutatingVerbs[subcommand] {
+		return nil
+	}
+	if hasDryRun(kubectlArgs) || hasDryRun(extraArgs) {
+		return nil
+	}
+	return fmt.Errorf("refusing to run mutating command %q against multiple contexts without --dry-run", subcommand)
+}
+
+// hasDryRun reports whether args contain an enabled --dry-run flag
+func hasDryRun(args []string) bool {
+	for _, arg := range args {
+		if arg == "--dry-run" {
+			return true
+		}
+		if value, ok := strings.CutPrefix(arg, "--dry-run="); ok {
+			return value != "none" && value != "false"
+		}
+	}
+	return false
+}
+
+func runCommand(subcommand string, extraArgs []string) (err error) {
+	if err := checkRunFlags(subcommand, extraArgs); err != nil {
+		return err
+	}
+	closeLog, err := setupLogging()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	if subcommand == "logs" {
+		extraArgs = applyLogDefaults(extraArgs)
+	}
+	if detectOutputFormat(extraArgs) == formatPrometheus && subcommand != "version" {
+		return fmt.Errorf("-o prometheus is only supported by the version command")
+	}
+	if detectOutputFormat(extraArgs) == formatNDJSONErrors && rerunFailed > 0 {
+		return fmt.Errorf("--rerun-failed cannot be used with -o ndjson-with-errors, which streams each context once")
+	}
+	if detectOutputFormat(extraArgs) == formatNDJSONErrors && hashResults {
+		return fmt.Errorf("--result-hash cannot be used with -o ndjson-with-errors, which streams each context")
+	}
+	if streamOutput && rerunFailed > 0 {
+		return fmt.Errorf("--rerun-failed cannot be used with --stream, which prints each context once")
+	}
+	if topTotals && subcommand != "top" {
+		return fmt.Errorf("--totals is only supported by the top command")
+	}
+	if fleetCapacity && (subcommand != "top" || !isTopNodes(extraArgs)) {
+		return fmt.Errorf("--fleet-capacity is only supported by top nodes")
+	}
+	if resourceSummary && subcommand != "get" {
+		return fmt.Errorf("--resource-summary is only supported by the get command")
+	}
+	if summarize && subcommand != "get" {
+		return fmt.Errorf("--summarize is only supported by the get command")
+	}
+	if _, _, err := splitContextColumns(extraArgs); err != nil {
+		return err
+	}
+	if _, err := parseWhere(whereExpr); err != nil {
+		return err
+	}
+	if _, err := parseContextRewrite(contextRewriteExpr); err != nil {
+		return err
+	}
+	if format := detectOutputFormat(extraArgs); whereExpr != "" && format != formatJSON && format != formatYAML && format != formatNDJSONErrors {
+		return fmt.Errorf("--where requires -o json, yaml or ndjson-with-errors")
+	}
+
+	if inputFile != "" && captureRaw != "" {
+		return fmt.Errorf("--input-file and --capture-raw cannot be used together")
+	}
+
+	contexts, restoreExec, err := loadContexts()
+	if err != nil {
+		return err
+	}
+	defer restoreExec()
+
+	if explainPlan {
+		return printPlan(buildPlan(contexts, subcommand, extraArgs), detectOutputFormat(extraArgs))
+	}
+	if captureRaw != "" {
+		finishCapture := startCapture(contexts)
+		defer func() {
+			if captureErr := finishCapture(); err == nil {
+				err = captureErr
+			}
+		}()
+	}
+	if watchInterval > 0 {
+		return watchCommand(contexts, subcommand, extraArgs)
+	}
+	return runIteration(contexts, subcommand, extraArgs)
+}
+
+// checkRunFlags validates the flags every run shares
+func checkRunFlags(subcommand string, extraArgs []string) error {
+	if barrier != barrierNone && barrier != barrierDispatch && barrier != barrierComplete {
+		return fmt.Errorf("invalid --barrier %q: must be %s or %s", barrier, barrierDispatch, barrierComplete)
+	}
+	if onError != onErrorContinue && onError != onErrorStop && onError != onErrorPrompt {
+		return fmt.Errorf("invalid --on-error %q: must be %s, %s or %s", onError, onErrorContinue, onErrorStop, onErrorPrompt)
+	}
+	if onError != onErrorContinue && rerunFailed > 0 {
+		return fmt.Errorf("--rerun-failed can only be used with --on-error=%s", onErrorContinue)
+	}
+	if colorByContext != colorAuto && colorByContext != colorAlways && colorByContext != colorNever {
+		return fmt.Errorf("invalid --color-by-context %q: must be %s, %s or %s", colorByContext, colorAuto, colorAlways, colorNever)
+	}
+	if err := checkReadOnly(subcommand, extraArgs); err != nil {
+		return err
+	}
+	// The native backend is given the arguments as rewritten for kubectl
+	nativeArgs, _, _ := rewriteOutputArgs(subcommand, extraArgs)
+	if err := checkNativeBackend(subcommand, nativeArgs); err != nil {
+		return err
+	}
+	if retries < 0 {
+		return fmt.Errorf("invalid --retries %d: must not be negative", retries)
+	}
+	if _, _, err := parseFailThreshold(failThreshold); err != nil {
+		return err
+	}
+	if _, err := parsePriorities(priorityContexts); err != nil {
+		return err
+	}
+	return nil
+}
+
+// setupRun does for commands that print their own output what runCommand
+// does before running: it checks the shared flags, sets up logging and the
+// backend, and returns the contexts to run against. finish must be called at
+// the end.
+func setupRun(subcommand string, extraArgs []string) (contexts []string, finish func(), err error) {
+	if err := checkRunFlags(subcommand, extraArgs); err != nil {
+		return nil, nil, err
+	}
+	if _, err := parseContextRewrite(contextRewriteExpr); err != nil {
+		return nil, nil, err
+	}
+	if captureRaw != "" {
+		return nil, nil, fmt.Errorf("--capture-raw is not supported by %s", subcommand)
+	}
+	closeLog, err := setupLogging()
+	if err != nil {
+		return nil, nil, err
+	}
+	contexts, restoreExec, err := loadContexts()
+	if err != nil {
+		closeLog()
+		return nil, nil, err
+	}
+	return contexts, func() {
+		restoreExec()
+		closeLog()
+	}, nil
+}
+
+// checkFailures is checkFailThreshold for commands whose output is incomplete
+// without every context, such as compare and logs -f: without
+// --fail-threshold any failed context fails the run
+func checkFailures(results []contextResult) error {
+	if failThreshold != "" {
+		return checkFailThreshold(results)
+	}
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d contexts failed", failed, len(results))
+	}
+	return nil
+}
+
+// loadContexts returns the contexts of the --input-file capture, whose output
+// is then replayed, or else the resolved contexts, run with --backend. The
+// returned function restores the kubectl runner either one replaced.
+func loadContexts() ([]string, func(), error) {
+	previous := kubectlExec
+	restore := func() { kubectlExec = previous }
+
+	var contexts []string
+	var err error
+	if inputFile != "" {
+		contexts, err = replayCapture(inputFile)
+	} else {
+		if backend == backendNative {
+			kubectlExec = nativeExec
+		}
+		contexts, err = resolveContexts()
+	}
+	if err != nil {
+		restore()
+		return nil, nil, err
+	}
+	return contexts, restore, nil
+}
+
+// resolveContexts returns the contexts to run against: those selected from
+// the kubeconfig, in order, after --start-after/--limit and --require-namespace
+func resolveContexts() ([]string, error) {
 	contexts, err := getContexts()
 	if err != nil {
-		return fmt.Errorf("failed to get contexts: %w", err)
+		runLog.Error("failed to get contexts", "error", err.Error())
+		return nil, fmt.Errorf("failed to get contexts: %w", err)
 	}
 
 	if len(contexts) == 0 {
-		return fmt.Errorf("no contexts found in kubeconfig")
+		return nil, fmt.Errorf("no contexts found in kubeconfig")
+	}
+	if requireCurrentContext && len(contexts) > 1 && !contextsNarrowed() {
+		return nil, fmt.Errorf("--require-current-context: refusing to run against all %d contexts; select them with --context, --group, --filter, --prefix, --suffix, --cluster-filter, --user-filter or %s", len(contexts), kubeContextEnv)
+	}
+	if expectContextsFile != "" {
+		if err := checkExpectedContexts(contexts, expectContextsFile); err != nil {
+			return nil, err
+		}
+	}
+
+	contexts, err = sortContexts(contexts, sortOrder)
+	if err != nil {
+		return nil, err
+	}
+	if interactive {
+		if contexts, err = pickContexts(contexts); err != nil {
+			return nil, err
+		}
 	}
 
+	groupRegex, err := compileContextGroup()
+	if err != nil {
+		return nil, err
+	}
+	if groupRegex != nil {
+		contexts = groupContexts(groupRegex, contexts)
+	}
+
+	if startAfter != "" || contextLimit > 0 {
+		contexts = resumeContexts(contexts, startAfter, contextLimit)
+		if len(contexts) == 0 {
+			return nil, fmt.Errorf("no contexts left after %q", startAfter)
+		}
+	}
+
+	if requiredNamespace != "" {
+		contexts, err = filterByNamespace(contexts, requiredNamespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return contexts, nil
+}
+
+// runIteration runs the command against the contexts and prints the results
+func runIteration(contexts []string, subcommand string, extraArgs []string) error {
+	start := time.Now()
+	runLog.Info("run started", "subcommand", subcommand, "contexts", len(contexts))
+	spentRetries.reset()
+	kubectlExtraArgs, outputFormat, contextColumns := rewriteOutputArgs(subcommand, extraArgs)
+	var onResult func(contextResult)
+	if outputFormat == formatNDJSONErrors {
+		// Records are streamed as each context completes instead of formatted at the end
+		onResult = printNDJSONResult
+	}
+
+	var skew map[string]string
+	if checkVersionSkew {
+		skew = versionSkewNotes(contexts)
+	}
+	rewrite, _ := parseContextRewrite(contextRewriteExpr) // validated by runCommand
+	if subcommand == "top" && fleetCapacity {
+		results, err := printFleetCapacity(contexts, kubectlExtraArgs, rewrite)
+		if err != nil {
+			return err
+		}
+		return checkFailThreshold(results)
+	}
+	var stream *tableStream
+	if streamOutput {
+		if !streamable(outputFormat, subcommand) {
+			notef("--stream only applies to plain table output; printing when all contexts finish")
+		} else {
+			var err error
+			if stream, err = newTableStream(contexts, rewrite); err != nil {
+				return err
+			}
+			onResult = stream.print
+		}
+	}
+	if onResult != nil {
+		stream := onResult
+		onResult = func(result contextResult) { stream(rewrite.result(result)) }
+	}
+
+	results := runContextsWithEmitter(contexts, subcommand, kubectlExtraArgs, onResult)
+	results = rerunFailedContexts(results, subcommand, kubectlExtraArgs)
+	for i := range results {
+		results[i].versionSkew = skew[results[i].context]
+		results[i] = rewrite.result(results[i])
+	}
+	if outputDir != "" {
+		// Before any of the output is changed, with the format kubectl printed
+		if err := writeOutputDir(outputDir, results, detectOutputFormat(kubectlExtraArgs)); err != nil {
+			return err
+		}
+	}
+	if warnSelectors {
+		defer warnUnsupportedSelectors(results)
+	}
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	runLog.Info("run finished", "contexts", len(results), "failed", failed, "duration_ms", time.Since(start).Milliseconds())
+	if subcommand == "logs" && maxLogLines > 0 {
+		for i := range results {
+			results[i].output = truncateLines(results[i].output, maxLogLines)
+		}
+	}
+
+	if subcommand == "top" && topTotals {
+		for i := range results {
+			if results[i].err != nil {
+				continue
+			}
+			output, err := appendTopTotals(results[i].output)
+			if err != nil {
+				return fmt.Errorf("context %s: %w", results[i].context, err)
+			}
+			results[i].output = output
+		}
+	}
+
+	if contextColumns != nil {
+		results = insertContextColumns(results, contextColumns)
+	}
+
+	if startAfter != "" || contextLimit > 0 {
+		defer notef("Last context processed: %s (resume with --start-after %s)", contexts[len(contexts)-1], contexts[len(contexts)-1])
+	}
+
+	if outputFormat == formatNDJSONErrors {
+		return checkFailThreshold(results)
+	}
+	if stream != nil {
+		if showWarnings {
+			printWarnings(results)
+		}
+		return checkFailThreshold(results)
+	}
+
+	// Format and print results
+	if err := formatOutput(results, outputFormat, subcommand); err != nil {
+		return err
+	}
+	return checkFailThreshold(results)
+}
+
+// rewriteOutputArgs returns the arguments kubectl is given for the user's
+// extraArgs, the format the results are then printed in, and the CONTEXT
+// columns of -o custom-columns, which kubectl cannot print itself
+func rewriteOutputArgs(subcommand string, extraArgs []string) ([]string, outputFormat, []contextColumn) {
+	outputFormat := detectOutputFormat(extraArgs)
+	kubectlExtraArgs := extraArgs
+	if outputFormat == formatPrometheus || outputFormat == formatCSV || outputFormat == formatTSV {
+		// kubectl has no such output, so it prints its default table instead
+		kubectlExtraArgs = removeOutputFlag(extraArgs)
+	}
+	if resourceSummary || summarize || outputFormat == formatNDJSONErrors {
+		// Kinds are counted, and records built, from the items of JSON output
+		kubectlExtraArgs = append(removeOutputFlag(extraArgs), "-o", "json")
+	}
+
+	if subcommand == "get" && outputFormat == formatDefault && hasShowKind(extraArgs) {
+		if showKind {
+			kubectlExtraArgs = append(append([]string{}, extraArgs...), "--show-kind")
+		}
+		outputFormat = formatKinds
+	}
+	var contextColumns []contextColumn
+	if outputFormat == formatCustomColumns {
+		// Invalid columns are reported by runCommand
+		if args, columns, err := splitContextColumns(extraArgs); err == nil {
+			kubectlExtraArgs, contextColumns = args, columns
+		}
+		if contextColumns != nil {
+			outputFormat = formatContextColumns
+		}
+	}
+	return kubectlExtraArgs, outputFormat, contextColumns
+}
+
+// removeOutputFlag returns args without the -o/--output flag and its value,
+// whether given as -o VALUE, -o=VALUE, -oVALUE or --output=VALUE
+func removeOutputFlag(args []string) []string {
+	var kept []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "-o" || args[i] == "--output" {
+			i++
+			continue
+		}
+		if strings.HasPrefix(args[i], "-o") || strings.HasPrefix(args[i], "--output=") {
+			continue
+		}
+		kept = append(kept, args[i])
+	}
+	return kept
+}
+
+// parseFailThreshold parses --fail-threshold, either a percentage of
+// contexts such as "10%" or a number of contexts such as "3"
+func parseFailThreshold(value string) (limit float64, percent bool, err error) {
+	if value == "" {
+		return 0, false, nil
+	}
+	number := strings.TrimSuffix(value, "%")
+	percent = number != value
+	limit, err = strconv.ParseFloat(number, 64)
+	if err != nil || limit < 0 || (percent && limit > 100) {
+		return 0, false, fmt.Errorf("invalid --fail-threshold %q: must be a count such as 3 or a percentage such as 10%%", value)
+	}
+	return limit, percent, nil
+}
+
+// checkFailThreshold logs the failure ratio and returns an error when more
+// contexts failed than --fail-threshold allows
+func checkFailThreshold(results []contextResult) error {
+	if failThreshold == "" || len(results) == 0 {
+		return nil
+	}
+	limit, percent, _ := parseFailThreshold(failThreshold) // validated by runCommand
+
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	ratio := float64(failed) / float64(len(results)) * 100
+	notef("%d of %d contexts failed (%.1f%%, threshold %s)", failed, len(results), ratio, failThreshold)
+
+	exceeded := float64(failed) > limit
+	if percent {
+		exceeded = ratio > limit
+	}
+	if exceeded {
+		return fmt.Errorf("%d of %d contexts failed, more than --fail-threshold %s", failed, len(results), failThreshold)
+	}
+	return nil
+}
+
+// runContexts runs the kubectl command against each context in parallel,
+// bounded by --batch-size, and returns the results in context order
+func runContexts(contexts []string, subcommand string, extraArgs []string) []contextResult {
+	return runContextsWithEmitter(contexts, subcommand, extraArgs, nil)
+}
+
+// runContextsWithEmitter is runContexts with an onResult callback that is
+// called, one at a time, as each context completes. Contexts are dispatched to
+// workers in order, and fewer of them run at once while kubectl reports
+// throttling. Contexts that have not started when --on-error stops the run
+// are reported as skipped. With --barrier=dispatch results are held back until every
+// context has been dispatched, and with --barrier=complete until all finished.
+func runContextsWithEmitter(contexts []string, subcommand string, extraArgs []string, onResult func(contextResult)) []contextResult {
+	return runContextsWithPolicy(contexts, subcommand, sameArgs(extraArgs), onResult, newErrorPolicy())
+}
+
+// sameArgs returns an argument builder that gives every context extraArgs
+func sameArgs(extraArgs []string) func(context string) []string {
+	return func(string) []string {
+		return extraArgs
+	}
+}
+
+// runContextsWithPolicy is runContextsWithEmitter applying policy instead of
+// --on-error, with the arguments of each context built by argsFor
+func runContextsWithPolicy(contexts []string, subcommand string, argsFor func(context string) []string, onResult func(contextResult), policy *errorPolicy) []contextResult {
+	progress := startSpinner(len(contexts))
+	defer progress.stop()
+	if batchDeadline > 0 {
+		return runBatchesWithDeadline(contexts, subcommand, argsFor, onResult, progress, policy)
+	}
 	results := make([]contextResult, len(contexts))
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, batchSize)
+	queues := clusterQueues(contexts, dispatchOrder(contexts))
+	jobs := make(chan []int, len(queues))
+	for _, queue := range queues {
+		jobs <- queue
+	}
+	close(jobs)
+
+	workers := batchSize
+	if workers < 1 {
+		workers = 1
+	}
 
-	for i, ctx := range contexts {
+	limiter := newConcurrencyLimiter(workers)
+
+	var mu sync.Mutex
+	dispatched := 0
+	ready := barrier == barrierNone
+	var held []contextResult
+	emit := func(result contextResult) {
+		if onResult == nil {
+			return
+		}
+		if !ready {
+			held = append(held, result)
+			return
+		}
+		onResult(result)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(queues); w++ {
 		wg.Add(1)
-		go func(index int, context string) {
+		go func() {
 			defer wg.Done()
-			semaphore <- struct{}{}        // Acquire semaphore
-			defer func() { <-semaphore }() // Release semaphore
-
-			output, err := runKubectlCommand(context, subcommand, extraArgs)
-			results[index] = contextResult{
-				context: context,
-				output:  output,
-				err:     err,
+			for queue := range jobs {
+				for _, index := range queue {
+					mu.Lock()
+					dispatched++
+					if barrier == barrierDispatch && dispatched == len(contexts) {
+						ready = true
+						for _, result := range held {
+							emit(result)
+						}
+						held = nil
+					}
+					mu.Unlock()
+
+					var result contextResult
+					if err := policy.skip(); err != nil {
+						result = contextResult{context: contexts[index], err: err}
+					} else {
+						limiter.acquire()
+						progress.contextStarted()
+						result = runContext(context.Background(), contexts[index], subcommand, argsFor(contexts[index]), nil)
+						progress.contextFinished()
+						limiter.release(result.context, result.throttled)
+						policy.failed(result)
+					}
+					results[index] = result
+
+					mu.Lock()
+					emit(result)
+					mu.Unlock()
+				}
 			}
-		}(i, ctx)
+		}()
 	}
 
 	wg.Wait()
 
-	// Determine output format
-	outputFormat := detectOutputFormat(extraArgs)
+	if onResult != nil && !ready {
+		for _, result := range results {
+			onResult(result)
+		}
+	}
+	return results
+}
 
-	// Format and print results
-	return formatOutput(results, outputFormat, subcommand)
+// runContext runs kubectl against a single context, holding lock, if any,
+// while it runs. kubectl is killed when ctx is cancelled.
+func runContext(ctx context.Context, context, subcommand string, extraArgs []string, lock *sync.Mutex) contextResult {
+	if lock != nil {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+	runLog.Info("context started", "context", context)
+	started := time.Now()
+	result := runKubectlWithRetries(ctx, context, subcommand, extraArgs)
+	if result.err != nil {
+		runLog.Error("context failed", "context", context, "duration_ms", time.Since(started).Milliseconds(), "attempts", result.attempts, "error", result.err.Error())
+	} else {
+		runLog.Info("context finished", "context", context, "duration_ms", time.Since(started).Milliseconds(), "attempts", result.attempts)
+	}
+	return result
 }
 
-func runKubectlCommand(context, subcommand string, extraArgs []string) (string, error) {
-	args := []string{"--context", context, subcommand}
-	args = append(args, extraArgs...)
+// parsePriorities parses the --priority CONTEXT[=WEIGHT] values. A context
+// without a weight gets weight 1.
+func parsePriorities(values []string) (map[string]int, error) {
+	priorities := make(map[string]int, len(values))
+	for _, value := range values {
+		name, weight, hasWeight := strings.Cut(value, "=")
+		name = strings.TrimSpace(name)
+		priorities[name] = 1
+		if !hasWeight {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(weight))
+		if err != nil || name == "" || n < 1 {
+			return nil, fmt.Errorf("invalid --priority %q: must be CONTEXT or CONTEXT=WEIGHT with a WEIGHT of at least 1", value)
+		}
+		priorities[name] = n
+	}
+	return priorities, nil
+}
+
+// dispatchOrder returns the indexes of contexts in the order they are
+// handed to workers: by descending --priority weight, otherwise in order
+func dispatchOrder(contexts []string) []int {
+	priorities, _ := parsePriorities(priorityContexts) // validated by runCommand
+	order := make([]int, len(contexts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return priorities[contexts[order[a]]] > priorities[contexts[order[b]]]
+	})
+	return order
+}
+
+// clusterQueues splits the contexts, given by index in dispatch order, into
+// the queues a worker runs one after the other. With --serialize-per-cluster
+// there is a queue per cluster, so contexts of the same cluster never run at
+// once and no worker waits for another one to finish a cluster; otherwise
+// every context is a queue of its own.
+func clusterQueues(contexts []string, order []int) [][]int {
+	var clusters map[string]string
+	if serializePerCluster {
+		var err error
+		if clusters, err = getContextClusters(); err != nil {
+			notef("Not serializing per cluster: %v", err)
+		}
+	}
+
+	var queues [][]int
+	byCluster := make(map[string]int)
+	for _, index := range order {
+		cluster := clusters[contexts[index]]
+		if cluster == "" {
+			queues = append(queues, []int{index})
+			continue
+		}
+		if q, ok := byCluster[cluster]; ok {
+			queues[q] = append(queues[q], index)
+			continue
+		}
+		byCluster[cluster] = len(queues)
+		queues = append(queues, []int{index})
+	}
+	return queues
+}
+
+// clusterLocks returns a mutex per context that is shared by all contexts
+// pointing at the same cluster, so --serialize-per-cluster runs at most one
+// of them at a time in a --batch-deadline batch, where every context has a
+// goroutine of its own. It returns nil when the flag is not set.
+func clusterLocks(contexts []string) map[string]*sync.Mutex {
+	if !serializePerCluster {
+		return nil
+	}
+	clusters, err := getContextClusters()
+	if err != nil {
+		notef("Not serializing per cluster: %v", err)
+		return nil
+	}
+
+	byCluster := make(map[string]*sync.Mutex)
+	locks := make(map[string]*sync.Mutex, len(contexts))
+	for _, context := range contexts {
+		cluster := clusters[context]
+		if cluster == "" {
+			continue
+		}
+		if byCluster[cluster] == nil {
+			byCluster[cluster] = &sync.Mutex{}
+		}
+		locks[context] = byCluster[cluster]
+	}
+	return locks
+}
+
+// rerunFailedContexts reruns only the failed contexts, up to --rerun-failed
+// passes, replacing their results in place. The reruns are taken from
+// --retry-budget, which --retries attempts also use up.
+func rerunFailedContexts(results []contextResult, subcommand string, extraArgs []string) []contextResult {
+	for pass := 0; pass < rerunFailed; pass++ {
+		var failedIdx []int
+		var failed []string
+		for i, result := range results {
+			if result.err != nil {
+				failedIdx = append(failedIdx, i)
+				failed = append(failed, result.context)
+			}
+		}
+		if len(failed) == 0 {
+			break
+		}
+
+		granted := spentRetries.take(len(failed))
+		exhausted := granted < len(failed)
+		if exhausted {
+			notef("Retry budget of %d exhausted: %d failed contexts not rerun", retryBudget, len(failed)-granted)
+			failedIdx, failed = failedIdx[:granted], failed[:granted]
+		}
+		if len(failed) == 0 {
+			break
+		}
 
-	cmd := exec.Command("kubectl", args...)
-	output, err := cmd.CombinedOutput()
-	return string(output), err
+		runLog.Info("rerunning failed contexts", "pass", pass+1, "contexts", failed)
+		for i, result := range runContexts(failed, subcommand, extraArgs) {
+			results[failedIdx[i]] = result
+		}
+		if exhausted {
+			break
+		}
+	}
+	return results
+}
+
+// filterByNamespace drops the contexts in which the namespace is not found.
+// The check continues past failures without prompting, whatever --on-error
+// says. Contexts where it failed for another reason, such as an unreachable
+// cluster or no permission to get namespaces, are kept so the run reports
+// their actual error.
+func filterByNamespace(contexts []string, namespace string) ([]string, error) {
+	var remaining []string
+	preflight := &errorPolicy{policy: onErrorContinue}
+	for _, result := range runContextsWithPolicy(contexts, "get", sameArgs([]string{"namespace", namespace}), nil, preflight) {
+		if result.err != nil {
+			if code, _ := parseKubectlError(result.output, result.err); code == "NotFound" {
+				notef("Skipping context %s: namespace %q not found", result.context, namespace)
+				continue
+			}
+			runLog.Error("namespace check failed", "context", result.context, "namespace", namespace, "error", result.err.Error())
+		}
+		remaining = append(remaining, result.context)
+	}
+
+	if len(remaining) == 0 {
+		return nil, fmt.Errorf("no contexts have namespace %q", namespace)
+	}
+	return remaining, nil
+}
+
+// defaultLogTail is the --tail injected into logs commands that don't set one,
+// so a casual fleet-wide logs command doesn't fetch every line of every pod
+const defaultLogTail = 100
+
+// applyLogDefaults adds --tail=defaultLogTail unless args already set --tail
+// or limit the logs by time with --since or --since-time. Users can opt out
+// with --tail=-1.
+func applyLogDefaults(args []string) []string {
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		if name == "--tail" || name == "--since" || name == "--since-time" {
+			return args
+		}
+	}
+	return append(append([]string(nil), args...), fmt.Sprintf("--tail=%d", defaultLogTail))
+}
+
+// truncateLines keeps the first max lines of output, marking any cut
+func truncateLines(output string, max int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) <= max {
+		return output
+	}
+	return strings.Join(lines[:max], "\n") + "\n...[truncated]...\n"
+}
+
+// notef prints an informational message to stderr, or records it in the
+// structured log with --log-format=json
+func notef(format string, args ...interface{}) {
+	if logFormat == logFormatJSON {
+		runLog.Info(fmt.Sprintf(format, args...))
+		return
+	}
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// runKubectlCommand runs kubectl against a context and returns its output and
+// stderr. When kubectl fails, stderr is appended to the output so the error
+// message is reported with it.
+func runKubectlCommand(ctx context.Context, context, subcommand string, extraArgs []string) (string, string, error) {
+	stdout, stderr, err := kubectlExec(ctx, buildKubectlArgs(context, subcommand, extraArgs))
+	if err != nil {
+		return stdout + stderr, "", err
+	}
+	return stdout, stderr, nil
+}
+
+// buildKubectlArgs assembles the kubectl argv for a single context. Global
+// --kubectl-arg values go before the subcommand so they never mix with the
+// passthrough arguments. Contexts with a --context-kubeconfig get --kubeconfig,
+// and so do the others when --kubeconfig is set.
+func buildKubectlArgs(context, subcommand string, extraArgs []string) []string {
+	var args []string
+	if path := contextKubeconfig(context); path != "" {
+		args = append(args, "--kubeconfig", path)
+	} else if kubeconfigFlag != "" {
+		args = append(args, "--kubeconfig", kubeconfigFlag)
+	}
+	args = append(args, "--context", context)
+	args = append(args, kubectlArgs...)
+	args = append(args, subcommand)
+	if subcommand == "kustomize" {
+		extraArgs = expandContextPlaceholder(extraArgs, context)
+	}
+	args = append(args, extraArgs...)
+	return args
 }
@@ -0,0 +1,1011 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// setGlobal overrides a package-level flag variable for the duration of a test.
+func setGlobal[T any](t *testing.T, p *T, v T) {
+	t.Helper()
+	original := *p
+	*p = v
+	t.Cleanup(func() { *p = original })
+}
+
+// writeKubeconfig writes a kubeconfig containing the given contexts and points KUBECONFIG at it.
+func writeKubeconfig(t *testing.T, contexts ...string) string {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("apiVersion: v1\nkind: Config\ncontexts:\n")
+	for _, ctx := range contexts {
+		fmt.Fprintf(&b, "- name: %s\n  context:\n    cluster: %s\n    user: %s\n", ctx, ctx, ctx)
+	}
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	t.Setenv("KUBECONFIG", path)
+	return path
+}
+
+// fakeKubectl replaces the kubectl invocation with fn, whose output is
+// returned as stdout, for the duration of a test.
+func fakeKubectl(t *testing.T, fn func(args []string) (string, error)) {
+	t.Helper()
+	setGlobal(t, &kubectlExec, func(_ context.Context, args []string) (string, string, error) {
+		output, err := fn(args)
+		return output, "", err
+	})
+}
+
+// captureStdout returns everything written to os.Stdout while fn runs.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureFile(t, &os.Stdout, fn)
+}
+
+// captureStderr returns everything written to os.Stderr while fn runs.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureFile(t, &os.Stderr, fn)
+}
+
+// captureFile redirects *f to a pipe while fn runs and returns what was written.
+func captureFile(t *testing.T, f **os.File, fn func()) string {
+	t.Helper()
+	var captured bytes.Buffer
+	original := *f
+	r, w, _ := os.Pipe()
+	*f = w
+	defer func() {
+		*f = original
+	}()
+
+	done := make(chan bool)
+	go func() {
+		io.Copy(&captured, r)
+		done <- true
+	}()
+
+	fn()
+	w.Close()
+	<-done
+	return captured.String()
+}
+
+// argValue returns the value following name in args, or "" if absent.
+func argValue(args []string, name string) string {
+	for i, arg := range args {
+		if arg == name && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func TestBuildKubectlArgs(t *testing.T) {
+	setGlobal(t, &kubectlArgs, []string{"--request-timeout=5s"})
+
+	got := buildKubectlArgs("ctx1", "get", []string{"pods", "-n", "default"})
+	want := []string{"--context", "ctx1", "--request-timeout=5s", "get", "pods", "-n", "default"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("buildKubectlArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestMaxParallelFlag(t *testing.T) {
+	setGlobal(t, &batchSize, batchSize)
+	if err := rootCmd.PersistentFlags().Set("max-parallel", "3"); err != nil {
+		t.Fatalf("Set(max-parallel) error = %v", err)
+	}
+	if batchSize != 3 {
+		t.Errorf("--max-parallel 3 set the batch size to %d, want 3", batchSize)
+	}
+}
+
+func TestRunCommandKubeconfigFlag(t *testing.T) {
+	path := writeKubeconfig(t, "flag-ctx1", "flag-ctx2")
+	// The flag wins over KUBECONFIG
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	setGlobal(t, &kubeconfigFlag, path)
+
+	var mu sync.Mutex
+	var argvs [][]string
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		argvs = append(argvs, args)
+		return "NAME\npod1", nil
+	})
+	captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	if len(argvs) != 2 {
+		t.Fatalf("kubectl invoked %d times, want once for each context of --kubeconfig", len(argvs))
+	}
+	for _, args := range argvs {
+		if argValue(args, "--kubeconfig") != path {
+			t.Errorf("kubectl args = %v, want --kubeconfig %s", args, path)
+		}
+	}
+}
+
+func TestRunCommandKubectlArgs(t *testing.T) {
+	contexts := []string{"ctx1", "ctx2", "ctx3"}
+	writeKubeconfig(t, contexts...)
+	globalArgs := []string{"--request-timeout=5s", "-v=6"}
+	setGlobal(t, &kubectlArgs, globalArgs)
+
+	var mu sync.Mutex
+	argvs := make(map[string][]string)
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		argvs[argValue(args, "--context")] = args
+		return "NAME\npod1", nil
+	})
+
+	captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	if len(argvs) != len(contexts) {
+		t.Fatalf("kubectl invoked for %d contexts, want %d", len(argvs), len(contexts))
+	}
+	for _, ctx := range contexts {
+		args := argvs[ctx]
+		for _, want := range globalArgs {
+			count := 0
+			for _, arg := range args {
+				if arg == want {
+					count++
+				}
+			}
+			if count != 1 {
+				t.Errorf("context %s argv %v contains %q %d times, want 1", ctx, args, want, count)
+			}
+		}
+	}
+}
+
+func TestCheckReadOnly(t *testing.T) {
+	tests := []struct {
+		name       string
+		subcommand string
+		args       []string
+		wantError  bool
+	}{
+		{name: "read-only verb", subcommand: "get", args: []string{"pods"}},
+		{name: "apply without dry-run", subcommand: "apply", args: []string{"-f", "pod.yaml"}, wantError: true},
+		{name: "delete without dry-run", subcommand: "delete", args: []string{"pod", "pod1"}, wantError: true},
+		{name: "apply with server dry-run", subcommand: "apply", args: []string{"-f", "pod.yaml", "--dry-run=server"}},
+		{name: "label with client dry-run", subcommand: "label", args: []string{"pod", "pod1", "a=b", "--dry-run=client"}},
+		{name: "bare dry-run flag", subcommand: "scale", args: []string{"deploy/api", "--replicas=2", "--dry-run"}},
+		{name: "dry-run none", subcommand: "patch", args: []string{"pod", "pod1", "--dry-run=none"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReadOnly(tt.subcommand, tt.args)
+			if tt.wantError && err == nil {
+				t.Errorf("checkReadOnly(%q, %v) expected error but got none", tt.subcommand, tt.args)
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("checkReadOnly(%q, %v) unexpected error = %v", tt.subcommand, tt.args, err)
+			}
+		})
+	}
+}
+
+func TestRunCommandBlocksMutatingVerbs(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	var called atomic.Bool
+	fakeKubectl(t, func(args []string) (string, error) {
+		called.Store(true)
+		return "", nil
+	})
+
+	if err := runCommand("delete", []string{"pod", "pod1"}); err == nil {
+		t.Errorf("runCommand() expected error for mutating verb without --dry-run")
+	}
+	if called.Load() {
+		t.Errorf("kubectl should not be invoked for a blocked command")
+	}
+
+	setGlobal(t, &kubectlArgs, []string{"--dry-run=server"})
+	captureStdout(t, func() {
+		if err := runCommand("delete", []string{"pod", "pod1"}); err != nil {
+			t.Errorf("runCommand() unexpected error = %v", err)
+		}
+	})
+	if !called.Load() {
+		t.Errorf("kubectl should be invoked for a dry-run command")
+	}
+}
+
+func TestRunCommandRerunFailed(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2", "ctx3")
+	setGlobal(t, &rerunFailed, 2)
+
+	// ctx2 fails on its first two attempts, ctx3 always fails
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		ctx := argValue(args, "--context")
+		attempts[ctx]++
+		if ctx == "ctx3" || (ctx == "ctx2" && attempts[ctx] <= 2) {
+			return "connection refused", fmt.Errorf("exit status 1")
+		}
+		return "NAME\npod-" + ctx, nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	expectedAttempts := map[string]int{"ctx1": 1, "ctx2": 3, "ctx3": 3}
+	for ctx, want := range expectedAttempts {
+		if attempts[ctx] != want {
+			t.Errorf("context %s attempted %d times, want %d", ctx, attempts[ctx], want)
+		}
+	}
+	if !strings.Contains(output, "pod-ctx1") || !strings.Contains(output, "pod-ctx2") {
+		t.Errorf("output should contain results of ctx1 and the rerun ctx2, got %q", output)
+	}
+}
+
+func TestRunCommandRetryBudget(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2", "ctx3", "ctx4")
+	setGlobal(t, &rerunFailed, 3)
+	setGlobal(t, &retryBudget, 5)
+
+	// Every context always fails, so without a budget there would be 12 reruns
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[argValue(args, "--context")]++
+		return "connection refused", fmt.Errorf("exit status 1")
+	})
+
+	var stderr string
+	captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := runCommand("get", []string{"pods"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+	})
+
+	total := 0
+	for _, n := range attempts {
+		total += n
+	}
+	if reruns := total - 4; reruns != 5 {
+		t.Errorf("made %d reruns, want the budget of 5", reruns)
+	}
+	expectedAttempts := map[string]int{"ctx1": 3, "ctx2": 2, "ctx3": 2, "ctx4": 2}
+	for ctx, want := range expectedAttempts {
+		if attempts[ctx] != want {
+			t.Errorf("context %s attempted %d times, want %d", ctx, attempts[ctx], want)
+		}
+	}
+	if strings.Count(stderr, "Retry budget") != 1 || !strings.Contains(stderr, "Retry budget of 5 exhausted: 3 failed contexts not rerun") {
+		t.Errorf("stderr should note the exhausted budget once, got %q", stderr)
+	}
+}
+
+func TestRunCommandRetryBudgetWithRetries(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	setGlobal(t, &retries, 3)
+	setGlobal(t, &retryBackoff, time.Millisecond)
+	setGlobal(t, &rerunFailed, 2)
+	setGlobal(t, &retryBudget, 4)
+
+	// Without the budget, each context would run 4 times per pass for 3 passes
+	var mu sync.Mutex
+	calls := 0
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return "Unable to connect to the server: dial tcp: connection refused", fmt.Errorf("exit status 1")
+	})
+
+	var stderr string
+	captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := runCommand("get", []string{"pods"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+	})
+
+	if calls != 2+4 {
+		t.Errorf("kubectl ran %d times, want 2 plus the budget of 4", calls)
+	}
+	if !strings.Contains(stderr, "Retry budget of 4 exhausted") {
+		t.Errorf("stderr should note the exhausted budget, got %q", stderr)
+	}
+}
+
+func TestRunCommandPassesChunkSizeThrough(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	extraArgs := []string{"pods", "--chunk-size=500", "-o", "json"}
+
+	var mu sync.Mutex
+	var argvs [][]string
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		argvs = append(argvs, args)
+		return `{"items":[]}`, nil
+	})
+
+	captureStdout(t, func() {
+		if err := runCommand("get", extraArgs); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	for _, args := range argvs {
+		got := strings.Join(args[len(args)-len(extraArgs):], " ")
+		if got != strings.Join(extraArgs, " ") {
+			t.Errorf("kubectl argv %v does not end with passthrough args %v", args, extraArgs)
+		}
+	}
+}
+
+func TestRunCommandRequireNamespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		onError string
+	}{
+		{name: "continue", onError: onErrorContinue},
+		{name: "stop does not apply to the check", onError: onErrorStop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeKubeconfig(t, "ctx1", "ctx2", "ctx3", "ctx4")
+			setGlobal(t, &requiredNamespace, "payments")
+			setGlobal(t, &onError, tt.onError)
+			setGlobal(t, &batchSize, 1)
+
+			var mu sync.Mutex
+			var ran []string
+			fakeKubectl(t, func(args []string) (string, error) {
+				ctx := argValue(args, "--context")
+				if argValue(args, "get") == "namespace" {
+					switch ctx {
+					case "ctx1":
+						return `Error from server (NotFound): namespaces "payments" not found`, fmt.Errorf("exit status 1")
+					case "ctx3":
+						return `Error from server (Forbidden): namespaces "payments" is forbidden`, fmt.Errorf("exit status 1")
+					}
+					return "NAME       STATUS   AGE\npayments   Active   10d", nil
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				ran = append(ran, ctx)
+				return "NAME\npod-" + ctx, nil
+			})
+
+			var output string
+			stderr := captureStderr(t, func() {
+				output = captureStdout(t, func() {
+					if err := runCommand("get", []string{"pods", "-n", "payments"}); err != nil {
+						t.Errorf("runCommand() error = %v", err)
+					}
+				})
+			})
+
+			// ctx3 can't get namespaces, which doesn't mean the namespace is missing
+			sort.Strings(ran)
+			if strings.Join(ran, ",") != "ctx2,ctx3,ctx4" {
+				t.Errorf("command ran against %v, want [ctx2 ctx3 ctx4]", ran)
+			}
+			if strings.Contains(output, "ctx1") {
+				t.Errorf("output should not contain the skipped context, got %q", output)
+			}
+			if strings.Count(stderr, "not found") != 1 || !strings.Contains(stderr, "Skipping context ctx1") {
+				t.Errorf("stderr = %q, want only ctx1 skipped", stderr)
+			}
+		})
+	}
+}
+
+func TestRunContextsBarrier(t *testing.T) {
+	contexts := []string{"ctx1", "ctx2", "ctx3"}
+	tests := []struct {
+		name     string
+		barrier  string
+		expected []string
+	}{
+		{
+			name:     "no barrier",
+			barrier:  barrierNone,
+			expected: []string{"start ctx1", "emit ctx1", "start ctx2", "emit ctx2", "start ctx3", "emit ctx3"},
+		},
+		{
+			name:     "dispatch barrier",
+			barrier:  barrierDispatch,
+			expected: []string{"start ctx1", "start ctx2", "emit ctx1", "emit ctx2", "start ctx3", "emit ctx3"},
+		},
+		{
+			name:     "complete barrier",
+			barrier:  barrierComplete,
+			expected: []string{"start ctx1", "start ctx2", "start ctx3", "emit ctx1", "emit ctx2", "emit ctx3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A single worker makes the dispatch order deterministic
+			setGlobal(t, &batchSize, 1)
+			setGlobal(t, &barrier, tt.barrier)
+
+			var events []string
+			fakeKubectl(t, func(args []string) (string, error) {
+				events = append(events, "start "+argValue(args, "--context"))
+				return "", nil
+			})
+
+			runContextsWithEmitter(contexts, "get", []string{"pods"}, func(result contextResult) {
+				events = append(events, "emit "+result.context)
+			})
+
+			if strings.Join(events, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("events = %v, want %v", events, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyLogDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{name: "injects default tail", args: []string{"deploy/api"}, expected: []string{"deploy/api", "--tail=100"}},
+		{name: "keeps explicit tail", args: []string{"deploy/api", "--tail=20"}, expected: []string{"deploy/api", "--tail=20"}},
+		{name: "keeps separate tail value", args: []string{"deploy/api", "--tail", "5"}, expected: []string{"deploy/api", "--tail", "5"}},
+		{name: "opt out", args: []string{"deploy/api", "--tail=-1"}, expected: []string{"deploy/api", "--tail=-1"}},
+		{name: "since", args: []string{"deploy/api", "--since=1h"}, expected: []string{"deploy/api", "--since=1h"}},
+		{name: "separate since value", args: []string{"deploy/api", "--since", "10m"}, expected: []string{"deploy/api", "--since", "10m"}},
+		{name: "since time", args: []string{"deploy/api", "--since-time=2026-10-17T08:00:00Z"}, expected: []string{"deploy/api", "--since-time=2026-10-17T08:00:00Z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyLogDefaults(tt.args)
+			if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("applyLogDefaults(%v) = %v, want %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncateLines(t *testing.T) {
+	output := "line1\nline2\nline3\nline4\n"
+	if got := truncateLines(output, 2); got != "line1\nline2\n...[truncated]...\n" {
+		t.Errorf("truncateLines() = %q", got)
+	}
+	if got := truncateLines(output, 4); got != output {
+		t.Errorf("truncateLines() within limit = %q, want unchanged", got)
+	}
+}
+
+func TestRunContextsSerializePerCluster(t *testing.T) {
+	config := `apiVersion: v1
+kind: Config
+contexts:
+- name: prod-admin
+  context:
+    cluster: prod
+    user: admin
+- name: prod-readonly
+  context:
+    cluster: prod
+    user: readonly
+- name: staging
+  context:
+    cluster: staging
+    user: admin
+`
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	t.Setenv("KUBECONFIG", path)
+	setGlobal(t, &serializePerCluster, true)
+	// With two workers, one must not sit waiting for prod while staging is queued
+	setGlobal(t, &batchSize, 2)
+
+	clusterOf := map[string]string{"prod-admin": "prod", "prod-readonly": "prod", "staging": "staging"}
+	var mu sync.Mutex
+	inFlight := map[string]int{}
+	prodFinished := 0
+	var overlapped, parallel atomic.Bool
+	fakeKubectl(t, func(args []string) (string, error) {
+		cluster := clusterOf[argValue(args, "--context")]
+		mu.Lock()
+		inFlight[cluster]++
+		if inFlight[cluster] > 1 {
+			overlapped.Store(true)
+		}
+		if cluster == "staging" && inFlight["prod"] > 0 && prodFinished == 0 {
+			parallel.Store(true)
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight[cluster]--
+		if cluster == "prod" {
+			prodFinished++
+		}
+		mu.Unlock()
+		return "ok", nil
+	})
+
+	results := runContexts([]string{"prod-admin", "prod-readonly", "staging"}, "get", nil)
+	for _, result := range results {
+		if result.err != nil {
+			t.Errorf("context %s: unexpected error %v", result.context, result.err)
+		}
+	}
+	if overlapped.Load() {
+		t.Errorf("contexts of the same cluster ran concurrently")
+	}
+	if !parallel.Load() {
+		t.Errorf("staging did not run alongside the first prod context")
+	}
+}
+
+func TestCheckFailThreshold(t *testing.T) {
+	// 2 of 10 contexts failed
+	var results []contextResult
+	for i := 0; i < 10; i++ {
+		result := contextResult{context: fmt.Sprintf("ctx%d", i)}
+		if i < 2 {
+			result.err = fmt.Errorf("exit status 1")
+		}
+		results = append(results, result)
+	}
+
+	tests := []struct {
+		threshold string
+		wantErr   bool
+	}{
+		{threshold: "", wantErr: false},
+		{threshold: "20%", wantErr: false},
+		{threshold: "10%", wantErr: true},
+		{threshold: "2", wantErr: false},
+		{threshold: "1", wantErr: true},
+		{threshold: "0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.threshold, func(t *testing.T) {
+			setGlobal(t, &failThreshold, tt.threshold)
+			err := checkFailThreshold(results)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkFailThreshold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFailThresholdInvalid(t *testing.T) {
+	for _, value := range []string{"ten", "-1", "150%", "%"} {
+		if _, _, err := parseFailThreshold(value); err == nil {
+			t.Errorf("parseFailThreshold(%q) expected error", value)
+		}
+	}
+}
+
+func TestRunCommandSortsContexts(t *testing.T) {
+	writeKubeconfig(t, "zeta", "alpha", "mid")
+	fakeKubectl(t, func(args []string) (string, error) {
+		return fmt.Sprintf(`{"items":[{"metadata":{"name":"%s"}}]}`, argValue(args, "--context")), nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods", "-o", "json"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	alpha, mid, zeta := strings.Index(output, `"alpha"`), strings.Index(output, `"mid"`), strings.Index(output, `"zeta"`)
+	if alpha == -1 || !(alpha < mid && mid < zeta) {
+		t.Errorf("runCommand() items not ordered by context name:\n%s", output)
+	}
+}
+
+func TestRunCommandShowWarnings(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	setGlobal(t, &kubectlExec, func(_ context.Context, args []string) (string, string, error) {
+		if argValue(args, "--context") == "ctx1" {
+			if argValue(args, "-o") == "json" {
+				return `{"items":[{"metadata":{"name":"cs1"}}]}`, "Warning: v1 ComponentStatus is deprecated in v1.19+\n", nil
+			}
+			return "NAME   STATUS\ncs1    Healthy\n", "Warning: v1 ComponentStatus is deprecated in v1.19+\n", nil
+		}
+		if argValue(args, "-o") == "json" {
+			return `{"items":[{"metadata":{"name":"cs2"}}]}`, "", nil
+		}
+		return "NAME   STATUS\ncs2    Healthy\n", "", nil
+	})
+
+	t.Run("warnings stay out of the table", func(t *testing.T) {
+		var stdout string
+		stderr := captureStderr(t, func() {
+			stdout = captureStdout(t, func() {
+				if err := runCommand("get", []string{"componentstatuses"}); err != nil {
+					t.Errorf("runCommand() error = %v", err)
+				}
+			})
+		})
+		if strings.Contains(stdout, "Warning") || strings.Contains(stderr, "Warning") {
+			t.Errorf("warnings should not be shown without --show-warnings:\nstdout:\n%s\nstderr:\n%s", stdout, stderr)
+		}
+	})
+
+	t.Run("warnings section", func(t *testing.T) {
+		setGlobal(t, &showWarnings, true)
+		var stdout string
+		stderr := captureStderr(t, func() {
+			stdout = captureStdout(t, func() {
+				if err := runCommand("get", []string{"componentstatuses"}); err != nil {
+					t.Errorf("runCommand() error = %v", err)
+				}
+			})
+		})
+		if !strings.Contains(stdout, "cs1") || strings.Contains(stdout, "Warning") {
+			t.Errorf("stdout should hold only the table:\n%s", stdout)
+		}
+		expected := "CONTEXT  WARNING\nctx1     Warning: v1 ComponentStatus is deprecated in v1.19+\n"
+		if stderr != expected {
+			t.Errorf("stderr =\n%s\nwant\n%s", stderr, expected)
+		}
+	})
+
+	t.Run("warnings annotation", func(t *testing.T) {
+		setGlobal(t, &showWarnings, true)
+		stdout := captureStdout(t, func() {
+			if err := runCommand("get", []string{"componentstatuses", "-o", "json"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+		if strings.Count(stdout, `"multi/warnings": "Warning: v1 ComponentStatus is deprecated in v1.19+"`) != 1 {
+			t.Errorf("only the ctx1 item should carry the multi/warnings annotation:\n%s", stdout)
+		}
+	})
+}
+
+func TestRunKubectlCommandFailureKeepsStderr(t *testing.T) {
+	setGlobal(t, &kubectlExec, func(_ context.Context, args []string) (string, string, error) {
+		return "", "Unable to connect to the server: timeout\n", fmt.Errorf("exit status 1")
+	})
+	output, stderr, err := runKubectlCommand(context.Background(), "ctx1", "get", []string{"pods"})
+	if err == nil || stderr != "" || !strings.Contains(output, "Unable to connect") {
+		t.Errorf("runKubectlCommand() = %q, %q, %v, want the error message in output", output, stderr, err)
+	}
+}
+
+func TestRunCommandRequireCurrentContext(t *testing.T) {
+	writeKubeconfig(t, "dev", "staging", "prod")
+	setGlobal(t, &requireCurrentContext, true)
+
+	var calls atomic.Int32
+	fakeKubectl(t, func(args []string) (string, error) {
+		calls.Add(1)
+		return "NAME\npod1\n", nil
+	})
+
+	t.Run("guarded", func(t *testing.T) {
+		err := runCommand("get", []string{"pods"})
+		if err == nil || !strings.Contains(err.Error(), "refusing to run against all 3 contexts") {
+			t.Errorf("runCommand() error = %v, want refusal", err)
+		}
+		if calls.Load() != 0 {
+			t.Errorf("kubectl should not run when the guard refuses, ran %d times", calls.Load())
+		}
+	})
+
+	t.Run("narrowed by filter", func(t *testing.T) {
+		setGlobal(t, &filterPatterns, []string{"dev|staging"})
+		captureStdout(t, func() {
+			if err := runCommand("get", []string{"pods"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+	})
+
+	t.Run("narrowed by env", func(t *testing.T) {
+		t.Setenv("KUBECTL_MULTI_CONTEXTS", "dev,prod")
+		captureStdout(t, func() {
+			if err := runCommand("get", []string{"pods"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+	})
+}
+
+func TestRunCommandRequireCurrentContextSingleContext(t *testing.T) {
+	writeKubeconfig(t, "only")
+	setGlobal(t, &requireCurrentContext, true)
+	fakeKubectl(t, func(args []string) (string, error) {
+		return "NAME\npod1\n", nil
+	})
+
+	captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+}
+
+func TestRunCommandPriorityDispatchOrder(t *testing.T) {
+	writeKubeconfig(t, "dev", "prod-eu", "prod-us", "staging", "test")
+	setGlobal(t, &batchSize, 1)
+	setGlobal(t, &priorityContexts, []string{"prod-eu", "prod-us=5", "staging=2"})
+
+	var mu sync.Mutex
+	var dispatched []string
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		dispatched = append(dispatched, argValue(args, "--context"))
+		return "NAME\npod-" + argValue(args, "--context") + "\n", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	expected := []string{"prod-us", "staging", "prod-eu", "dev", "test"}
+	if strings.Join(dispatched, ",") != strings.Join(expected, ",") {
+		t.Errorf("dispatch order = %v, want %v", dispatched, expected)
+	}
+	// Output keeps the normal context order
+	if strings.Index(output, "pod-dev") > strings.Index(output, "pod-prod-us") {
+		t.Errorf("output should stay in context order:\n%s", output)
+	}
+}
+
+func TestParsePrioritiesInvalid(t *testing.T) {
+	for _, value := range []string{"prod=high", "prod=0", "prod=-1", "=2"} {
+		if _, err := parsePriorities([]string{value}); err == nil {
+			t.Errorf("parsePriorities(%q) expected error", value)
+		}
+	}
+}
+
+func TestRunCommandContextKubeconfig(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	separate := filepath.Join(t.TempDir(), "airgap")
+	config := "contexts:\n- name: airgap\n  context:\n    cluster: airgap\n- name: ctx2\n  context:\n    cluster: ctx2-direct\n"
+	if err := os.WriteFile(separate, []byte(config), 0o600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	setGlobal(t, &contextKubeconfigs, []string{"airgap=" + separate, "ctx2=" + separate})
+
+	var mu sync.Mutex
+	kubeconfigs := make(map[string]string)
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		kubeconfigs[argValue(args, "--context")] = argValue(args, "--kubeconfig")
+		return "NAME\npod1\n", nil
+	})
+
+	captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	expected := map[string]string{"airgap": separate, "ctx1": "", "ctx2": separate}
+	if !reflect.DeepEqual(kubeconfigs, expected) {
+		t.Errorf("--kubeconfig per context = %v, want %v", kubeconfigs, expected)
+	}
+}
+
+func TestRunCommandContextKubeconfigMissingContext(t *testing.T) {
+	separate := writeKubeconfig(t, "other")
+	writeKubeconfig(t, "ctx1")
+	setGlobal(t, &contextKubeconfigs, []string{"airgap=" + separate})
+
+	err := runCommand("get", []string{"pods"})
+	if err == nil || !strings.Contains(err.Error(), "context airgap not found") {
+		t.Errorf("runCommand() error = %v, want missing context error", err)
+	}
+}
+
+func TestRunCommandExplainPlan(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	setGlobal(t, &explainPlan, true)
+	setGlobal(t, &priorityContexts, []string{"ctx2"})
+	setGlobal(t, &kubectlArgs, []string{"--request-timeout=5s"})
+	fakeKubectl(t, func(args []string) (string, error) {
+		t.Errorf("kubectl ran with --explain-plan: %v", args)
+		return "", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods", "-o", "json"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	var plan executionPlan
+	if err := json.Unmarshal([]byte(output), &plan); err != nil {
+		t.Fatalf("plan is not JSON: %v\n%s", err, output)
+	}
+	if plan.Timeout != "5s" || plan.Concurrency != 2 {
+		t.Errorf("timeout = %q, concurrency = %d, want 5s and 2", plan.Timeout, plan.Concurrency)
+	}
+	var argvs [][]string
+	for _, step := range plan.Contexts {
+		argvs = append(argvs, step.Argv)
+	}
+	expected := [][]string{
+		{"kubectl", "--context", "ctx2", "--request-timeout=5s", "get", "pods", "-o", "json"},
+		{"kubectl", "--context", "ctx1", "--request-timeout=5s", "get", "pods", "-o", "json"},
+	}
+	if !reflect.DeepEqual(argvs, expected) {
+		t.Errorf("plan argv = %v, want %v", argvs, expected)
+	}
+}
+
+func TestBuildPlanRewritesOutputArgs(t *testing.T) {
+	writeKubeconfig(t, "ctx1")
+	tests := []struct {
+		name      string
+		args      []string
+		summarize bool
+		expected  []string
+	}{
+		{name: "csv", args: []string{"pods", "-o", "csv"}, expected: []string{"pods"}},
+		{name: "summarize", args: []string{"pods"}, summarize: true, expected: []string{"pods", "-o", "json"}},
+		{name: "ndjson with errors", args: []string{"pods", "-o", "ndjson-with-errors"}, expected: []string{"pods", "-o", "json"}},
+		{name: "context column", args: []string{"pods", "-o", "custom-columns=CTX:CONTEXT,NAME:.metadata.name"}, expected: []string{"pods", "-o", "custom-columns=NAME:.metadata.name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGlobal(t, &summarize, tt.summarize)
+			plan := buildPlan([]string{"ctx1"}, "get", tt.args)
+			expected := append([]string{"kubectl", "--context", "ctx1", "get"}, tt.expected...)
+			if !reflect.DeepEqual(plan.Contexts[0].Argv, expected) {
+				t.Errorf("plan argv = %v, want %v", plan.Contexts[0].Argv, expected)
+			}
+		})
+	}
+}
+
+func TestRunCommandNDJSONWithErrors(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2", "ctx3")
+	var outputs []string
+	var mu sync.Mutex
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		outputs = append(outputs, argValue(args, "-o"))
+		mu.Unlock()
+		switch argValue(args, "--context") {
+		case "ctx2":
+			return "Error from server (Forbidden): pods is forbidden\n", fmt.Errorf("exit status 1")
+		case "ctx3":
+			return "not json", nil
+		}
+		return `{"items": [{"metadata": {"name": "a"}}, {"metadata": {"name": "b"}}]}`, nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods", "-o", "ndjson-with-errors"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	for _, o := range outputs {
+		if o != "json" {
+			t.Errorf("kubectl ran with -o %q, want json", o)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	sort.Strings(lines)
+	expected := []string{
+		`{"context":"ctx2","error":"pods is forbidden","code":"Forbidden"}`,
+		`{"context":"ctx3","error":"context ctx3: failed to parse JSON: invalid character 'o' in literal null (expecting 'u')","code":"InvalidOutput"}`,
+		`{"metadata":{"annotations":{"multi/context":"ctx1"},"name":"a"}}`,
+		`{"metadata":{"annotations":{"multi/context":"ctx1"},"name":"b"}}`,
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("ndjson lines =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestRunCommandNDJSONWithErrorsRerunFailed(t *testing.T) {
+	writeKubeconfig(t, "ctx1")
+	setGlobal(t, &rerunFailed, 1)
+
+	err := runCommand("get", []string{"pods", "-o", "ndjson-with-errors"})
+	if err == nil || !strings.Contains(err.Error(), "--rerun-failed") {
+		t.Errorf("runCommand() error = %v, want --rerun-failed error", err)
+	}
+}
+
+func TestRunCommandExpectContextsFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline string
+		wantErr  string
+	}{
+		{name: "match", baseline: "# prod fleet\nctx1\nctx2\n"},
+		{name: "match contexts table", baseline: "CURRENT   NAME   CLUSTER   USER   NAMESPACE\n          ctx1   ctx1      ctx1\n*         ctx2   ctx2      ctx2\n"},
+		{name: "added", baseline: "ctx1\n", wantErr: "\n  added: ctx2"},
+		{name: "removed", baseline: "ctx1\nctx2\nctx3\n", wantErr: "\n  removed: ctx3"},
+		{name: "added and removed", baseline: "ctx1\nold\n", wantErr: "--expect-contexts-file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeKubeconfig(t, "ctx1", "ctx2")
+			baseline := filepath.Join(t.TempDir(), "contexts.txt")
+			if err := os.WriteFile(baseline, []byte(tt.baseline), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			setGlobal(t, &expectContextsFile, baseline)
+			ran := false
+			fakeKubectl(t, func(args []string) (string, error) {
+				ran = true
+				return "", nil
+			})
+
+			var err error
+			captureStdout(t, func() {
+				err = runCommand("get", []string{"pods"})
+			})
+			if tt.wantErr == "" {
+				if err != nil || !ran {
+					t.Errorf("runCommand() error = %v, ran = %v, want a run", err, ran)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("runCommand() error = %v, want %q", err, tt.wantErr)
+			}
+			if ran {
+				t.Errorf("kubectl ran despite the context mismatch")
+			}
+		})
+	}
+}
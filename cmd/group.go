@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+)
+
+// Groupings of the rows of the default output
+const (
+	groupByContext   = "context"
+	groupByNamespace = "namespace"
+)
+
+// splitNamespaceSections splits the tables of the results into one section
+// per namespace, ordered by name, for --group-by namespace. It returns nil
+// when the rows are all in one namespace, or with a note when a table has no
+// NAMESPACE column to group by. Failed contexts are kept in the first
+// section so their errors are printed once.
+func splitNamespaceSections(results []contextResult) []*tableSection {
+	byKey := make(map[string]*tableSection)
+	var failed []contextResult
+	for _, result := range results {
+		if result.err != nil {
+			failed = append(failed, result)
+			continue
+		}
+		lines := strings.Split(strings.TrimSpace(result.output), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		columns := parseHeader(lines[0])
+		index := columnIndex(columns, "NAMESPACE")
+		if index == -1 {
+			notef("--group-by namespace needs a NAMESPACE column, as printed with -A; grouping by context")
+			return nil
+		}
+		// Rows of a namespace, keeping the header of this context's table
+		rows := make(map[string][]string)
+		var namespaces []string
+		for _, line := range lines[1:] {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			namespace := splitRow(columns, line)[index]
+			if rows[namespace] == nil {
+				namespaces = append(namespaces, namespace)
+			}
+			rows[namespace] = append(rows[namespace], line)
+		}
+		for _, namespace := range namespaces {
+			section := byKey[namespace]
+			if section == nil {
+				section = &tableSection{key: namespace}
+				byKey[namespace] = section
+			}
+			part := result
+			part.output = strings.Join(append([]string{lines[0]}, rows[namespace]...), "\n")
+			section.results = append(section.results, part)
+		}
+	}
+	if len(byKey) < 2 {
+		return nil
+	}
+
+	var sections []*tableSection
+	for _, section := range byKey {
+		sections = append(sections, section)
+	}
+	slices.SortFunc(sections, func(a, b *tableSection) int { return strings.Compare(a.key, b.key) })
+	sections[0].results = append(failed, sections[0].results...)
+	return sections
+}
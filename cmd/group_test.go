@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormatDefaultOutputGroupByNamespace(t *testing.T) {
+	setGlobal(t, &groupBy, groupByNamespace)
+	results := []contextResult{
+		{context: "ctx1", output: "NAMESPACE   NAME\npayments    web-1\nbilling     api-1"},
+		{context: "ctx2", output: "NAMESPACE   NAME\npayments    web-2"},
+		{context: "ctx3", output: "Unable to connect", err: fmt.Errorf("exit status 1")},
+	}
+
+	var output string
+	stderr := captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := formatDefaultOutput(results); err != nil {
+				t.Errorf("formatDefaultOutput() error = %v", err)
+			}
+		})
+	})
+	expected := "CONTEXT  NAMESPACE   NAME\n" +
+		"ctx1     billing     api-1\n" +
+		"\n" +
+		"CONTEXT  NAMESPACE   NAME\n" +
+		"ctx1     payments    web-1\n" +
+		"ctx2     payments    web-2\n"
+	if output != expected {
+		t.Errorf("formatDefaultOutput() output =\n%s\nwant\n%s", output, expected)
+	}
+	if strings.Count(stderr, "Context ctx3") != 1 {
+		t.Errorf("stderr = %q, want the ctx3 error once", stderr)
+	}
+}
+
+func TestFormatDefaultOutputGroupByNamespaceWithoutColumn(t *testing.T) {
+	setGlobal(t, &groupBy, groupByNamespace)
+	results := []contextResult{
+		{context: "ctx1", output: "NAME\nweb-1"},
+		{context: "ctx2", output: "NAME\nweb-2"},
+	}
+
+	var output string
+	stderr := captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := formatDefaultOutput(results); err != nil {
+				t.Errorf("formatDefaultOutput() error = %v", err)
+			}
+		})
+	})
+	expected := "CONTEXT  NAME\nctx1     web-1\nctx2     web-2\n"
+	if output != expected {
+		t.Errorf("formatDefaultOutput() output =\n%s\nwant\n%s", output, expected)
+	}
+	if !strings.Contains(stderr, "needs a NAMESPACE column") {
+		t.Errorf("stderr = %q, want a note about the missing column", stderr)
+	}
+}
+
+func TestFormatDefaultOutputInvalidGroupBy(t *testing.T) {
+	setGlobal(t, &groupBy, "cluster")
+	if err := formatDefaultOutput(nil); err == nil {
+		t.Error("formatDefaultOutput() expected error for invalid --group-by")
+	}
+}
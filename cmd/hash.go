@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// resultHashLength is the number of hex digits of a --result-hash digest
+const resultHashLength = 12
+
+// formatResultHashes prints a CONTEXT  HASH table with a short digest of each
+// context's normalized output. Contexts with equal hashes returned the same
+// result, ignoring volatile fields.
+func formatResultHashes(results []contextResult, format outputFormat) error {
+	rows := [][]string{{contextHeader, "HASH"}}
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		normalized, err := normalizeResult(result, format)
+		if err != nil {
+			return fmt.Errorf("context %s: %w", result.context, err)
+		}
+		rows = append(rows, []string{result.context, resultHash(normalized)})
+	}
+	printTable(rows)
+	return nil
+}
+
+// resultHash returns the truncated sha256 digest of normalized output
+func resultHash(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])[:resultHashLength]
+}
+
+// normalizeResult returns a context's output without volatile fields. JSON
+// and YAML items are compared like --merge-strategy=unique does, ignoring
+// their status and server-set metadata such as resourceVersion, in name
+// order. Tables lose their AGE column and column padding.
+func normalizeResult(result contextResult, format outputFormat) (string, error) {
+	switch format {
+	case formatJSON, formatYAML:
+		unmarshal, formatName := json.Unmarshal, "JSON"
+		if format == formatYAML {
+			unmarshal, formatName = yaml.Unmarshal, "YAML"
+		}
+		items, err := collectItems([]contextResult{result}, unmarshal, formatName, true)
+		if err != nil {
+			return "", err
+		}
+		identities := make([]string, len(items))
+		for i, item := range items {
+			identities[i] = itemIdentity(item)
+		}
+		sort.Strings(identities)
+		return strings.Join(identities, "\n"), nil
+	default:
+		lines := strings.Split(strings.TrimSpace(result.output), "\n")
+		lines = rewriteAgeColumn(lines, func(string) string { return "" })
+		for i, line := range lines {
+			lines[i] = strings.Join(strings.Fields(line), " ")
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+}
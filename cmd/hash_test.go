@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeResultHash(t *testing.T) {
+	pod := func(resourceVersion, image string) string {
+		return `{"items": [{"metadata": {"name": "web", "resourceVersion": "` + resourceVersion + `", "uid": "` + resourceVersion + `"},` +
+			` "spec": {"containers": [{"image": "` + image + `"}]}, "status": {"phase": "Running"}}]}`
+	}
+	tests := []struct {
+		name      string
+		format    outputFormat
+		a, b      string
+		wantEqual bool
+	}{
+		{name: "json identical", format: formatJSON, a: pod("100", "nginx:1.25"), b: pod("100", "nginx:1.25"), wantEqual: true},
+		{name: "json server-set metadata", format: formatJSON, a: pod("100", "nginx:1.25"), b: pod("987", "nginx:1.25"), wantEqual: true},
+		{name: "json field change", format: formatJSON, a: pod("100", "nginx:1.25"), b: pod("100", "nginx:1.26"), wantEqual: false},
+		{
+			name:      "json item order",
+			format:    formatJSON,
+			a:         `{"items": [{"metadata": {"name": "a"}}, {"metadata": {"name": "b"}}]}`,
+			b:         `{"items": [{"metadata": {"name": "b"}}, {"metadata": {"name": "a"}}]}`,
+			wantEqual: true,
+		},
+		{name: "yaml field change", format: formatYAML, a: "items:\n- metadata:\n    name: web\n", b: "items:\n- metadata:\n    name: api\n", wantEqual: false},
+		{
+			name:      "table age and padding",
+			format:    formatDefault,
+			a:         "NAME   READY   AGE\nweb    1/1     5d\n",
+			b:         "NAME        READY   AGE\nweb         1/1     12m\n",
+			wantEqual: true,
+		},
+		{name: "table field change", format: formatDefault, a: "NAME   READY\nweb    1/1\n", b: "NAME   READY\nweb    0/1\n", wantEqual: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := func(output string) string {
+				normalized, err := normalizeResult(contextResult{context: "ctx1", output: output}, tt.format)
+				if err != nil {
+					t.Fatalf("normalizeResult() error = %v", err)
+				}
+				return resultHash(normalized)
+			}
+			a, b := hash(tt.a), hash(tt.b)
+			if len(a) != resultHashLength {
+				t.Errorf("hash %q has %d digits, want %d", a, len(a), resultHashLength)
+			}
+			if (a == b) != tt.wantEqual {
+				t.Errorf("hashes %s and %s, wantEqual %v", a, b, tt.wantEqual)
+			}
+		})
+	}
+}
+
+func TestFormatResultHashes(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "NAME   AGE\nweb    5d\n"},
+		{context: "ctx2", output: "NAME   AGE\nweb    2h\n"},
+		{context: "ctx3", output: "NAME   AGE\napi    2h\n"},
+	}
+	output := captureStdout(t, func() {
+		if err := formatResultHashes(results, formatDefault); err != nil {
+			t.Errorf("formatResultHashes() error = %v", err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 4 || strings.Fields(lines[0])[1] != "HASH" {
+		t.Fatalf("output = %q, want a CONTEXT  HASH table with 3 rows", output)
+	}
+	hashes := []string{strings.Fields(lines[1])[1], strings.Fields(lines[2])[1], strings.Fields(lines[3])[1]}
+	if hashes[0] != hashes[1] || hashes[0] == hashes[2] {
+		t.Errorf("hashes = %v, want ctx1 and ctx2 equal and ctx3 different", hashes)
+	}
+}
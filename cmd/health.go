@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Check that the API server of every context is ready",
+	Long:  `Request --probe-path (default /readyz) from the API server of every context with kubectl get --raw and report an HTTP-style status per context.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHealth()
+	},
+}
+
+// httpStatuses maps the reasons kubectl reports for failed requests to HTTP statuses
+var httpStatuses = map[string]string{
+	"BadRequest":          "400 Bad Request",
+	"Unauthorized":        "401 Unauthorized",
+	"Forbidden":           "403 Forbidden",
+	"NotFound":            "404 Not Found",
+	"InternalError":       "500 Internal Server Error",
+	"InternalServerError": "500 Internal Server Error",
+	"ServiceUnavailable":  "503 Service Unavailable",
+	"Unreachable":         "unreachable",
+}
+
+func runHealth() error {
+	contexts, err := getContexts()
+	if err != nil {
+		return fmt.Errorf("failed to get contexts: %w", err)
+	}
+	contexts, err = sortContexts(contexts, sortOrder)
+	if err != nil {
+		return err
+	}
+
+	results := runContexts(contexts, "get", []string{"--raw", probePath})
+	rows, unhealthy := healthRows(results)
+	printTable(rows)
+
+	if unhealthy > 0 {
+		return fmt.Errorf("%d of %d contexts failed the %s probe", unhealthy, len(results), probePath)
+	}
+	return nil
+}
+
+// healthRows returns a CONTEXT/STATUS/DETAIL table of the probe results and
+// the number of contexts that failed the probe
+func healthRows(results []contextResult) ([][]string, int) {
+	rows := [][]string{{contextHeader, "STATUS", "DETAIL"}}
+	unhealthy := 0
+	for _, result := range results {
+		if result.err == nil {
+			rows = append(rows, []string{result.context, "200 OK", probeDetail(result.output)})
+			continue
+		}
+
+		unhealthy++
+		code, message := parseKubectlError(result.output, result.err)
+		status, ok := httpStatuses[code]
+		if !ok {
+			status = "error"
+		}
+		rows = append(rows, []string{result.context, status, message})
+	}
+	return rows, unhealthy
+}
+
+// probeDetail summarizes a probe response by its last line, which is the
+// verdict for both /readyz ("ok") and /readyz?verbose ("readyz check passed")
+func probeDetail(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
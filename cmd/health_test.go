@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRunHealth(t *testing.T) {
+	writeKubeconfig(t, "healthy", "degraded", "down")
+	setGlobal(t, &probePath, "/readyz?verbose")
+
+	var mu sync.Mutex
+	var probed []string
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		probed = append(probed, argValue(args, "--raw"))
+		mu.Unlock()
+		switch argValue(args, "--context") {
+		case "healthy":
+			return "[+]ping ok\n[+]etcd ok\nreadyz check passed\n", nil
+		case "degraded":
+			return `Error from server (InternalError): an error on the server ("[-]etcd failed: reason withheld") has prevented the request from succeeding`, fmt.Errorf("exit status 1")
+		default:
+			return "Unable to connect to the server: dial tcp 10.0.0.1:443: i/o timeout", fmt.Errorf("exit status 1")
+		}
+	})
+
+	var err error
+	output := captureStdout(t, func() {
+		err = runHealth()
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "2 of 3 contexts") {
+		t.Errorf("runHealth() error = %v, want 2 of 3 contexts failed", err)
+	}
+	for _, path := range probed {
+		if path != "/readyz?verbose" {
+			t.Errorf("probe requested %q, want /readyz?verbose", path)
+		}
+	}
+
+	expected := []string{
+		"CONTEXT   STATUS                     DETAIL",
+		"degraded  500 Internal Server Error  an error on the server",
+		"down      unreachable                dial tcp 10.0.0.1:443: i/o timeout",
+		"healthy   200 OK                     readyz check passed",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("runHealth() output missing %q:\n%s", line, output)
+		}
+	}
+}
+
+func TestRunHealthAllReady(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	fakeKubectl(t, func(args []string) (string, error) {
+		return "ok", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runHealth(); err != nil {
+			t.Errorf("runHealth() error = %v", err)
+		}
+	})
+	if strings.Count(output, "200 OK") != 2 {
+		t.Errorf("runHealth() should report both contexts as 200 OK:\n%s", output)
+	}
+}
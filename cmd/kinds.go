@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// kindSection is the merged table of one kind: the header of the first
+// context that returned the kind and the rows of every context
+type kindSection struct {
+	kind   string
+	header []string
+	rows   [][]string
+}
+
+// hasShowKind reports whether kubectl is asked to prefix names with their
+// kind, by --show-kind before or after the subcommand
+func hasShowKind(args []string) bool {
+	if showKind {
+		return true
+	}
+	for _, arg := range args {
+		if arg == "--show-kind" || arg == "--show-kind=true" {
+			return true
+		}
+	}
+	return false
+}
+
+// formatKindSections merges get output whose names are prefixed with their
+// kind, such as get all, into one table per kind with CONTEXT and KIND
+// columns. kubectl prints a section per kind, separated by blank lines.
+func formatKindSections(results []contextResult) error {
+	var sections []*kindSection
+	byKind := make(map[string]*kindSection)
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		for _, block := range strings.Split(strings.TrimSpace(result.output), "\n\n") {
+			lines := strings.Split(strings.TrimSpace(block), "\n")
+			if len(lines) < 2 {
+				continue
+			}
+			columns := parseHeader(lines[0])
+			if columnIndex(columns, "NAME") != 0 {
+				return fmt.Errorf("context %s: --show-kind requires table output starting with a NAME column", result.context)
+			}
+			for _, line := range lines[1:] {
+				cells := splitRow(columns, line)
+				kind, name, ok := strings.Cut(cells[0], "/")
+				if !ok {
+					kind, name = "<none>", cells[0]
+				}
+				section := byKind[kind]
+				if section == nil {
+					section = &kindSection{kind: kind, header: columnNames(columns)}
+					byKind[kind] = section
+					sections = append(sections, section)
+				}
+				row := append([]string{result.context, kind, name}, cells[1:]...)
+				section.rows = append(section.rows, row)
+			}
+		}
+	}
+
+	for i, section := range sections {
+		if i > 0 {
+			fmt.Println()
+		}
+		contexts := []string{contextHeader}
+		rows := [][]string{append([]string{"KIND"}, section.header...)}
+		for _, row := range section.rows {
+			contexts = append(contexts, row[0])
+			rows = append(rows, row[1:])
+		}
+		width := 0
+		for _, context := range contexts {
+			width = max(width, utf8.RuneCountInString(context))
+		}
+		for j, line := range renderTableRows(rows) {
+			context := contexts[j]
+			padding := strings.Repeat(" ", width-utf8.RuneCountInString(context))
+			if j > 0 {
+				context = colorizeContext(context)
+			}
+			fmt.Printf("%s%s"+contextGap+"%s\n", context, padding, line)
+		}
+	}
+	return nil
+}
+
+// tableSection is one table of multi-table output, such as a kind of get
+// all, with the part of each context's output that belongs to it
+type tableSection struct {
+	key     string
+	results []contextResult
+}
+
+// splitTableSections splits output with several blank-line separated tables
+// per context, as printed by get all, into one section per kind, or per
+// header when names have no kind prefix. It returns nil when every context
+// printed a single table. Failed contexts are kept in the first section so
+// their errors are printed once.
+func splitTableSections(results []contextResult) []*tableSection {
+	var sections []*tableSection
+	var failed []contextResult
+	byKey := make(map[string]*tableSection)
+	multiple := false
+	for _, result := range results {
+		if result.err != nil {
+			failed = append(failed, result)
+			continue
+		}
+		blocks := tableBlocks(result.output)
+		if len(blocks) > 1 {
+			multiple = true
+		}
+		for _, block := range blocks {
+			key := sectionKey(block)
+			section := byKey[key]
+			if section == nil {
+				section = &tableSection{key: key}
+				byKey[key] = section
+				sections = append(sections, section)
+			}
+			part := result
+			part.output = block
+			section.results = append(section.results, part)
+		}
+	}
+	if !multiple {
+		return nil
+	}
+	sections[0].results = append(failed, sections[0].results...)
+	return sections
+}
+
+// tableBlocks returns the blank-line separated blocks of output. Unless every
+// block starts with an upper-case header line and has rows, such as in logs
+// with blank lines, the output is a single block.
+func tableBlocks(output string) []string {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil
+	}
+	var blocks []string
+	for _, block := range strings.Split(output, "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		header, _, hasRows := strings.Cut(block, "\n")
+		if !hasRows || header != strings.ToUpper(header) {
+			return []string{output}
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// sectionKey returns the kind of a table's names, such as pod for pod/web-0,
+// or its column names when the names have no kind prefix
+func sectionKey(block string) string {
+	lines := strings.Split(block, "\n")
+	columns := parseHeader(lines[0])
+	if nameIdx := columnIndex(columns, "NAME"); nameIdx != -1 && len(lines) > 1 {
+		if kind, _, ok := strings.Cut(splitRow(columns, lines[1])[nameIdx], "/"); ok {
+			return kind
+		}
+	}
+	return strings.Join(columnNames(columns), " ")
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatKindSections(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "NAME       READY   STATUS\npod/web    1/1     Running\n\nNAME                 TYPE        CLUSTER-IP\nservice/kubernetes   ClusterIP   10.0.0.1\n"},
+		{context: "ctx2", output: "NAME       READY   STATUS\npod/api    0/1     Pending\n\nNAME                     READY   UP-TO-DATE\ndeployment.apps/api      0/1     1\n"},
+	}
+
+	output := captureStdout(t, func() {
+		if err := formatKindSections(results); err != nil {
+			t.Errorf("formatKindSections() error = %v", err)
+		}
+	})
+
+	expected := strings.Join([]string{
+		"CONTEXT  KIND   NAME   READY   STATUS",
+		"ctx1     pod    web    1/1     Running",
+		"ctx2     pod    api    0/1     Pending",
+		"",
+		"CONTEXT  KIND      NAME         TYPE        CLUSTER-IP",
+		"ctx1     service   kubernetes   ClusterIP   10.0.0.1",
+		"",
+		"CONTEXT  KIND              NAME   READY   UP-TO-DATE",
+		"ctx2     deployment.apps   api    0/1     1",
+	}, "\n") + "\n"
+	if output != expected {
+		t.Errorf("formatKindSections() =\n%s\nwant\n%s", output, expected)
+	}
+}
+
+func TestRunCommandShowKind(t *testing.T) {
+	writeKubeconfig(t, "ctx1")
+	setGlobal(t, &showKind, true)
+	fakeKubectl(t, func(args []string) (string, error) {
+		if args[len(args)-1] != "--show-kind" {
+			t.Errorf("kubectl args = %v, want --show-kind passed on", args)
+		}
+		return "NAME      READY\npod/web   1/1\n", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+	if output != "CONTEXT  KIND   NAME   READY\nctx1     pod    web    1/1\n" {
+		t.Errorf("output = %q", output)
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// contextPlaceholder is replaced by the context name in kustomize arguments
+const contextPlaceholder = "{context}"
+
+var kustomizeCmd = &cobra.Command{
+	Use:   "kustomize DIR",
+	Short: "Render per-context kustomize overlays",
+	Long: `Run kubectl kustomize for every context, with {context} in the arguments replaced by the context name,
+e.g. kubectl multi-context kustomize overlays/{context}. Nothing is applied to the clusters.`,
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkKustomizeArgs(args); err != nil {
+			return err
+		}
+		return runCommand("kustomize", args)
+	},
+}
+
+// checkKustomizeArgs rejects kustomize invocations that would render the
+// same local directory once per context
+func checkKustomizeArgs(args []string) error {
+	for _, arg := range args {
+		if arg == "-o" || arg == "--output" || strings.HasPrefix(arg, "--output=") {
+			return fmt.Errorf("kustomize: -o is not supported, output is printed per context")
+		}
+	}
+	for _, arg := range args {
+		if strings.Contains(arg, contextPlaceholder) {
+			return nil
+		}
+	}
+	return fmt.Errorf("kustomize builds locally and ignores the context; include %s in the directory, e.g. overlays/%s", contextPlaceholder, contextPlaceholder)
+}
+
+// expandContextPlaceholder returns args with {context} replaced by context
+func expandContextPlaceholder(args []string, context string) []string {
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = strings.ReplaceAll(arg, contextPlaceholder, context)
+	}
+	return expanded
+}
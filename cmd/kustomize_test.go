@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckKustomizeArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "per-context overlay", args: []string{"overlays/{context}"}},
+		{name: "placeholder in flag value", args: []string{"--load-restrictor=LoadRestrictionsNone", "clusters/{context}/app"}},
+		{name: "same directory for every context", args: []string{"overlays/prod"}, wantErr: "include {context}"},
+		{name: "output file", args: []string{"overlays/{context}", "-o", "out.yaml"}, wantErr: "-o is not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkKustomizeArgs(tt.args)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("checkKustomizeArgs(%v) error = %v, want nil", tt.args, err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("checkKustomizeArgs(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunCommandKustomize(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	fakeKubectl(t, func(args []string) (string, error) {
+		dir := args[len(args)-1]
+		return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + strings.TrimPrefix(dir, "overlays/") + "\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: app\n", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("kustomize", []string{"overlays/{context}"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	expected := "# === context: ctx1 ===\n" +
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ctx1\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: app\n" +
+		"---\n# === context: ctx2 ===\n" +
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ctx2\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: app\n"
+	if output != expected {
+		t.Errorf("kustomize output =\n%s\nwant\n%s", output, expected)
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Formats for --log-format
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// runLog receives structured records about the run, such as the start and
+// finish of each context. It discards them unless --log-format=json.
+var runLog = slog.New(slog.DiscardHandler)
+
+// setupLogging points runLog at stderr or --log-file according to
+// --log-format and returns a function that restores it
+func setupLogging() (func(), error) {
+	switch logFormat {
+	case logFormatText:
+		return func() {}, nil
+	case logFormatJSON:
+	default:
+		return nil, fmt.Errorf("invalid --log-format %q: must be %s or %s", logFormat, logFormatText, logFormatJSON)
+	}
+
+	var w io.Writer = os.Stderr
+	var file *os.File
+	if logFile != "" {
+		var err error
+		file, err = os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = file
+	}
+
+	previous := runLog
+	runLog = slog.New(slog.NewJSONHandler(w, nil))
+	return func() {
+		runLog = previous
+		if file != nil {
+			file.Close()
+		}
+	}, nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunCommandJSONLog(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	logPath := filepath.Join(t.TempDir(), "run.log")
+	setGlobal(t, &logFormat, logFormatJSON)
+	setGlobal(t, &logFile, logPath)
+	fakeKubectl(t, func(args []string) (string, error) {
+		if argValue(args, "--context") == "ctx2" {
+			return "Unable to connect to the server", fmt.Errorf("exit status 1")
+		}
+		return "NAME   READY\npod1   1/1\n", nil
+	})
+
+	captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	file, err := os.Open(logPath)
+	if err != nil {
+		t.Fatalf("failed to open log file: %v", err)
+	}
+	defer file.Close()
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+
+	find := func(msg, context string) map[string]interface{} {
+		for _, record := range records {
+			if record["msg"] == msg && (context == "" || record["context"] == context) {
+				return record
+			}
+		}
+		t.Errorf("no %q record for context %q in %v", msg, context, records)
+		return nil
+	}
+
+	if record := find("run started", ""); record != nil && record["contexts"] != float64(2) {
+		t.Errorf("run started contexts = %v, want 2", record["contexts"])
+	}
+	find("context started", "ctx1")
+	if record := find("context finished", "ctx1"); record != nil {
+		if _, ok := record["duration_ms"]; !ok {
+			t.Errorf("context finished record has no duration_ms: %v", record)
+		}
+	}
+	if record := find("context failed", "ctx2"); record != nil {
+		if record["level"] != "ERROR" || record["error"] != "exit status 1" {
+			t.Errorf("context failed record = %v, want level ERROR and the error", record)
+		}
+	}
+	if record := find("run finished", ""); record != nil && record["failed"] != float64(1) {
+		t.Errorf("run finished failed = %v, want 1", record["failed"])
+	}
+}
+
+func TestSetupLoggingInvalidFormat(t *testing.T) {
+	setGlobal(t, &logFormat, "xml")
+	if _, err := setupLogging(); err == nil {
+		t.Errorf("setupLogging() expected error for invalid --log-format")
+	}
+}
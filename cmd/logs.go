@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs POD|TYPE/NAME|-l SELECTOR",
+	Short: "Run kubectl logs against all contexts",
+	Long: `Run kubectl logs against all contexts in parallel, prefixing every line with the context and the pod and
+container it came from, e.g. [prod-eu pod/web-0/nginx].`,
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if isFollow(args) {
+			return followLogs(applyLogPrefix(args))
+		}
+		return runCommand("logs", applyLogPrefix(args))
+	},
+}
+
+// kubectlFollow runs kubectl, calling onLine with each complete line of its
+// stdout as it is printed, and returns its stderr once it exits. It is a
+// variable so tests can substitute a fake kubectl.
+var kubectlFollow = func(args []string, onLine func(string)) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("kubectl", args...)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	if err := scanLines(stdout, onLine); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return stderr.String(), err
+	}
+	return stderr.String(), cmd.Wait()
+}
+
+// maxLogLineSize is the longest log line followed without being cut
+const maxLogLineSize = 1024 * 1024
+
+// scanLines calls onLine with each line read from r
+func scanLines(r io.Reader, onLine func(string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		onLine(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// isFollow reports whether logs args ask kubectl to follow the logs
+func isFollow(args []string) bool {
+	for _, arg := range args {
+		if arg == "-f" || arg == "--follow" || arg == "--follow=true" {
+			return true
+		}
+	}
+	return false
+}
+
+// followLogs follows the logs of every context at once, however many there
+// are, until all the streams end. Lines are printed whole as they arrive, one
+// at a time, so the streams interleave without mixing. Streams that fail
+// fail the run.
+func followLogs(args []string) error {
+	if inputFile != "" {
+		return fmt.Errorf("--input-file cannot replay logs -f")
+	}
+	contexts, finish, err := setupRun("logs", args)
+	if err != nil {
+		return err
+	}
+	defer finish()
+	rewrite, _ := parseContextRewrite(contextRewriteExpr) // validated by setupRun
+	args = applyLogDefaults(args)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make([]contextResult, len(contexts))
+	for i, context := range contexts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := rewrite.apply(context)
+			runLog.Info("context started", "context", context)
+			stderr, err := kubectlFollow(buildKubectlArgs(context, "logs", args), func(line string) {
+				mu.Lock()
+				defer mu.Unlock()
+				fmt.Fprintln(os.Stdout, logLine(name, line))
+			})
+			results[i] = contextResult{context: name, err: err}
+			if err != nil {
+				runLog.Error("context failed", "context", context, "error", err.Error())
+				mu.Lock()
+				defer mu.Unlock()
+				printContextError(name, err, strings.TrimSpace(stderr))
+				return
+			}
+			runLog.Info("context finished", "context", context)
+		}()
+	}
+	wg.Wait()
+	return checkFailures(results)
+}
+
+// applyLogPrefix adds --prefix unless args already set it, so kubectl names
+// the pod and container of each line
+func applyLogPrefix(args []string) []string {
+	for _, arg := range args {
+		if arg == "--prefix" || strings.HasPrefix(arg, "--prefix=") {
+			return args
+		}
+	}
+	return append(append([]string(nil), args...), "--prefix")
+}
+
+// formatLogOutput prints every log line prefixed with its context. The
+// [pod/NAME/CONTAINER] prefix kubectl adds is merged into the context's,
+// e.g. [prod-eu pod/web-0/nginx].
+func formatLogOutput(results []contextResult) error {
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		output := strings.TrimRight(result.output, "\n")
+		if output == "" {
+			continue
+		}
+		for _, line := range strings.Split(output, "\n") {
+			fmt.Println(logLine(result.context, line))
+		}
+	}
+	return nil
+}
+
+// logLine returns a log line prefixed with its colored context, merging in
+// the [pod/NAME/CONTAINER] prefix kubectl added, if any
+func logLine(context, line string) string {
+	if source, message, ok := cutLogPrefix(line); ok {
+		return fmt.Sprintf("[%s %s] %s", colorizeContext(context), source, message)
+	}
+	return fmt.Sprintf("[%s] %s", colorizeContext(context), line)
+}
+
+// cutLogPrefix splits a line of kubectl logs --prefix into its
+// pod/NAME/CONTAINER source and the message
+func cutLogPrefix(line string) (source, message string, ok bool) {
+	rest, found := strings.CutPrefix(line, "[pod/")
+	if !found {
+		return "", "", false
+	}
+	source, message, ok = strings.Cut(rest, "] ")
+	if !ok {
+		source, ok = strings.CutSuffix(rest, "]")
+	}
+	return "pod/" + source, message, ok
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestApplyLogPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{name: "adds prefix", args: []string{"web-0"}, expected: []string{"web-0", "--prefix"}},
+		{name: "keeps prefix", args: []string{"-l", "app=web", "--prefix"}, expected: []string{"-l", "app=web", "--prefix"}},
+		{name: "keeps disabled prefix", args: []string{"web-0", "--prefix=false"}, expected: []string{"web-0", "--prefix=false"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyLogPrefix(tt.args); strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("applyLogPrefix(%v) = %v, want %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunCommandLogs(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	var commands []string
+	fakeKubectl(t, func(args []string) (string, error) {
+		if argValue(args, "--context") == "ctx1" {
+			commands = append(commands, strings.Join(args, " "))
+			return "[pod/web-0/nginx] GET / 200\n[pod/web-1/nginx] GET /healthz 200\n", nil
+		}
+		return "plain line\n", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := logsCmd.RunE(logsCmd, []string{"-l", "app=web"}); err != nil {
+			t.Errorf("logs error = %v", err)
+		}
+	})
+
+	expected := "[ctx1 pod/web-0/nginx] GET / 200\n[ctx1 pod/web-1/nginx] GET /healthz 200\n[ctx2] plain line\n"
+	if output != expected {
+		t.Errorf("logs output = %q, want %q", output, expected)
+	}
+	if len(commands) != 1 || !strings.Contains(commands[0], "logs -l app=web --prefix --tail=100") {
+		t.Errorf("kubectl ran %v, want logs with --prefix and the default --tail", commands)
+	}
+}
+
+func TestCutLogPrefix(t *testing.T) {
+	tests := []struct {
+		line    string
+		source  string
+		message string
+		ok      bool
+	}{
+		{line: "[pod/web-0/nginx] started", source: "pod/web-0/nginx", message: "started", ok: true},
+		{line: "[pod/web-0/nginx]", source: "pod/web-0/nginx", ok: true},
+		{line: "[INFO] started", ok: false},
+		{line: "started", ok: false},
+	}
+
+	for _, tt := range tests {
+		source, message, ok := cutLogPrefix(tt.line)
+		if ok != tt.ok || ok && (source != tt.source || message != tt.message) {
+			t.Errorf("cutLogPrefix(%q) = %q, %q, %v, want %q, %q, %v", tt.line, source, message, ok, tt.source, tt.message, tt.ok)
+		}
+	}
+}
+
+func TestFollowLogs(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2", "ctx3")
+	setGlobal(t, &kubectlFollow, func(args []string, onLine func(string)) (string, error) {
+		context := argValue(args, "--context")
+		if context == "ctx3" {
+			return "error: pods \"web-0\" not found", fmt.Errorf("exit status 1")
+		}
+		for i := 0; i < 50; i++ {
+			onLine(fmt.Sprintf("[pod/web-0/nginx] %s line %d", context, i))
+		}
+		return "", nil
+	})
+
+	var stderr string
+	output := captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := logsCmd.RunE(logsCmd, []string{"web-0", "-f"}); err == nil || err.Error() != "1 of 3 contexts failed" {
+				t.Errorf("logs -f error = %v, want the failed stream to fail the run", err)
+			}
+		})
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 100 {
+		t.Fatalf("logs -f printed %d lines, want 100", len(lines))
+	}
+	next := map[string]int{}
+	for _, line := range lines {
+		var context string
+		var n int
+		if _, err := fmt.Sscanf(line, "[%s pod/web-0/nginx] %s line %d", new(string), &context, &n); err != nil {
+			t.Fatalf("logs -f printed a mixed up line %q", line)
+		}
+		if n != next[context] {
+			t.Errorf("line %q out of order, want line %d of %s", line, next[context], context)
+		}
+		next[context]++
+	}
+	if !strings.Contains(stderr, "Context ctx3: Error: exit status 1") || !strings.Contains(stderr, "not found") {
+		t.Errorf("stderr = %q, want the failed context's error", stderr)
+	}
+}
+
+func TestFollowLogsColors(t *testing.T) {
+	writeKubeconfig(t, "ctx1")
+	setGlobal(t, &colorByContext, colorAlways)
+	setGlobal(t, &kubectlFollow, func(args []string, onLine func(string)) (string, error) {
+		onLine("[pod/web-0/nginx] started")
+		return "", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := logsCmd.RunE(logsCmd, []string{"web-0", "--follow"}); err != nil {
+			t.Errorf("logs --follow error = %v", err)
+		}
+	})
+	if want := "[" + colorizeContext("ctx1") + " pod/web-0/nginx] started\n"; output != want || !strings.Contains(output, "\033[") {
+		t.Errorf("logs --follow output = %q, want %q", output, want)
+	}
+}
+
+func TestScanLines(t *testing.T) {
+	var lines []string
+	long := strings.Repeat("x", 100*1024)
+	if err := scanLines(strings.NewReader("first\n"+long+"\nlast"), func(line string) { lines = append(lines, line) }); err != nil {
+		t.Fatalf("scanLines() error = %v", err)
+	}
+	if len(lines) != 3 || lines[0] != "first" || lines[1] != long || lines[2] != "last" {
+		t.Errorf("scanLines() = %d lines, want first, the long line and last", len(lines))
+	}
+}
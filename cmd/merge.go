@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Strategies for --merge-strategy
+const (
+	mergeAll       = "all"
+	mergeUnique    = "unique"
+	mergeByContext = "by-context"
+
+	contextsAnnotation = "multi/contexts"
+)
+
+// serverSetMetadata lists the metadata fields the API server fills in, which
+// differ between clusters even for identical objects
+var serverSetMetadata = []string{"uid", "resourceVersion", "creationTimestamp", "generation", "managedFields", "selfLink"}
+
+// buildList merges the items of all contexts into a List according to
+// --merge-strategy
+func buildList(results []contextResult, unmarshal func([]byte, interface{}) error, formatName string, strict bool) (map[string]interface{}, error) {
+	list := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+	}
+
+	switch mergeStrategy {
+	case mergeAll:
+		items, err := collectItems(results, unmarshal, formatName, strict)
+		if err != nil {
+			return nil, err
+		}
+		list["items"] = items
+	case mergeUnique, mergeByContext:
+		var groups []map[string]interface{}
+		var perContext [][]map[string]interface{}
+		for _, result := range results {
+			items, err := collectItems([]contextResult{result}, unmarshal, formatName, strict)
+			if err != nil {
+				return nil, err
+			}
+			perContext = append(perContext, items)
+			groups = append(groups, map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "List",
+				"context":    result.context,
+				"items":      items,
+			})
+		}
+		if mergeStrategy == mergeByContext {
+			list["items"] = groups
+		} else {
+			list["items"] = uniqueItems(results, perContext)
+		}
+	default:
+		return nil, fmt.Errorf("invalid --merge-strategy %q: must be %s, %s or %s", mergeStrategy, mergeAll, mergeUnique, mergeByContext)
+	}
+	return list, nil
+}
+
+// uniqueItems keeps the first of each set of identical objects across
+// contexts and lists the contexts that returned it in a multi/contexts
+// annotation, which replaces the single context the object was decorated
+// with. Objects are compared ignoring their context, status and server-set
+// metadata.
+func uniqueItems(results []contextResult, perContext [][]map[string]interface{}) []map[string]interface{} {
+	unique := []map[string]interface{}{}
+	contexts := make(map[string][]string)
+	kept := make(map[string]map[string]interface{})
+
+	for i, items := range perContext {
+		for _, item := range items {
+			if _, ok := item["metadata"].(map[string]interface{}); !ok {
+				unique = append(unique, item)
+				continue
+			}
+			key := itemIdentity(item)
+			if _, seen := kept[key]; !seen {
+				kept[key] = item
+				unique = append(unique, item)
+			}
+			contexts[key] = append(contexts[key], results[i].context)
+		}
+	}
+
+	for key, item := range kept {
+		metadata := item["metadata"].(map[string]interface{})
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, contextAnnotation)
+		}
+		delete(metadata, "context")
+		delete(item, "context")
+		annotate(metadata, contextsAnnotation, strings.Join(contexts[key], ","))
+	}
+	return unique
+}
+
+// itemIdentity returns a key that is equal for objects that only differ by
+// their context, status or server-set metadata
+func itemIdentity(item map[string]interface{}) string {
+	data, _ := json.Marshal(item)
+	var stripped map[string]interface{}
+	json.Unmarshal(data, &stripped)
+
+	delete(stripped, "context")
+	delete(stripped, "contextGroup")
+	delete(stripped, "status")
+	if metadata, ok := stripped["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "context")
+		delete(metadata, "contextGroup")
+		for _, field := range serverSetMetadata {
+			delete(metadata, field)
+		}
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			for key := range annotations {
+				if strings.HasPrefix(key, "multi/") {
+					delete(annotations, key)
+				}
+			}
+			if len(annotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+	}
+
+	key, _ := json.Marshal(stripped)
+	return string(key)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildListMergeStrategies(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: `{"items":[` +
+			`{"kind":"CustomResourceDefinition","metadata":{"name":"widgets.example.com","uid":"a1","resourceVersion":"10"},"spec":{"group":"example.com"},"status":{"storedVersions":["v1"]}},` +
+			`{"kind":"Pod","metadata":{"name":"pod1"}}]}`},
+		{context: "ctx2", output: `{"items":[` +
+			`{"kind":"CustomResourceDefinition","metadata":{"name":"widgets.example.com","uid":"b2","resourceVersion":"99"},"spec":{"group":"example.com"},"status":{"storedVersions":["v1","v2"]}}]}`},
+		{context: "ctx3", output: `{"items":[` +
+			`{"kind":"CustomResourceDefinition","metadata":{"name":"widgets.example.com","uid":"c3"},"spec":{"group":"other.example.com"}}]}`},
+	}
+
+	tests := []struct {
+		strategy string
+		expected string
+	}{
+		{
+			strategy: mergeAll,
+			expected: `{"apiVersion":"v1","items":[` +
+				`{"kind":"CustomResourceDefinition","metadata":{"annotations":{"multi/context":"ctx1"},"name":"widgets.example.com","resourceVersion":"10","uid":"a1"},"spec":{"group":"example.com"},"status":{"storedVersions":["v1"]}},` +
+				`{"kind":"Pod","metadata":{"annotations":{"multi/context":"ctx1"},"name":"pod1"}},` +
+				`{"kind":"CustomResourceDefinition","metadata":{"annotations":{"multi/context":"ctx2"},"name":"widgets.example.com","resourceVersion":"99","uid":"b2"},"spec":{"group":"example.com"},"status":{"storedVersions":["v1","v2"]}},` +
+				`{"kind":"CustomResourceDefinition","metadata":{"annotations":{"multi/context":"ctx3"},"name":"widgets.example.com","uid":"c3"},"spec":{"group":"other.example.com"}}` +
+				`],"kind":"List"}`,
+		},
+		{
+			strategy: mergeUnique,
+			expected: `{"apiVersion":"v1","items":[` +
+				`{"kind":"CustomResourceDefinition","metadata":{"annotations":{"multi/contexts":"ctx1,ctx2"},"name":"widgets.example.com","resourceVersion":"10","uid":"a1"},"spec":{"group":"example.com"},"status":{"storedVersions":["v1"]}},` +
+				`{"kind":"Pod","metadata":{"annotations":{"multi/contexts":"ctx1"},"name":"pod1"}},` +
+				`{"kind":"CustomResourceDefinition","metadata":{"annotations":{"multi/contexts":"ctx3"},"name":"widgets.example.com","uid":"c3"},"spec":{"group":"other.example.com"}}` +
+				`],"kind":"List"}`,
+		},
+		{
+			strategy: mergeByContext,
+			expected: `{"apiVersion":"v1","items":[` +
+				`{"apiVersion":"v1","context":"ctx1","items":[` +
+				`{"kind":"CustomResourceDefinition","metadata":{"annotations":{"multi/context":"ctx1"},"name":"widgets.example.com","resourceVersion":"10","uid":"a1"},"spec":{"group":"example.com"},"status":{"storedVersions":["v1"]}},` +
+				`{"kind":"Pod","metadata":{"annotations":{"multi/context":"ctx1"},"name":"pod1"}}],"kind":"List"},` +
+				`{"apiVersion":"v1","context":"ctx2","items":[` +
+				`{"kind":"CustomResourceDefinition","metadata":{"annotations":{"multi/context":"ctx2"},"name":"widgets.example.com","resourceVersion":"99","uid":"b2"},"spec":{"group":"example.com"},"status":{"storedVersions":["v1","v2"]}}],"kind":"List"},` +
+				`{"apiVersion":"v1","context":"ctx3","items":[` +
+				`{"kind":"CustomResourceDefinition","metadata":{"annotations":{"multi/context":"ctx3"},"name":"widgets.example.com","uid":"c3"},"spec":{"group":"other.example.com"}}],"kind":"List"}` +
+				`],"kind":"List"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			setGlobal(t, &mergeStrategy, tt.strategy)
+			list, err := buildList(results, json.Unmarshal, "JSON", false)
+			if err != nil {
+				t.Fatalf("buildList() error = %v", err)
+			}
+			data, err := json.Marshal(list)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("buildList() =\n%s\nwant\n%s", data, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildListInvalidMergeStrategy(t *testing.T) {
+	setGlobal(t, &mergeStrategy, "first")
+	if _, err := buildList(nil, json.Unmarshal, "JSON", false); err == nil {
+		t.Errorf("buildList() expected error for invalid --merge-strategy")
+	}
+}
+
+func TestBuildListUniqueReplacesContext(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: `{"items":[{"kind":"Namespace","metadata":{"name":"default"}}]}`},
+		{context: "ctx2", output: `{"items":[{"kind":"Namespace","metadata":{"name":"default"}}]}`},
+	}
+
+	tests := []struct {
+		placement string
+		expected  string
+	}{
+		{placement: placementAnnotation, expected: `{"kind":"Namespace","metadata":{"annotations":{"multi/contexts":"ctx1,ctx2"},"name":"default"}}`},
+		{placement: placementMetadata, expected: `{"kind":"Namespace","metadata":{"annotations":{"multi/contexts":"ctx1,ctx2"},"name":"default"}}`},
+		{placement: placementSibling, expected: `{"kind":"Namespace","metadata":{"annotations":{"multi/contexts":"ctx1,ctx2"},"name":"default"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.placement, func(t *testing.T) {
+			setGlobal(t, &mergeStrategy, mergeUnique)
+			setGlobal(t, &contextPlacement, tt.placement)
+			list, err := buildList(results, json.Unmarshal, "JSON", false)
+			if err != nil {
+				t.Fatalf("buildList() error = %v", err)
+			}
+			data, err := json.Marshal(list["items"])
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(data) != "["+tt.expected+"]" {
+				t.Errorf("items =\n%s\nwant\n[%s]", data, tt.expected)
+			}
+		})
+	}
+}
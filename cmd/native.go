@@ -0,0 +1,587 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Execution backends for --backend
+const (
+	backendKubectl = "kubectl"
+	backendNative  = "native"
+)
+
+// nativeTableAccept asks the API server to render a table, as kubectl does,
+// falling back to plain JSON for servers that can't
+const nativeTableAccept = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// errNativeFailed is the error of a native invocation that failed. Its
+// message is in stderr, as with kubectl's exit status.
+var errNativeFailed = errors.New("native request failed")
+
+// nativeRequest is a kubectl invocation parsed for the native backend
+type nativeRequest struct {
+	kubeconfig    string
+	context       string
+	subcommand    string
+	args          []string
+	namespace     string
+	allNamespaces bool
+	selector      string
+	fieldSelector string
+	output        string
+	raw           string
+	showKind      bool
+	timeout       time.Duration
+}
+
+// nativeTarget is a resource type to get, with the names to get or none to
+// list them
+type nativeTarget struct {
+	resource string
+	names    []string
+}
+
+// checkNativeBackend validates --backend and, for the native backend, that
+// the command and its arguments can run without kubectl
+func checkNativeBackend(subcommand string, extraArgs []string) error {
+	if backend != backendKubectl && backend != backendNative {
+		return fmt.Errorf("invalid --backend %q: must be %s or %s", backend, backendKubectl, backendNative)
+	}
+	if backend == backendKubectl {
+		return nil
+	}
+	if checkVersionSkew {
+		return fmt.Errorf("--check-version-skew compares kubectl's version and cannot be used with --backend=%s", backendNative)
+	}
+	args := append(append(append([]string{}, kubectlArgs...), subcommand), extraArgs...)
+	if _, err := parseNativeArgs(args); err != nil {
+		return fmt.Errorf("--backend=%s: %w", backendNative, err)
+	}
+	return nil
+}
+
+// parseNativeArgs parses the kubectl argv of a context for the native
+// backend, which supports get and version and the flags that shape them
+func parseNativeArgs(args []string) (*nativeRequest, error) {
+	req := &nativeRequest{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if req.subcommand == "" {
+				req.subcommand = arg
+			} else {
+				req.args = append(req.args, arg)
+			}
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "-A", "--all-namespaces":
+			req.allNamespaces = !hasValue || value == "true"
+			continue
+		case "--show-kind":
+			req.showKind = !hasValue || value == "true"
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, fmt.Errorf("flag %s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "--kubeconfig":
+			req.kubeconfig = value
+		case "--context":
+			req.context = value
+		case "-n", "--namespace":
+			req.namespace = value
+		case "-l", "--selector":
+			req.selector = value
+		case "--field-selector":
+			req.fieldSelector = value
+		case "-o", "--output":
+			req.output = value
+		case "--raw":
+			req.raw = value
+		case "--request-timeout":
+			timeout, err := parseRequestTimeout(value)
+			if err != nil {
+				return nil, err
+			}
+			req.timeout = timeout
+		default:
+			return nil, fmt.Errorf("flag %s is not supported", name)
+		}
+	}
+
+	switch req.subcommand {
+	case "get":
+		if req.raw == "" && len(req.args) == 0 {
+			return nil, fmt.Errorf("get needs a resource type")
+		}
+		switch req.output {
+		case "", "wide", "json", "yaml", "name":
+		default:
+			return nil, fmt.Errorf("-o %s is not supported", req.output)
+		}
+	case "version":
+		switch req.output {
+		case "", "json", "yaml":
+		default:
+			return nil, fmt.Errorf("-o %s is not supported by version", req.output)
+		}
+	default:
+		return nil, fmt.Errorf("%s is not supported; only get and version are", req.subcommand)
+	}
+	return req, nil
+}
+
+// parseRequestTimeout parses a --request-timeout value, a duration such as
+// 30s or a number of seconds
+func parseRequestTimeout(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --request-timeout %q: %w", value, err)
+	}
+	return timeout, nil
+}
+
+// nativeExec runs a kubectl invocation with client-go instead of the kubectl
+// binary. It has the signature of kubectlExec and reports errors on stderr
+// the way kubectl does, so the rest of the tool can't tell the difference.
+func nativeExec(ctx context.Context, args []string) (string, string, error) {
+	req, err := parseNativeArgs(args)
+	if err != nil {
+		return "", fmt.Sprintf("error: %v\n", err), errNativeFailed
+	}
+	config, namespace, err := nativeConfig(req)
+	if err != nil {
+		return "", fmt.Sprintf("error: %v\n", err), errNativeFailed
+	}
+
+	if req.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.timeout)
+		defer cancel()
+	}
+	var stdout, stderr string
+	if req.subcommand == "version" {
+		stdout, err = nativeVersion(config, req.output)
+	} else {
+		stdout, stderr, err = nativeGet(ctx, config, namespace, req)
+	}
+	if err != nil {
+		return stdout, stderr + nativeErrorMessage(err), errNativeFailed
+	}
+	return stdout, stderr, nil
+}
+
+// nativeConfig returns the client configuration of the request's context and
+// its default namespace
+func nativeConfig(req *nativeRequest) (*rest.Config, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if req.kubeconfig != "" {
+		rules.ExplicitPath = req.kubeconfig
+	}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: req.context})
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", err
+	}
+	if req.namespace != "" {
+		namespace = req.namespace
+	}
+	config.QPS = nativeQPS
+	config.Burst = nativeBurst
+	config.Timeout = req.timeout
+	config.UserAgent = "kubectl-multi-context"
+	return config, namespace, nil
+}
+
+// nativeErrorMessage formats an error as kubectl prints it
+func nativeErrorMessage(err error) string {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return fmt.Sprintf("Error from server (%s): %s\n", status.Status().Reason, status.Status().Message)
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Sprintf("Unable to connect to the server: %v\n", urlErr.Err)
+	}
+	return fmt.Sprintf("error: %v\n", err)
+}
+
+// nativeVersion prints the server version as kubectl version does. There is
+// no client version without kubectl.
+func nativeVersion(config *rest.Config, output string) (string, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return "", err
+	}
+	info, err := client.ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	versions := map[string]any{"serverVersion": info}
+	switch output {
+	case "json":
+		data, err := json.MarshalIndent(versions, "", "  ")
+		return string(data) + "\n", err
+	case "yaml":
+		return marshalNativeYAML(versions)
+	default:
+		return fmt.Sprintf("Server Version: %s\n", info.GitVersion), nil
+	}
+}
+
+// nativeGet gets resources, printing a server-side rendered table by
+// default and the objects for -o json, yaml and name
+func nativeGet(ctx context.Context, config *rest.Config, namespace string, req *nativeRequest) (string, string, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return "", "", err
+	}
+	if req.raw != "" {
+		data, err := client.RESTClient().Get().AbsPath(req.raw).DoRaw(ctx)
+		return string(data), "", err
+	}
+
+	targets, err := nativeTargets(req.args, restmapper.NewDiscoveryCategoryExpander(client))
+	if err != nil {
+		return "", "", err
+	}
+	cached := memory.NewMemCacheClient(client)
+	mapper := restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cached), cached, nil)
+	mappings := make([]*meta.RESTMapping, len(targets))
+	for i, target := range targets {
+		if mappings[i], err = nativeMapping(mapper, target.resource); err != nil {
+			return "", "", err
+		}
+	}
+	if req.allNamespaces {
+		namespace = ""
+	}
+	namespaceFor := func(mapping *meta.RESTMapping) string {
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			return ""
+		}
+		return namespace
+	}
+
+	notFound := "No resources found\n"
+	if namespace != "" {
+		notFound = fmt.Sprintf("No resources found in %s namespace.\n", namespace)
+	}
+	if req.output == "" || req.output == "wide" {
+		showKind := req.showKind
+		for _, mapping := range mappings {
+			showKind = showKind || mapping.Resource != mappings[0].Resource
+		}
+		var tables []string
+		for i, target := range targets {
+			table, err := nativeTable(ctx, client.RESTClient(), mappings[i], namespaceFor(mappings[i]), target.names, req, showKind)
+			if err != nil {
+				return strings.Join(tables, "\n"), "", err
+			}
+			if table != "" {
+				tables = append(tables, table)
+			}
+		}
+		if len(tables) == 0 {
+			return "", notFound, nil
+		}
+		return strings.Join(tables, "\n"), "", nil
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return "", "", err
+	}
+	var items []unstructured.Unstructured
+	for i, target := range targets {
+		resource := dynamicClient.Resource(mappings[i].Resource).Namespace(namespaceFor(mappings[i]))
+		if len(target.names) == 0 {
+			list, err := resource.List(ctx, metav1.ListOptions{LabelSelector: req.selector, FieldSelector: req.fieldSelector})
+			if err != nil {
+				return "", "", err
+			}
+			items = append(items, list.Items...)
+			continue
+		}
+		for _, name := range target.names {
+			object, err := resource.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return "", "", err
+			}
+			items = append(items, *object)
+		}
+	}
+	single := len(targets) == 1 && len(targets[0].names) == 1
+	if len(items) == 0 && !single && req.output != "json" && req.output != "yaml" {
+		return "", notFound, nil
+	}
+	output, err := printNativeObjects(items, single, req.output)
+	return output, "", err
+}
+
+// nativeTargets parses the resource arguments of get: TYPE[,TYPE...] [NAME...]
+// or TYPE/NAME..., with categories such as all expanded to their resources
+func nativeTargets(args []string, categories restmapper.CategoryExpander) ([]nativeTarget, error) {
+	if strings.Contains(args[0], "/") {
+		var targets []nativeTarget
+		for _, arg := range args {
+			resource, name, ok := strings.Cut(arg, "/")
+			if !ok || resource == "" || name == "" {
+				return nil, fmt.Errorf("there is no need to specify a resource type as a separate argument when passing arguments in resource/name form")
+			}
+			targets = append(targets, nativeTarget{resource: resource, names: []string{name}})
+		}
+		return targets, nil
+	}
+
+	var targets []nativeTarget
+	for _, resource := range strings.Split(args[0], ",") {
+		if expanded, ok := categories.Expand(resource); ok {
+			for _, groupResource := range expanded {
+				targets = append(targets, nativeTarget{resource: groupResource.String()})
+			}
+			continue
+		}
+		targets = append(targets, nativeTarget{resource: resource})
+	}
+	if len(args) > 1 {
+		if len(targets) > 1 {
+			return nil, fmt.Errorf("names can only be given for a single resource type")
+		}
+		targets[0].names = args[1:]
+	}
+	return targets, nil
+}
+
+// nativeMapping resolves a resource argument such as po, deployments.apps or
+// deployments.v1.apps
+func nativeMapping(mapper meta.RESTMapper, resource string) (*meta.RESTMapping, error) {
+	fullySpecified, groupResource := schema.ParseResourceArg(strings.ToLower(resource))
+	partial := groupResource.WithVersion("")
+	if fullySpecified != nil {
+		if gvr, err := mapper.ResourceFor(*fullySpecified); err == nil {
+			partial = gvr
+		}
+	}
+	gvr, err := mapper.ResourceFor(partial)
+	if err != nil {
+		return nil, fmt.Errorf("the server doesn't have a resource type %q", resource)
+	}
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// nativeTable gets a resource as a table rendered by the server and prints
+// it like kubectl, with a NAMESPACE column for --all-namespaces and names
+// prefixed with their kind when several types are printed
+func nativeTable(ctx context.Context, client rest.Interface, mapping *meta.RESTMapping, namespace string, names []string, req *nativeRequest, showKind bool) (string, error) {
+	if len(names) == 0 {
+		names = []string{""}
+	}
+	var tables []metav1.Table
+	for _, name := range names {
+		request := client.Get().AbsPath(nativePath(mapping, namespace, name)...).
+			SetHeader("Accept", nativeTableAccept).
+			Param("includeObject", "Metadata")
+		if name == "" {
+			if req.selector != "" {
+				request = request.Param("labelSelector", req.selector)
+			}
+			if req.fieldSelector != "" {
+				request = request.Param("fieldSelector", req.fieldSelector)
+			}
+		}
+		data, err := request.DoRaw(ctx)
+		if err != nil {
+			// The table request has no decoder for the Status of a failure
+			var status metav1.Status
+			if json.Unmarshal(data, &status) == nil && status.Kind == "Status" {
+				return "", &apierrors.StatusError{ErrStatus: status}
+			}
+			return "", err
+		}
+		var table metav1.Table
+		if err := json.Unmarshal(data, &table); err != nil {
+			return "", fmt.Errorf("failed to parse the table of %s: %w", mapping.Resource.Resource, err)
+		}
+		tables = append(tables, table)
+	}
+
+	var rows [][]string
+	var visible []int
+	for _, table := range tables {
+		if visible == nil {
+			header := []string{}
+			if req.allNamespaces && mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+				header = append(header, "NAMESPACE")
+			}
+			for i, column := range table.ColumnDefinitions {
+				if column.Priority == 0 || req.output == "wide" {
+					visible = append(visible, i)
+					header = append(header, strings.ToUpper(column.Name))
+				}
+			}
+			rows = append(rows, header)
+		}
+		for _, row := range table.Rows {
+			var cells []string
+			if req.allNamespaces && mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+				cells = append(cells, rowNamespace(row))
+			}
+			for _, i := range visible {
+				cell := "<none>"
+				if i < len(row.Cells) && row.Cells[i] != nil {
+					cell = fmt.Sprint(row.Cells[i])
+				}
+				if showKind && table.ColumnDefinitions[i].Name == "Name" {
+					cell = kindPrefix(mapping.GroupVersionKind.GroupKind()) + "/" + cell
+				}
+				cells = append(cells, cell)
+			}
+			rows = append(rows, cells)
+		}
+	}
+	if len(rows) < 2 {
+		return "", nil
+	}
+
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 6, 4, 3, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	return b.String(), nil
+}
+
+// nativePath returns the API path of a resource, a namespace's resources or
+// a named object
+func nativePath(mapping *meta.RESTMapping, namespace, name string) []string {
+	gvr := mapping.Resource
+	path := []string{"/apis", gvr.Group, gvr.Version}
+	if gvr.Group == "" {
+		path = []string{"/api", gvr.Version}
+	}
+	if namespace != "" {
+		path = append(path, "namespaces", namespace)
+	}
+	path = append(path, gvr.Resource)
+	if name != "" {
+		path = append(path, name)
+	}
+	return path
+}
+
+// rowNamespace returns the namespace of a table row's object metadata
+func rowNamespace(row metav1.TableRow) string {
+	var object metav1.PartialObjectMetadata
+	if err := json.Unmarshal(row.Object.Raw, &object); err != nil {
+		return ""
+	}
+	return object.Namespace
+}
+
+// kindPrefix returns how kubectl names a kind in output, such as pod or
+// deployment.apps
+func kindPrefix(groupKind schema.GroupKind) string {
+	prefix := strings.ToLower(groupKind.Kind)
+	if groupKind.Group != "" {
+		prefix += "." + groupKind.Group
+	}
+	return prefix
+}
+
+// printNativeObjects prints the objects for -o json, yaml or name, as a List
+// unless a single object was asked for by name
+func printNativeObjects(items []unstructured.Unstructured, single bool, output string) (string, error) {
+	if output == "name" {
+		var b strings.Builder
+		for _, item := range items {
+			fmt.Fprintf(&b, "%s/%s\n", kindPrefix(item.GroupVersionKind().GroupKind()), item.GetName())
+		}
+		return b.String(), nil
+	}
+
+	var data any
+	if single && len(items) == 1 {
+		data = items[0].Object
+	} else {
+		objects := make([]any, len(items))
+		for i, item := range items {
+			objects[i] = item.Object
+		}
+		data = map[string]any{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      objects,
+			"metadata":   map[string]any{"resourceVersion": ""},
+		}
+	}
+	if output == "yaml" {
+		return marshalNativeYAML(data)
+	}
+	encoded, err := json.MarshalIndent(data, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(encoded) + "\n", nil
+}
+
+// marshalNativeYAML prints a value as YAML by way of its JSON form, so API
+// types use their JSON field names
+func marshalNativeYAML(value any) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	var data any
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
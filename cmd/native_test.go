@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeAPIServer serves discovery for pods and namespaces, a pods table and
+// list in the default namespace, and the server version
+func fakeAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	responses := map[string]string{
+		"/version": `{"major": "1", "minor": "29", "gitVersion": "v1.29.2"}`,
+		"/api":     `{"kind": "APIVersions", "versions": ["v1"]}`,
+		"/apis":    `{"kind": "APIGroupList", "groups": []}`,
+		"/api/v1": `{"kind": "APIResourceList", "groupVersion": "v1", "resources": [
+			{"name": "pods", "singularName": "pod", "namespaced": true, "kind": "Pod", "verbs": ["get", "list"], "shortNames": ["po"]},
+			{"name": "namespaces", "singularName": "namespace", "namespaced": false, "kind": "Namespace", "verbs": ["get", "list"], "shortNames": ["ns"]}]}`,
+		"/api/v1/namespaces/default/pods": `{"kind": "PodList", "apiVersion": "v1", "items": [
+			{"metadata": {"name": "web-0", "namespace": "default"}}]}`,
+		"/api/v1/pods": `{"kind": "PodList", "apiVersion": "v1", "items": [
+			{"metadata": {"name": "web-0", "namespace": "default"}}]}`,
+		"/api/v1/namespaces/default/pods/missing": `{"kind": "Status", "apiVersion": "v1", "status": "Failure",
+			"reason": "NotFound", "message": "pods \"missing\" not found", "code": 404}`,
+	}
+	table := `{"kind": "Table", "apiVersion": "meta.k8s.io/v1",
+		"columnDefinitions": [{"name": "Name", "type": "string"}, {"name": "Status", "type": "string"}, {"name": "IP", "type": "string", "priority": 1}],
+		"rows": [{"cells": ["web-0", "Running", "10.0.0.1"], "object": {"metadata": {"name": "web-0", "namespace": "default"}}}]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.Header.Get("Accept"), "as=Table") && strings.HasSuffix(r.URL.Path, "/pods") {
+			body = table
+		}
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeNativeKubeconfig points a kubeconfig context at the fake API server
+func writeNativeKubeconfig(t *testing.T, server string) {
+	t.Helper()
+	path := writeKubeconfigContent(t, fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: fake
+  cluster:
+    server: %s
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: ctx1
+  context:
+    cluster: fake
+    user: admin
+`, server))
+	t.Setenv("KUBECONFIG", path)
+}
+
+func TestParseNativeArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected nativeRequest
+		errorMsg string
+	}{
+		{
+			name:     "get with flags",
+			args:     []string{"--context", "ctx1", "get", "pods", "-n", "apps", "-l", "app=web", "-o", "json", "--request-timeout=30"},
+			expected: nativeRequest{context: "ctx1", subcommand: "get", args: []string{"pods"}, namespace: "apps", selector: "app=web", output: "json", timeout: 30_000_000_000},
+		},
+		{
+			name:     "all namespaces and show kind",
+			args:     []string{"get", "pods,svc", "-A", "--show-kind"},
+			expected: nativeRequest{subcommand: "get", args: []string{"pods,svc"}, allNamespaces: true, showKind: true},
+		},
+		{
+			name:     "raw",
+			args:     []string{"get", "--raw", "/readyz"},
+			expected: nativeRequest{subcommand: "get", raw: "/readyz"},
+		},
+		{name: "unsupported subcommand", args: []string{"logs", "web-0"}, errorMsg: "logs is not supported"},
+		{name: "unsupported flag", args: []string{"get", "pods", "--sort-by", ".metadata.name"}, errorMsg: "flag --sort-by is not supported"},
+		{name: "unsupported output", args: []string{"get", "pods", "-o", "jsonpath={.items}"}, errorMsg: "-o jsonpath={.items} is not supported"},
+		{name: "missing resource", args: []string{"get"}, errorMsg: "get needs a resource type"},
+		{name: "missing value", args: []string{"get", "pods", "-n"}, errorMsg: "flag -n needs a value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := parseNativeArgs(tt.args)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("parseNativeArgs() error = %v, want %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseNativeArgs() error = %v", err)
+			}
+			if fmt.Sprintf("%+v", *req) != fmt.Sprintf("%+v", tt.expected) {
+				t.Errorf("parseNativeArgs() = %+v, want %+v", *req, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNativeExec(t *testing.T) {
+	writeNativeKubeconfig(t, fakeAPIServer(t).URL)
+
+	tests := []struct {
+		name       string
+		args       []string
+		expected   string
+		wantStderr string
+	}{
+		{name: "table", args: []string{"get", "pods"}, expected: "NAME    STATUS\nweb-0   Running\n"},
+		{name: "wide table", args: []string{"get", "po", "-o", "wide"}, expected: "NAME    STATUS    IP\nweb-0   Running   10.0.0.1\n"},
+		{name: "all namespaces", args: []string{"get", "pods", "-A"}, expected: "NAMESPACE   NAME    STATUS\ndefault     web-0   Running\n"},
+		{name: "name", args: []string{"get", "pods", "-o", "name"}, expected: "pod/web-0\n"},
+		{name: "version", args: []string{"version"}, expected: "Server Version: v1.29.2\n"},
+		{name: "not found", args: []string{"get", "pods", "missing"}, wantStderr: `Error from server (NotFound): pods "missing" not found`},
+		{name: "unknown resource", args: []string{"get", "widgets"}, wantStderr: `the server doesn't have a resource type "widgets"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, err := nativeExec(context.Background(), append([]string{"--context", "ctx1"}, tt.args...))
+			if tt.wantStderr != "" {
+				if err == nil || !strings.Contains(stderr, tt.wantStderr) {
+					t.Errorf("nativeExec() err = %v, stderr = %q, want %q", err, stderr, tt.wantStderr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("nativeExec() error = %v, stderr = %q", err, stderr)
+			}
+			if stdout != tt.expected {
+				t.Errorf("nativeExec() = %q, want %q", stdout, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNativeExecJSON(t *testing.T) {
+	writeNativeKubeconfig(t, fakeAPIServer(t).URL)
+
+	stdout, stderr, err := nativeExec(context.Background(), []string{"--context", "ctx1", "get", "pods", "-o", "json"})
+	if err != nil {
+		t.Fatalf("nativeExec() error = %v, stderr = %q", err, stderr)
+	}
+	var list struct {
+		Kind  string `json:"kind"`
+		Items []struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &list); err != nil {
+		t.Fatalf("nativeExec() printed invalid JSON: %v\n%s", err, stdout)
+	}
+	if list.Kind != "List" || len(list.Items) != 1 || list.Items[0].Kind != "Pod" || list.Items[0].Metadata.Name != "web-0" {
+		t.Errorf("nativeExec() = %s, want a List of pod web-0", stdout)
+	}
+}
+
+func TestRunCommandNativeBackend(t *testing.T) {
+	writeNativeKubeconfig(t, fakeAPIServer(t).URL)
+	setGlobal(t, &backend, backendNative)
+	setGlobal(t, &kubectlExec, kubectlExec)
+	before := fmt.Sprintf("%p", kubectlExec)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "table", args: []string{"pods"}, expected: "CONTEXT  NAME    STATUS\nctx1     web-0   Running\n"},
+		{name: "csv is rewritten to a table", args: []string{"pods", "-o", "csv"}, expected: "CONTEXT,NAME,STATUS\nctx1,web-0,Running\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureStdout(t, func() {
+				if err := runCommand("get", tt.args); err != nil {
+					t.Errorf("runCommand() error = %v", err)
+				}
+			})
+			if output != tt.expected {
+				t.Errorf("runCommand() output = %q, want %q", output, tt.expected)
+			}
+			if fmt.Sprintf("%p", kubectlExec) != before {
+				t.Errorf("kubectlExec was not restored after the native run")
+			}
+		})
+	}
+}
+
+func TestRunCommandNativeBackendInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		backend    string
+		subcommand string
+		args       []string
+		errorMsg   string
+	}{
+		{name: "unknown backend", backend: "grpc", subcommand: "get", args: []string{"pods"}, errorMsg: `invalid --backend "grpc"`},
+		{name: "unsupported subcommand", backend: backendNative, subcommand: "top", args: []string{"nodes"}, errorMsg: "--backend=native: top is not supported"},
+		{name: "unsupported output", backend: backendNative, subcommand: "get", args: []string{"pods", "-o", "custom-columns=NAME:.metadata.name"}, errorMsg: "is not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGlobal(t, &backend, tt.backend)
+			err := runCommand(tt.subcommand, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("runCommand() error = %v, want %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
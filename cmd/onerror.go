@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// Policies for --on-error
+const (
+	onErrorContinue = "continue"
+	onErrorStop     = "stop"
+	onErrorPrompt   = "prompt"
+)
+
+// promptInput is where --on-error=prompt reads answers from. It is a variable
+// so tests can script the answers.
+var promptInput io.Reader = os.Stdin
+
+var (
+	promptMu     sync.Mutex
+	promptReader *bufio.Reader
+	promptSource io.Reader
+)
+
+// promptAnswers returns the process-wide buffered reader of promptInput.
+// Every prompt reads through it, so what one prompt buffered ahead is not
+// lost to the next.
+func promptAnswers() *bufio.Reader {
+	promptMu.Lock()
+	defer promptMu.Unlock()
+	if promptReader == nil || promptSource != promptInput {
+		promptReader, promptSource = bufio.NewReader(promptInput), promptInput
+	}
+	return promptReader
+}
+
+// stdinIsTerminal reports whether an operator can answer prompts. It is a
+// variable so tests can simulate a terminal.
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// errorPolicy applies --on-error to a run: after a context fails, the
+// contexts that have not started yet are skipped with stop, or when the
+// operator declines to continue with prompt
+type errorPolicy struct {
+	mu      sync.Mutex
+	policy  string
+	stopped string // the context whose failure stopped the run
+	answers *bufio.Reader
+}
+
+func newErrorPolicy() *errorPolicy {
+	policy := onError
+	if policy == onErrorPrompt && !stdinIsTerminal() {
+		policy = onErrorContinue
+	}
+	p := &errorPolicy{policy: policy}
+	if policy == onErrorPrompt {
+		p.answers = promptAnswers()
+	}
+	return p
+}
+
+// skip returns the error to report for a context that must not start
+// because the run was stopped, or nil. It waits for a pending prompt.
+func (p *errorPolicy) skip() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped == "" {
+		return nil
+	}
+	return fmt.Errorf("skipped after context %s failed (--on-error=%s)", p.stopped, p.policy)
+}
+
+// failed applies the policy to a failed context's result
+func (p *errorPolicy) failed(result contextResult) {
+	if result.err == nil || p.policy == onErrorContinue {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped != "" {
+		return
+	}
+	if p.policy == onErrorPrompt {
+		_, message := parseKubectlError(result.output, result.err)
+		fmt.Fprintf(os.Stderr, "Context %s failed: %s\nContinue with the remaining contexts? [y/N] ", result.context, message)
+		answer, _ := p.answers.ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "y" || answer == "yes" {
+			return
+		}
+	}
+	p.stopped = result.context
+	notef("Stopping after context %s failed; contexts that have not started are skipped", result.context)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRunContextsOnError(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		terminal bool
+		answers  string
+		wantRan  []string
+		wantNote string
+	}{
+		{name: "continue", policy: onErrorContinue, wantRan: []string{"ctx1", "ctx2", "ctx3"}},
+		{name: "stop", policy: onErrorStop, wantRan: []string{"ctx1", "ctx2"}, wantNote: "Stopping after context ctx2 failed"},
+		{name: "prompt declined", policy: onErrorPrompt, terminal: true, answers: "n\n", wantRan: []string{"ctx1", "ctx2"}, wantNote: "Continue with the remaining contexts? [y/N]"},
+		{name: "prompt accepted", policy: onErrorPrompt, terminal: true, answers: "y\n", wantRan: []string{"ctx1", "ctx2", "ctx3"}, wantNote: "Context ctx2 failed: error: forbidden"},
+		{name: "prompt without terminal", policy: onErrorPrompt, wantRan: []string{"ctx1", "ctx2", "ctx3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGlobal(t, &onError, tt.policy)
+			setGlobal(t, &batchSize, 1)
+			setGlobal(t, &stdinIsTerminal, func() bool { return tt.terminal })
+			setGlobal[io.Reader](t, &promptInput, strings.NewReader(tt.answers))
+			var ran []string
+			fakeKubectl(t, func(args []string) (string, error) {
+				context := argValue(args, "--context")
+				ran = append(ran, context)
+				if context == "ctx2" {
+					return "error: forbidden", fmt.Errorf("exit status 1")
+				}
+				return "NAME\npod1\n", nil
+			})
+
+			var results []contextResult
+			stderr := captureStderr(t, func() {
+				results = runContexts([]string{"ctx1", "ctx2", "ctx3"}, "get", []string{"pods"})
+			})
+
+			if strings.Join(ran, ",") != strings.Join(tt.wantRan, ",") {
+				t.Errorf("ran %v, want %v", ran, tt.wantRan)
+			}
+			if !strings.Contains(stderr, tt.wantNote) {
+				t.Errorf("stderr = %q, want %q", stderr, tt.wantNote)
+			}
+			if len(tt.wantRan) < 3 {
+				if results[2].err == nil || !strings.Contains(results[2].err.Error(), "skipped after context ctx2 failed") {
+					t.Errorf("ctx3 error = %v, want skipped", results[2].err)
+				}
+			}
+		})
+	}
+}
+
+func TestRunCommandOnErrorInvalid(t *testing.T) {
+	setGlobal(t, &onError, "retry")
+	if err := runCommand("get", []string{"pods"}); err == nil || !strings.Contains(err.Error(), "invalid --on-error") {
+		t.Errorf("runCommand() error = %v, want invalid --on-error", err)
+	}
+}
+
+func TestErrorPolicySharesPromptInput(t *testing.T) {
+	setGlobal(t, &onError, onErrorPrompt)
+	setGlobal(t, &stdinIsTerminal, func() bool { return true })
+	setGlobal[io.Reader](t, &promptInput, strings.NewReader("n\ny\n"))
+	failed := contextResult{context: "ctx1", output: "error: forbidden", err: fmt.Errorf("exit status 1")}
+
+	captureStderr(t, func() {
+		first, second := newErrorPolicy(), newErrorPolicy()
+		first.failed(failed)
+		second.failed(failed)
+		if first.skip() == nil {
+			t.Errorf("first run continued, want it stopped by the first answer")
+		}
+		if err := second.skip(); err != nil {
+			t.Errorf("second run stopped (%v), want it continued by the second answer", err)
+		}
+	})
+}
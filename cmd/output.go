@@ -4,8 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/term"
 	"gopkg.in/yaml.v3"
@@ -17,8 +22,43 @@ const (
 	formatDefault outputFormat = "default"
 	formatJSON    outputFormat = "json"
 	formatYAML    outputFormat = "yaml"
+
+	formatCustomColumns outputFormat = "custom-columns"
+	formatPrometheus    outputFormat = "prometheus"
+	formatWide          outputFormat = "wide"
+	formatNDJSONErrors  outputFormat = "ndjson-with-errors"
+	formatJSONPath      outputFormat = "jsonpath"
+	formatCSV           outputFormat = "csv"
+	formatTSV           outputFormat = "tsv"
+	// formatContextColumns is custom-columns with a CONTEXT pseudo column,
+	// set by runIteration rather than detected from the arguments
+	formatContextColumns outputFormat = "custom-columns-with-context"
+	// formatKinds is get output with kind-prefixed names, set by runIteration
+	formatKinds outputFormat = "kinds"
+)
+
+// Table styles for the default output
+const (
+	tableStylePlain = "plain"
+	tableStyleBox   = "box"
 )
 
+// Where JSON and YAML output record the context of each item
+const (
+	placementMetadata   = "metadata"
+	placementAnnotation = "annotation"
+	placementSibling    = "sibling"
+
+	contextAnnotation     = "multi/context"
+	sourceAnnotation      = "multi/source-kubeconfig"
+	warningsAnnotation    = "multi/warnings"
+	versionSkewAnnotation = "multi/version-skew"
+)
+
+// contextGap separates the context column from the kubectl output in every
+// text table, so tables of different commands line up the same way
+const contextGap = "  "
+
 // ANSI color codes for terminal output
 const (
 	colorReset  = "\033[0m"
@@ -32,6 +72,13 @@ const (
 	colorGray   = "\033[90m"
 )
 
+// Modes for --color-by-context
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
 // Color palette for context names - using bright colors for better visibility
 var contextColors = []string{
 	"\033[91m", // Bright Red
@@ -54,10 +101,36 @@ func isTerminal() bool {
 	return term.IsTerminal(int(os.Stdout.Fd()))
 }
 
+// terminalWidth returns the width of the terminal on stdout, or 0 when it is
+// unknown. It is a variable so tests can simulate a terminal.
+var terminalWidth = func() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// parseMaxColWidth parses --max-col-width, either a number of characters or
+// "auto" to fit the table to the terminal width
+func parseMaxColWidth(value string) (width int, auto bool, err error) {
+	if value == "" {
+		return 0, false, nil
+	}
+	if value == "auto" {
+		return 0, true, nil
+	}
+	width, err = strconv.Atoi(value)
+	if err != nil || width < 2 {
+		return 0, false, fmt.Errorf("invalid --max-col-width %q: must be auto or a number of at least 2", value)
+	}
+	return width, false, nil
+}
+
 // getContextColor returns a consistent color for a given context name
 func getContextColor(context string) string {
-	if !isTerminal() {
-		return "" // No colors when piping to files
+	if colorByContext == colorNever || (colorByContext != colorAlways && !isTerminal()) {
+		return "" // No colors when piping to files, unless asked for
 	}
 
 	// Use hash of context name to consistently assign colors
@@ -88,27 +161,229 @@ func detectOutputFormat(args []string) outputFormat {
 				if format == "yaml" {
 					return formatYAML
 				}
+				if format == "prometheus" {
+					return formatPrometheus
+				}
+				if format == "wide" {
+					return formatWide
+				}
+				if format == "csv" || format == "tsv" {
+					return outputFormat(format)
+				}
+				if format == string(formatNDJSONErrors) {
+					return formatNDJSONErrors
+				}
+				if isCustomColumns(format) {
+					return formatCustomColumns
+				}
+				if isJSONPath(format) {
+					return formatJSONPath
+				}
+			}
+		}
+		for _, prefix := range []string{"-o=", "--output=", "-o"} {
+			if value, ok := strings.CutPrefix(arg, prefix); ok && isCustomColumns(value) {
+				return formatCustomColumns
+			} else if ok && isJSONPath(value) {
+				return formatJSONPath
+			} else if ok && strings.ToLower(value) == "wide" {
+				return formatWide
+			} else if value := strings.ToLower(value); ok && (value == "csv" || value == "tsv") {
+				return outputFormat(value)
 			}
 		}
 	}
 	return formatDefault
 }
 
+// isJSONPath reports whether an output format value is a jsonpath template
+func isJSONPath(format string) bool {
+	return strings.HasPrefix(format, "jsonpath=") || strings.HasPrefix(format, "jsonpath-file=") ||
+		strings.HasPrefix(format, "jsonpath-as-json=")
+}
+
+// isCustomColumns reports whether an output format value is a custom-columns spec
+func isCustomColumns(format string) bool {
+	return strings.HasPrefix(format, "custom-columns=") || strings.HasPrefix(format, "custom-columns-file=")
+}
+
 func formatOutput(results []contextResult, format outputFormat, subcommand string) error {
+	if failuresOnly {
+		return formatFailuresOnly(results)
+	}
+	if prettyErrors {
+		defer printErrorTable(results)
+	}
+	if showWarnings && format != formatJSON && format != formatYAML {
+		defer printWarnings(results)
+	}
+	if aggregateStatus {
+		return formatStatusSummary(results)
+	}
+	if resourceSummary {
+		return formatResourceSummary(results)
+	}
+	if summarize {
+		return formatCountSummary(results)
+	}
+	if hashResults {
+		return formatResultHashes(results, format)
+	}
+	if timeline {
+		return formatEventTimeline(results)
+	}
+	if rawKubectl {
+		return formatRawOutput(results)
+	}
+
 	switch format {
 	case formatJSON:
 		return formatJSONOutput(results, subcommand)
 	case formatYAML:
 		return formatYAMLOutput(results, subcommand)
+	case formatCustomColumns:
+		return formatCustomColumnsOutput(results)
+	case formatContextColumns:
+		return formatContextColumnsOutput(results)
+	case formatKinds:
+		return formatKindSections(results)
+	case formatWide:
+		return formatWideOutput(results)
+	case formatPrometheus:
+		return formatPrometheusOutput(results)
+	case formatJSONPath:
+		return formatJSONPathOutput(results)
+	case formatCSV:
+		return formatDelimitedOutput(results, ',')
+	case formatTSV:
+		return formatDelimitedOutput(results, '\t')
 	default:
 		if subcommand == "version" {
+			if summaryOnly {
+				return formatVersionDigest(results)
+			}
 			return formatVersionOutput(results)
 		}
+		if subcommand == "kustomize" {
+			return formatSectionOutput(results)
+		}
+		if subcommand == "logs" || subcommand == "exec" {
+			return formatLogOutput(results)
+		}
+		if subcommand == "describe" {
+			return formatDescribeOutput(results)
+		}
 		return formatDefaultOutput(results)
 	}
 }
 
+// formatSectionOutput prints each context's output, such as multi-document
+// YAML that isn't a List, as its own document section headed by a
+// "# === context: X ===" comment
+func formatSectionOutput(results []contextResult) error {
+	first := true
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		output := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(result.output), "---"))
+		if output == "" {
+			continue
+		}
+		if !first {
+			fmt.Println("---")
+		}
+		first = false
+		fmt.Printf("# === context: %s ===\n%s\n", result.context, output)
+	}
+	return nil
+}
+
+// formatRawOutput prints every line of each context's output untouched,
+// prefixed with "[context] "
+func formatRawOutput(results []contextResult) error {
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		output := strings.TrimRight(result.output, "\n")
+		if output == "" {
+			continue
+		}
+		prefix := fmt.Sprintf("[%s] ", colorizeContext(result.context))
+		for _, line := range strings.Split(output, "\n") {
+			fmt.Println(prefix + line)
+		}
+	}
+	return nil
+}
+
+// parseTableOptions validates the flags that shape default table output and
+// returns the --max-col-width setting
+func parseTableOptions() (maxWidth int, autoWidth bool, err error) {
+	if durationFormat != durationKube && durationFormat != durationHours && durationFormat != durationHuman {
+		return 0, false, fmt.Errorf("invalid --duration-format %q: must be %s, %s or %s", durationFormat, durationKube, durationHours, durationHuman)
+	}
+	if tableStyle != tableStylePlain && tableStyle != tableStyleBox {
+		return 0, false, fmt.Errorf("invalid --table-style %q: must be %s or %s", tableStyle, tableStylePlain, tableStyleBox)
+	}
+	if groupBy != groupByContext && groupBy != groupByNamespace {
+		return 0, false, fmt.Errorf("invalid --group-by %q: must be %s or %s", groupBy, groupByContext, groupByNamespace)
+	}
+	return parseMaxColWidth(maxColWidth)
+}
+
+// formatTableLines rewrites the AGE column and ellipsizes long cells of a
+// context's table lines as requested by the table flags
+func formatTableLines(lines []string, maxWidth int, autoWidth bool) []string {
+	if absoluteAges {
+		lines = rewriteAgeColumn(lines, absoluteAge)
+	} else if durationFormat != durationKube {
+		lines = rewriteAgeColumn(lines, formatAge)
+	}
+	if autoWidth && len(lines) > 1 {
+		// Share the terminal between the CONTEXT column and the table's columns
+		maxWidth = terminalWidth() / (len(parseHeader(lines[0])) + 1)
+	}
+	if maxWidth > 0 {
+		lines = truncateColumns(lines, maxWidth)
+	}
+	return lines
+}
+
 func formatDefaultOutput(results []contextResult) error {
+	maxWidth, autoWidth, err := parseTableOptions()
+	if err != nil {
+		return err
+	}
+	if sections := splitTableSections(results); sections != nil {
+		// Output such as get all is merged into one table per section
+		for i, section := range sections {
+			if i > 0 {
+				fmt.Println()
+			}
+			if err := formatDefaultOutput(section.results); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if groupBy == groupByNamespace {
+		if sections := splitNamespaceSections(results); sections != nil {
+			for i, section := range sections {
+				if i > 0 {
+					fmt.Println()
+				}
+				if err := formatDefaultOutput(section.results); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
 	// First pass: collect all contexts and their outputs to determine max context width
 	type outputData struct {
 		context string
@@ -117,7 +392,7 @@ func formatDefaultOutput(results []contextResult) error {
 		errMsg  string
 	}
 	var allOutputs []outputData
-	maxContextWidth := len("CONTEXT")
+	maxContextWidth := utf8.RuneCountInString(contextHeader)
 
 	for _, result := range results {
 		if result.err != nil {
@@ -141,6 +416,7 @@ func formatDefaultOutput(results []contextResult) error {
 		if len(lines) == 0 {
 			continue
 		}
+		lines = formatTableLines(lines, maxWidth, autoWidth)
 
 		if len(result.context) > maxContextWidth {
 			maxContextWidth = len(result.context)
@@ -152,31 +428,76 @@ func formatDefaultOutput(results []contextResult) error {
 		})
 	}
 
-	// Find the header from the first valid output
+	// Find the header from the --header-from context, or the first valid output
 	var headerLine string
 	var headerFound bool
 	for _, data := range allOutputs {
+		if headerFrom != "" && data.context != headerFrom {
+			continue
+		}
 		if data.err == nil && len(data.lines) > 1 {
 			headerLine = data.lines[0]
 			headerFound = true
 			break
 		}
 	}
+	if headerFrom != "" && !headerFound {
+		return fmt.Errorf("--header-from context %q produced no table output", headerFrom)
+	}
+
+	// kubectl aligns each context's table to its own values, so tables with
+	// the same columns are realigned together
+	if headerFound && tableStyle != tableStyleBox {
+		tables := make([][]string, len(allOutputs))
+		for i, data := range allOutputs {
+			tables[i] = data.lines
+		}
+		headerLine = alignTables(tables, headerLine)
+		for i := range allOutputs {
+			allOutputs[i].lines = tables[i]
+		}
+	}
+	if headerFound && sortColumn != "" {
+		tables := make([][]string, len(allOutputs))
+		for i, data := range allOutputs {
+			tables[i] = data.lines
+		}
+		if rows := sortRows(tables, headerLine); rows != nil {
+			// Each row becomes its own output, followed by the errors and
+			// the tables with other columns
+			var sorted []outputData
+			included := make(map[int]bool)
+			for _, row := range rows {
+				included[row.table] = true
+				sorted = append(sorted, outputData{
+					context: allOutputs[row.table].context,
+					lines:   []string{tables[row.table][0], tables[row.table][row.line]},
+				})
+			}
+			for i, data := range allOutputs {
+				if !included[i] {
+					sorted = append(sorted, data)
+				}
+			}
+			allOutputs = sorted
+		}
+	}
 
 	// Print header if found
+	var boxRows [][]string
 	if headerFound {
-		contextPadding := strings.Repeat(" ", maxContextWidth-len("CONTEXT"))
-		fmt.Printf("%s%s  %s\n", "CONTEXT", contextPadding, headerLine)
+		if tableStyle == tableStyleBox {
+			boxRows = append(boxRows, append([]string{contextHeader}, columnNames(parseHeader(headerLine))...))
+		} else {
+			contextPadding := strings.Repeat(" ", maxContextWidth-utf8.RuneCountInString(contextHeader))
+			fmt.Printf("%s%s"+contextGap+"%s\n", contextHeader, contextPadding, headerLine)
+		}
 	}
 
 	// Print all outputs
 	for _, data := range allOutputs {
 		if data.err != nil {
-			coloredContext := colorizeContext(data.context)
-			fmt.Fprintf(os.Stderr, "Context %s: Error: %v\n", coloredContext, data.err)
-			if data.errMsg != "" {
-				fmt.Fprintf(os.Stderr, "Output: %s\n", data.errMsg)
-			}
+			printContextError(data.context, data.err, data.errMsg)
 			continue
 		}
 
@@ -193,13 +514,214 @@ func formatDefaultOutput(results []contextResult) error {
 			if line == "" {
 				continue
 			}
-			fmt.Printf("%s%s  %s\n", coloredContext, contextPadding, line)
+			if tableStyle == tableStyleBox {
+				cells := []string{line}
+				if startIdx == 1 {
+					cells = splitRow(parseHeader(data.lines[0]), line)
+				}
+				boxRows = append(boxRows, append([]string{data.context}, cells...))
+				continue
+			}
+			fmt.Printf("%s%s"+contextGap+"%s\n", coloredContext, contextPadding, line)
+		}
+	}
+
+	if tableStyle == tableStyleBox && len(boxRows) > 0 {
+		for _, line := range renderBoxTable(boxRows, headerFound) {
+			fmt.Println(line)
 		}
 	}
 
 	return nil
 }
 
+// formatCustomColumnsOutput merges -o custom-columns tables. The column schema
+// is pinned from each context's header, which is identical for a given spec,
+// and empty cells are filled with <none> so the merged table stays rectangular.
+// kubectl sizes the columns of each context to its own values, so all tables
+// are rendered together to line up across contexts.
+func formatCustomColumnsOutput(results []contextResult) error {
+	var header []string
+	rows := make([][][]string, len(results))
+	for i, result := range results {
+		lines := strings.Split(strings.TrimSpace(result.output), "\n")
+		if result.err != nil || len(lines) < 2 {
+			continue
+		}
+		columns := parseHeader(lines[0])
+		if header == nil {
+			header = columnNames(columns)
+		}
+		for _, line := range lines[1:] {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			cells := splitRow(columns, line)
+			for j := range cells {
+				if cells[j] == "" {
+					cells[j] = "<none>"
+				}
+			}
+			rows[i] = append(rows[i], cells)
+		}
+	}
+	if header == nil {
+		return formatDefaultOutput(results)
+	}
+
+	_, aligned := renderAligned(header, rows)
+	normalized := make([]contextResult, len(results))
+	for i, result := range results {
+		normalized[i] = result
+		if aligned[i] != nil {
+			normalized[i].output = strings.Join(aligned[i], "\n")
+		}
+	}
+	return formatDefaultOutput(normalized)
+}
+
+// formatJSONPathOutput prints the -o jsonpath output of each context with the
+// context in front of every line, since values from several contexts are
+// otherwise indistinguishable. Contexts where the template printed nothing
+// show <none>.
+func formatJSONPathOutput(results []contextResult) error {
+	width := 0
+	for _, result := range results {
+		width = max(width, len(result.context))
+	}
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		output := strings.TrimRight(result.output, "\n")
+		if strings.TrimSpace(output) == "" {
+			output = "<none>"
+		}
+		contextPadding := strings.Repeat(" ", width-len(result.context))
+		for _, line := range strings.Split(output, "\n") {
+			fmt.Printf("%s%s"+contextGap+"%s\n", colorizeContext(result.context), contextPadding, line)
+		}
+	}
+	return nil
+}
+
+// formatWideOutput merges -o wide tables. Older servers print fewer wide
+// columns than newer ones, so when the headers differ every table is mapped
+// onto the union of the columns, starting from the widest header, with
+// missing cells shown as <none> and all tables aligned to the same widths.
+func formatWideOutput(results []contextResult) error {
+	var union []string
+	seen := make(map[string]bool)
+	mismatch := false
+	widest := -1
+	headers := make([][]string, len(results))
+	for i, result := range results {
+		lines := strings.Split(strings.TrimSpace(result.output), "\n")
+		if result.err != nil || len(lines) < 2 {
+			continue
+		}
+		headers[i] = columnNames(parseHeader(lines[0]))
+		if widest == -1 {
+			widest = i
+		} else if !slices.Equal(headers[i], headers[widest]) {
+			mismatch = true
+			if len(headers[i]) > len(headers[widest]) {
+				widest = i
+			}
+		}
+	}
+	if !mismatch {
+		return formatDefaultOutput(results)
+	}
+	for _, names := range append([][]string{headers[widest]}, headers...) {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				union = append(union, name)
+			}
+		}
+	}
+
+	// Render every table together so the columns line up across contexts
+	rows := make([][][]string, len(results))
+	for i, result := range results {
+		if headers[i] == nil {
+			continue
+		}
+		lines := strings.Split(strings.TrimSpace(result.output), "\n")
+		columns := parseHeader(lines[0])
+		if len(headers[i]) < len(union) {
+			notef("Context %s printed %d of %d wide columns; padding with <none>", result.context, len(headers[i]), len(union))
+		}
+		for _, line := range lines[1:] {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			cells := make(map[string]string, len(columns))
+			for j, cell := range splitRow(columns, line) {
+				cells[columns[j].name] = cell
+			}
+			row := make([]string, len(union))
+			for j, name := range union {
+				row[j] = cells[name]
+				if row[j] == "" {
+					row[j] = "<none>"
+				}
+			}
+			rows[i] = append(rows[i], row)
+		}
+	}
+
+	_, aligned := renderAligned(union, rows)
+	normalized := make([]contextResult, len(results))
+	for i, result := range results {
+		normalized[i] = result
+		if aligned[i] != nil {
+			normalized[i].output = strings.Join(aligned[i], "\n")
+		}
+	}
+	return formatDefaultOutput(normalized)
+}
+
+// printTable prints rows as left-aligned columns separated by two spaces
+func printTable(rows [][]string) {
+	writeTable(os.Stdout, rows)
+}
+
+// writeTable writes rows to w as left-aligned columns separated by two spaces
+func writeTable(w io.Writer, rows [][]string) {
+	widths := columnWidths(rows)
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			if i == len(row)-1 {
+				line.WriteString(cell)
+				break
+			}
+			line.WriteString(cell)
+			line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+2))
+		}
+		fmt.Fprintln(w, line.String())
+	}
+}
+
+// columnWidths returns the widest cell (in runes) of each column
+func columnWidths(rows [][]string) []int {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	return widths
+}
+
 func formatVersionOutput(results []contextResult) error {
 	type versionInfo struct {
 		clientVersion    string
@@ -213,6 +735,9 @@ func formatVersionOutput(results []contextResult) error {
 
 	// First pass: extract client and kustomize version from first successful result
 	for _, result := range results {
+		if serverOnly {
+			break
+		}
 		if result.err != nil {
 			continue
 		}
@@ -247,10 +772,7 @@ func formatVersionOutput(results []contextResult) error {
 			versionData[result.context] = versionInfo{
 				serverVersion: "ERROR",
 			}
-			fmt.Fprintf(os.Stderr, "Context %s: Error: %v\n", result.context, result.err)
-			if result.output != "" {
-				fmt.Fprintf(os.Stderr, "Output: %s\n", result.output)
-			}
+			printPlainContextError(result.context, result.err, result.output)
 			continue
 		}
 
@@ -262,18 +784,7 @@ func formatVersionOutput(results []contextResult) error {
 			continue
 		}
 
-		// Extract server version
-		var serverVersion string
-		lines := strings.Split(output, "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if strings.HasPrefix(line, "Server Version:") {
-				serverVersion = strings.TrimPrefix(line, "Server Version:")
-				serverVersion = strings.TrimSpace(serverVersion)
-				break
-			}
-		}
-
+		serverVersion := parseServerVersion(output)
 		if serverVersion == "" {
 			serverVersion = "N/A"
 		}
@@ -295,7 +806,7 @@ func formatVersionOutput(results []contextResult) error {
 	}
 
 	// Print table header
-	fmt.Printf("%-30s  %s\n", "CONTEXT", "SERVER VERSION")
+	fmt.Printf("%-30s  %s\n", contextHeader, "SERVER VERSION")
 	fmt.Println(strings.Repeat("-", 50))
 
 	// Print table rows
@@ -308,77 +819,116 @@ func formatVersionOutput(results []contextResult) error {
 		if contextLen < 30 {
 			padding = strings.Repeat(" ", 30-contextLen)
 		}
-		fmt.Printf("%s%s  %s\n", coloredContext, padding, info.serverVersion)
+		fmt.Printf("%s%s"+contextGap+"%s\n", coloredContext, padding, info.serverVersion)
 	}
 
 	return nil
 }
 
-func formatJSONOutput(results []contextResult, subcommand string) error {
-	var allItems []map[string]interface{}
+// parseServerVersion extracts the server version from kubectl version output
+func parseServerVersion(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if version, ok := strings.CutPrefix(line, "Server Version:"); ok {
+			return strings.TrimSpace(version)
+		}
+	}
+	return ""
+}
 
+// formatPrometheusOutput prints the server version and reachability of each
+// context in the Prometheus text format, for a node-exporter textfile collector
+func formatPrometheusOutput(results []contextResult) error {
+	var info, reachable strings.Builder
 	for _, result := range results {
+		context := escapeLabelValue(result.context)
 		if result.err != nil {
-			fmt.Fprintf(os.Stderr, "Context %s: Error: %v\n", result.context, result.err)
-			if result.output != "" {
-				// Try to parse error output anyway
-				var errorData map[string]interface{}
-				if err := json.Unmarshal([]byte(result.output), &errorData); err == nil {
-					errorData["context"] = result.context
-					errorData["error"] = result.err.Error()
-					allItems = append(allItems, errorData)
-				}
-			}
+			printContextError(result.context, result.err, result.output)
+			fmt.Fprintf(&reachable, "kube_server_reachable{context=\"%s\"} 0\n", context)
 			continue
 		}
-
-		var data map[string]interface{}
-		if err := json.Unmarshal([]byte(result.output), &data); err != nil {
-			fmt.Fprintf(os.Stderr, "Context %s: Failed to parse JSON: %v\n", result.context, err)
-			continue
+		fmt.Fprintf(&reachable, "kube_server_reachable{context=\"%s\"} 1\n", context)
+		if version := parseServerVersion(result.output); version != "" {
+			fmt.Fprintf(&info, "kube_server_version_info{context=\"%s\",version=\"%s\"} 1\n", context, escapeLabelValue(version))
 		}
+	}
 
-		// Extract items array if it exists
-		if itemsArray, exists := data["items"]; exists {
-			items, ok := itemsArray.([]interface{})
-			if !ok {
-				// Try to convert if it's not the right type
-				if itemsSlice, ok := itemsArray.([]interface{}); ok {
-					items = itemsSlice
-				} else {
-					continue
-				}
-			}
+	fmt.Println("# HELP kube_server_version_info Kubernetes API server version of each context.")
+	fmt.Println("# TYPE kube_server_version_info gauge")
+	fmt.Print(info.String())
+	fmt.Println("# HELP kube_server_reachable Whether the API server of each context answered.")
+	fmt.Println("# TYPE kube_server_reachable gauge")
+	fmt.Print(reachable.String())
+	return nil
+}
 
-			// Add context metadata to each item
-			for _, item := range items {
-				if itemMap, ok := item.(map[string]interface{}); ok {
-					if metadata, ok := itemMap["metadata"].(map[string]interface{}); ok {
-						metadata["context"] = result.context
-					} else {
-						itemMap["metadata"] = map[string]interface{}{
-							"context": result.context,
-						}
-					}
-					allItems = append(allItems, itemMap)
-				}
-			}
-		} else {
-			// No items array - this might be a single object or non-list response
-			// Add context to the root object
-			if metadata, ok := data["metadata"].(map[string]interface{}); ok {
-				metadata["context"] = result.context
-			} else {
-				data["context"] = result.context
-			}
-			allItems = append(allItems, data)
+// escapeLabelValue escapes a Prometheus label value
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// validContextPlacement reports whether --context-placement is a known placement
+func validContextPlacement() bool {
+	return contextPlacement == placementMetadata || contextPlacement == placementAnnotation || contextPlacement == placementSibling
+}
+
+// singleContextOutput returns the output of a run against a single context,
+// which the JSON and YAML formatters print as kubectl returned it, without
+// the List wrapper and context annotations. Options that rewrite, annotate,
+// label, regroup or validate items, and --keep-context, disable this.
+func singleContextOutput(results []contextResult) (string, bool) {
+	if len(results) != 1 || results[0].err != nil || keepContext || whereExpr != "" || trimEmpty || showOwners || !validContextPlacement() {
+		return "", false
+	}
+	if showWarnings || checkVersionSkew || showSource || contextGroup != "" || mergeStrategy == mergeByContext || strictJSON {
+		return "", false
+	}
+	return strings.TrimRight(results[0].output, "\n"), true
+}
+
+// sortOutputKeys re-encodes a single context's JSON or YAML output with its
+// map keys sorted. The merged List needs no sorting: the encoders sort map
+// keys, while kubectl prints fields in the order of the API types.
+func sortOutputKeys(output, formatName string) (string, error) {
+	var data interface{}
+	var encoded []byte
+	var err error
+	if formatName == "JSON" {
+		decoder := json.NewDecoder(strings.NewReader(output))
+		decoder.UseNumber()
+		if err = decoder.Decode(&data); err == nil {
+			encoded, err = json.MarshalIndent(data, "", "    ")
 		}
+	} else if err = yaml.Unmarshal([]byte(output), &data); err == nil {
+		encoded, err = yaml.Marshal(data)
 	}
+	if err != nil {
+		return "", fmt.Errorf("failed to sort %s keys: %w", formatName, err)
+	}
+	return strings.TrimRight(string(encoded), "\n"), nil
+}
 
-	output := map[string]interface{}{
-		"apiVersion": "v1",
-		"kind":       "List",
-		"items":      allItems,
+// printSingleContextOutput prints a single context's output, with its keys
+// sorted when --sort-keys is set
+func printSingleContextOutput(output, formatName string) error {
+	if sortKeys {
+		sorted, err := sortOutputKeys(output, formatName)
+		if err != nil {
+			return err
+		}
+		output = sorted
+	}
+	fmt.Println(output)
+	return nil
+}
+
+func formatJSONOutput(results []contextResult, subcommand string) error {
+	if output, ok := singleContextOutput(results); ok {
+		return printSingleContextOutput(output, "JSON")
+	}
+	output, err := buildList(results, json.Unmarshal, "JSON", strictJSON)
+	if err != nil {
+		return err
 	}
 
 	jsonData, err := json.MarshalIndent(output, "", "  ")
@@ -390,16 +940,143 @@ func formatJSONOutput(results []contextResult, subcommand string) error {
 	return nil
 }
 
+// errorRecord is the line printed for a failed context by -o ndjson-with-errors
+type errorRecord struct {
+	Context  string `json:"context"`
+	Error    string `json:"error"`
+	Code     string `json:"code,omitempty"`
+	Attempts int    `json:"attempts,omitempty"`
+}
+
+// printNDJSONResult prints one line per item of a context's JSON output, or
+// a single error record when the context failed or its output can't be
+// parsed. runIteration calls it as each context completes.
+func printNDJSONResult(result contextResult) {
+	var lines []interface{}
+	if result.err != nil {
+		code, message := parseKubectlError(result.output, result.err)
+		record := errorRecord{Context: result.context, Error: message, Code: code}
+		if result.attempts > 1 {
+			record.Attempts = result.attempts
+		}
+		lines = append(lines, record)
+	} else if items, err := collectItems([]contextResult{result}, json.Unmarshal, "JSON", true); err != nil {
+		lines = append(lines, errorRecord{Context: result.context, Error: err.Error(), Code: "InvalidOutput"})
+	} else {
+		for _, item := range items {
+			lines = append(lines, item)
+		}
+	}
+
+	for _, line := range lines {
+		data, err := json.Marshal(line)
+		if err != nil {
+			data, _ = json.Marshal(errorRecord{Context: result.context, Error: err.Error(), Code: "InvalidOutput"})
+		}
+		fmt.Println(string(data))
+	}
+}
+
 func formatYAMLOutput(results []contextResult, subcommand string) error {
-	var allItems []map[string]interface{}
+	if output, ok := singleContextOutput(results); ok {
+		return printSingleContextOutput(output, "YAML")
+	}
+	if splitByContext {
+		if mergeStrategy != mergeAll {
+			return fmt.Errorf("--split-by-context can only be used with --merge-strategy=%s", mergeAll)
+		}
+		return formatSplitYAMLOutput(results)
+	}
+
+	output, err := buildList(results, yaml.Unmarshal, "YAML", false)
+	if err != nil {
+		return err
+	}
 
+	yamlData, err := yaml.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	fmt.Print(string(yamlData))
+	return nil
+}
+
+// formatSplitYAMLOutput prints the merged YAML List with the items of each
+// context grouped under a "# === context: X ===" comment
+func formatSplitYAMLOutput(results []contextResult) error {
+	allItems := []map[string]interface{}{}
+	type section struct {
+		context string
+		start   int
+	}
+	var sections []section
 	for _, result := range results {
+		items, err := collectItems([]contextResult{result}, yaml.Unmarshal, "YAML", false)
+		if err != nil {
+			return err
+		}
+		if len(items) > 0 {
+			sections = append(sections, section{context: result.context, start: len(allItems)})
+			allItems = append(allItems, items...)
+		}
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      allItems,
+	}); err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value != "items" {
+			continue
+		}
+		items := doc.Content[i+1]
+		for _, s := range sections {
+			items.Content[s.start].HeadComment = fmt.Sprintf("=== context: %s ===", s.context)
+		}
+	}
+
+	yamlData, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	fmt.Print(string(yamlData))
+	return nil
+}
+
+// collectItems parses each context's output with unmarshal and concatenates
+// the items of all contexts, tagging every item with its context. Outputs
+// that fail to parse are skipped, or returned as an error when strict is set.
+func collectItems(results []contextResult, unmarshal func([]byte, interface{}) error, formatName string, strict bool) ([]map[string]interface{}, error) {
+	if !validContextPlacement() {
+		return nil, fmt.Errorf("invalid --context-placement %q: must be %s, %s or %s", contextPlacement, placementMetadata, placementAnnotation, placementSibling)
+	}
+
+	// Never nil, so an empty result marshals as [] rather than null
+	allItems := []map[string]interface{}{}
+	groupRegex, _ := compileContextGroup() // validated by runCommand
+	where, _ := parseWhere(whereExpr)      // validated by runCommand
+	var sources map[string]string
+	if showSource {
+		var err error
+		if sources, err = getContextSources(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, result := range results {
+		labels := contextGroupLabels(groupRegex, result.kubeconfigName())
 		if result.err != nil {
-			fmt.Fprintf(os.Stderr, "Context %s: Error: %v\n", result.context, result.err)
+			printPlainContextError(result.context, result.err, "")
 			if result.output != "" {
 				// Try to parse error output anyway
 				var errorData map[string]interface{}
-				if err := yaml.Unmarshal([]byte(result.output), &errorData); err == nil {
+				if err := unmarshal([]byte(result.output), &errorData); err == nil && errorData != nil {
 					errorData["context"] = result.context
 					errorData["error"] = result.err.Error()
 					allItems = append(allItems, errorData)
@@ -409,8 +1086,11 @@ func formatYAMLOutput(results []contextResult, subcommand string) error {
 		}
 
 		var data map[string]interface{}
-		if err := yaml.Unmarshal([]byte(result.output), &data); err != nil {
-			fmt.Fprintf(os.Stderr, "Context %s: Failed to parse YAML: %v\n", result.context, err)
+		if err := unmarshal([]byte(result.output), &data); err != nil {
+			if strict {
+				return nil, fmt.Errorf("context %s: failed to parse %s: %w", result.context, formatName, err)
+			}
+			fmt.Fprintf(os.Stderr, "Context %s: Failed to parse %s: %v\n", result.context, formatName, err)
 			continue
 		}
 
@@ -418,50 +1098,258 @@ func formatYAMLOutput(results []contextResult, subcommand string) error {
 		if itemsArray, exists := data["items"]; exists {
 			items, ok := itemsArray.([]interface{})
 			if !ok {
-				// Try to convert if it's not the right type
-				if itemsSlice, ok := itemsArray.([]interface{}); ok {
-					items = itemsSlice
-				} else {
-					continue
-				}
+				continue
 			}
 
 			// Add context metadata to each item
 			for _, item := range items {
-				if itemMap, ok := item.(map[string]interface{}); ok {
-					if metadata, ok := itemMap["metadata"].(map[string]interface{}); ok {
-						metadata["context"] = result.context
-					} else {
-						itemMap["metadata"] = map[string]interface{}{
-							"context": result.context,
-						}
-					}
+				if itemMap, ok := item.(map[string]interface{}); ok && where.match(itemMap) {
+					decorateItem(itemMap, result.context, true)
+					addSourceAnnotation(itemMap, sources[result.kubeconfigName()])
+					addWarningsAnnotation(itemMap, result.stderr)
+					addVersionSkewAnnotation(itemMap, result.versionSkew)
+					addGroupLabels(itemMap, labels)
 					allItems = append(allItems, itemMap)
 				}
 			}
 		} else {
 			// No items array - this might be a single object or non-list response
-			// Add context to the root object
-			if metadata, ok := data["metadata"].(map[string]interface{}); ok {
-				metadata["context"] = result.context
-			} else {
-				data["context"] = result.context
+			if !where.match(data) {
+				continue
 			}
+			decorateItem(data, result.context, false)
+			addSourceAnnotation(data, sources[result.kubeconfigName()])
+			addWarningsAnnotation(data, result.stderr)
+			addVersionSkewAnnotation(data, result.versionSkew)
+			addGroupLabels(data, labels)
 			allItems = append(allItems, data)
 		}
 	}
 
-	output := map[string]interface{}{
-		"apiVersion": "v1",
-		"kind":       "List",
-		"items":      allItems,
+	if trimEmpty {
+		for _, item := range allItems {
+			trimEmptyFields(item)
+		}
 	}
+	return allItems, nil
+}
 
-	yamlData, err := yaml.Marshal(output)
-	if err != nil {
-		return fmt.Errorf("failed to marshal YAML: %w", err)
+// keepEmptyFields lists fields whose empty value carries meaning and must
+// survive --trim-empty, such as a volume's emptyDir: {}
+var keepEmptyFields = map[string]bool{
+	"emptyDir": true,
+}
+
+// trimEmptyFields recursively removes null values and empty maps and slices
+// from m. Empty strings, zeros and false are kept.
+func trimEmptyFields(m map[string]interface{}) {
+	for key, value := range m {
+		value = trimEmptyValue(value)
+		if isEmptyValue(value) && !keepEmptyFields[key] {
+			delete(m, key)
+			continue
+		}
+		m[key] = value
 	}
+}
 
-	fmt.Print(string(yamlData))
+func trimEmptyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		trimEmptyFields(v)
+	case []interface{}:
+		kept := v[:0]
+		for _, elem := range v {
+			elem = trimEmptyValue(elem)
+			if !isEmptyValue(elem) {
+				kept = append(kept, elem)
+			}
+		}
+		return kept
+	}
+	return value
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// decorateItem records the context an item came from according to
+// --context-placement. Items without metadata get a new metadata map when
+// createMetadata is set, otherwise the context is added at the root.
+func decorateItem(item map[string]interface{}, context string, createMetadata bool) {
+	metadata, ok := item["metadata"].(map[string]interface{})
+	if !ok {
+		if !createMetadata || contextPlacement == placementSibling {
+			item["context"] = context
+			return
+		}
+		metadata = map[string]interface{}{}
+		item["metadata"] = metadata
+	}
+
+	switch contextPlacement {
+	case placementMetadata:
+		metadata["context"] = context
+	case placementSibling:
+		item["context"] = context
+	default:
+		annotate(metadata, contextAnnotation, context)
+	}
+
+	if showOwners {
+		summarizeOwners(metadata)
+	}
+}
+
+// addSourceAnnotation records the kubeconfig file an item's context came
+// from in a multi/source-kubeconfig annotation
+func addSourceAnnotation(item map[string]interface{}, source string) {
+	if source == "" {
+		return
+	}
+	if metadata, ok := item["metadata"].(map[string]interface{}); ok {
+		annotate(metadata, sourceAnnotation, source)
+	}
+}
+
+// addWarningsAnnotation records what kubectl printed on stderr for an item's
+// context in a multi/warnings annotation when --show-warnings is set
+func addWarningsAnnotation(item map[string]interface{}, stderr string) {
+	stderr = strings.TrimSpace(stderr)
+	if !showWarnings || stderr == "" {
+		return
+	}
+	if metadata, ok := item["metadata"].(map[string]interface{}); ok {
+		annotate(metadata, warningsAnnotation, stderr)
+	}
+}
+
+// addVersionSkewAnnotation records a --check-version-skew note on an item
+func addVersionSkewAnnotation(item map[string]interface{}, note string) {
+	if note == "" {
+		return
+	}
+	if metadata, ok := item["metadata"].(map[string]interface{}); ok {
+		annotate(metadata, versionSkewAnnotation, note)
+	}
+}
+
+// addGroupLabels records the --context-group labels of an item's context
+// next to its context
+func addGroupLabels(item map[string]interface{}, labels map[string]string) {
+	if labels == nil {
+		return
+	}
+	if metadata, ok := item["metadata"].(map[string]interface{}); ok {
+		metadata["contextGroup"] = labels
+		return
+	}
+	item["contextGroup"] = labels
+}
+
+// summarizeOwners replaces metadata.managedFields with a compact
+// multi/owners annotation listing each field manager and its operation
+func summarizeOwners(metadata map[string]interface{}) {
+	managedFields, ok := metadata["managedFields"].([]interface{})
+	if !ok {
+		return
+	}
+
+	var owners []string
+	seen := make(map[string]bool)
+	for _, entry := range managedFields {
+		field, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		owner := fmt.Sprintf("%v", field["manager"])
+		if operation, ok := field["operation"]; ok {
+			owner = fmt.Sprintf("%s (%v)", owner, operation)
+		}
+		if !seen[owner] {
+			seen[owner] = true
+			owners = append(owners, owner)
+		}
+	}
+
+	annotate(metadata, "multi/owners", strings.Join(owners, ", "))
+	delete(metadata, "managedFields")
+}
+
+// annotate sets an annotation in metadata, creating the annotations map if needed
+func annotate(metadata map[string]interface{}, key, value string) {
+	annotations, ok := metadata["annotations"].(map[string]interface{})
+	if !ok {
+		annotations = map[string]interface{}{}
+		metadata["annotations"] = annotations
+	}
+	annotations[key] = value
+}
+
+// sanitizeFilename makes a context name safe to use as a file name
+func sanitizeFilename(name string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	if sanitized == "" || strings.Trim(sanitized, ".") == "" {
+		sanitized = "_" + sanitized
+	}
+	return sanitized
+}
+
+// writeOutputDir writes each context's output as kubectl returned it to
+// <dir>/<context>.<ext>, with the extension of the format kubectl printed,
+// and the error of failed contexts to <dir>/<context>.err. Nothing is written
+// when two contexts would get the same file name.
+func writeOutputDir(dir string, results []contextResult, format outputFormat) error {
+	// Compared case-insensitively, as on macOS and Windows file systems
+	owners := make(map[string]string, len(results))
+	for _, result := range results {
+		name := strings.ToLower(sanitizeFilename(result.context))
+		if other, ok := owners[name]; ok {
+			return fmt.Errorf("--output-dir: contexts %s and %s would both be written to %s", other, result.context, sanitizeFilename(result.context))
+		}
+		owners[name] = result.context
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ext := ".txt"
+	switch format {
+	case formatJSON:
+		ext = ".json"
+	case formatYAML:
+		ext = ".yaml"
+	}
+
+	for _, result := range results {
+		base := filepath.Join(dir, sanitizeFilename(result.context))
+		if result.err != nil {
+			content := fmt.Sprintf("Error: %v\n%s", result.err, result.output)
+			if err := os.WriteFile(base+".err", []byte(content), 0o644); err != nil {
+				return fmt.Errorf("failed to write output for context %s: %w", result.context, err)
+			}
+			continue
+		}
+		if err := os.WriteFile(base+ext, []byte(result.output), 0o644); err != nil {
+			return fmt.Errorf("failed to write output for context %s: %w", result.context, err)
+		}
+	}
 	return nil
 }
@@ -2,13 +2,53 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
+func TestGetContextColor(t *testing.T) {
+	// Colors are derived from the name only, so they are the same on every run
+	setGlobal(t, &colorByContext, colorAlways)
+	expected := map[string]string{
+		"prod-eu": "\033[33m",
+		"prod-us": "\033[94m",
+		"staging": "\033[92m",
+	}
+	for context, color := range expected {
+		if got := getContextColor(context); got != color {
+			t.Errorf("getContextColor(%q) = %q, want %q", context, got, color)
+		}
+	}
+	if got := colorizeContext("staging"); got != "\033[92mstaging\033[0m" {
+		t.Errorf("colorizeContext() = %q", got)
+	}
+
+	setGlobal(t, &colorByContext, colorNever)
+	if got := colorizeContext("staging"); got != "staging" {
+		t.Errorf("colorizeContext() with never = %q, want no color", got)
+	}
+}
+
+func TestFormatJSONOutputNeverColored(t *testing.T) {
+	setGlobal(t, &colorByContext, colorAlways)
+	results := []contextResult{{context: "ctx1", output: `{"items": [{"metadata": {"name": "a"}}]}`}}
+	output := captureStdout(t, func() {
+		if err := formatJSONOutput(results, "get"); err != nil {
+			t.Errorf("formatJSONOutput() error = %v", err)
+		}
+	})
+	if strings.Contains(output, "\033[") {
+		t.Errorf("JSON output contains color codes: %q", output)
+	}
+}
+
 func TestDetectOutputFormat(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -60,6 +100,56 @@ func TestDetectOutputFormat(t *testing.T) {
 			args:     []string{"pod", "-o"},
 			expected: formatDefault,
 		},
+		{
+			name:     "custom-columns output",
+			args:     []string{"pods", "-o", "custom-columns=NAME:.metadata.name"},
+			expected: formatCustomColumns,
+		},
+		{
+			name:     "custom-columns output with equals",
+			args:     []string{"pods", "--output=custom-columns=NAME:.metadata.name"},
+			expected: formatCustomColumns,
+		},
+		{
+			name:     "custom-columns-file output",
+			args:     []string{"pods", "-ocustom-columns-file=cols.txt"},
+			expected: formatCustomColumns,
+		},
+		{
+			name:     "jsonpath output",
+			args:     []string{"pods", "-o", "jsonpath={.items[*].metadata.name}"},
+			expected: formatJSONPath,
+		},
+		{
+			name:     "jsonpath output with equals",
+			args:     []string{"pods", "--output=jsonpath={.items[*].metadata.name}"},
+			expected: formatJSONPath,
+		},
+		{
+			name:     "jsonpath-file output",
+			args:     []string{"pods", "-ojsonpath-file=names.txt"},
+			expected: formatJSONPath,
+		},
+		{
+			name:     "csv output",
+			args:     []string{"pods", "-o", "csv"},
+			expected: formatCSV,
+		},
+		{
+			name:     "tsv output with equals",
+			args:     []string{"pods", "--output=tsv"},
+			expected: formatTSV,
+		},
+		{
+			name:     "wide output",
+			args:     []string{"pods", "-o", "wide"},
+			expected: formatWide,
+		},
+		{
+			name:     "wide output with equals",
+			args:     []string{"pods", "--output=wide"},
+			expected: formatWide,
+		},
 		{
 			name:     "output flag at end",
 			args:     []string{"pod", "--output"},
@@ -92,7 +182,7 @@ func TestFormatDefaultOutput(t *testing.T) {
 					err:     nil,
 				},
 			},
-			expected: "CONTEXT  NAME    STATUS    AGE\nctx1     pod1    Running   5m\n",
+			expected: "CONTEXT  NAME   STATUS    AGE\nctx1     pod1   Running   5m\n",
 		},
 		{
 			name: "multiple contexts with header",
@@ -108,7 +198,7 @@ func TestFormatDefaultOutput(t *testing.T) {
 					err:     nil,
 				},
 			},
-			expected: "CONTEXT  NAME    STATUS    AGE\nctx1     pod1    Running   5m\nctx2     pod2    Pending   3m\n",
+			expected: "CONTEXT  NAME   STATUS    AGE\nctx1     pod1   Running   5m\nctx2     pod2   Pending   3m\n",
 		},
 		{
 			name: "contexts with different length names",
@@ -124,7 +214,17 @@ func TestFormatDefaultOutput(t *testing.T) {
 					err:     nil,
 				},
 			},
-			expected: "CONTEXT                 NAME    STATUS\nshort                   pod1    Running\nvery-long-context-name  pod2    Pending\n",
+			expected: "CONTEXT                 NAME   STATUS\nshort                   pod1   Running\nvery-long-context-name  pod2   Pending\n",
+		},
+		{
+			name: "columns realigned across contexts",
+			results: []contextResult{
+				{context: "ctx1", output: "NAME   READY   STATUS\npod1   1/1     Running"},
+				{context: "ctx2", output: "NAME                   READY   STATUS\nweb-7d9f8b6c5d-abcde   1/1     Running"},
+			},
+			expected: "CONTEXT  NAME                   READY   STATUS\n" +
+				"ctx1     pod1                   1/1     Running\n" +
+				"ctx2     web-7d9f8b6c5d-abcde   1/1     Running\n",
 		},
 		{
 			name: "context with error",
@@ -140,7 +240,7 @@ func TestFormatDefaultOutput(t *testing.T) {
 					err:     fmt.Errorf("connection failed"),
 				},
 			},
-			expected: "CONTEXT  NAME    STATUS\nctx1     pod1    Running\n",
+			expected: "CONTEXT  NAME   STATUS\nctx1     pod1   Running\n",
 		},
 		{
 			name: "context with empty output",
@@ -156,7 +256,7 @@ func TestFormatDefaultOutput(t *testing.T) {
 					err:     nil,
 				},
 			},
-			expected: "CONTEXT  NAME    STATUS\nctx1     pod1    Running\n",
+			expected: "CONTEXT  NAME   STATUS\nctx1     pod1   Running\n",
 		},
 		{
 			name: "no header in output",
@@ -204,6 +304,210 @@ func TestFormatDefaultOutput(t *testing.T) {
 	}
 }
 
+func TestFormatDefaultOutputContextHeader(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "NAME   STATUS\npod1   Running"},
+		{context: "production", output: "NAME   STATUS\npod2   Running"},
+	}
+
+	setGlobal(t, &contextHeader, "CLUSTER")
+	output := captureStdout(t, func() {
+		if err := formatDefaultOutput(results); err != nil {
+			t.Errorf("formatDefaultOutput() error = %v, want nil", err)
+		}
+	})
+	expected := "CLUSTER     NAME   STATUS\nctx1        pod1   Running\nproduction  pod2   Running\n"
+	if output != expected {
+		t.Errorf("formatDefaultOutput() output = %q, want %q", output, expected)
+	}
+
+	setGlobal(t, &tableStyle, tableStyleBox)
+	output = captureStdout(t, func() {
+		if err := formatDefaultOutput(results); err != nil {
+			t.Errorf("formatDefaultOutput() error = %v, want nil", err)
+		}
+	})
+	if !strings.Contains(output, "│ CLUSTER    │ NAME │") {
+		t.Errorf("box output header = %q, want CLUSTER column", output)
+	}
+}
+
+func TestFormatDefaultOutputSections(t *testing.T) {
+	getAll := func(pod, svc string) string {
+		return "NAME       READY   STATUS\npod/" + pod + "   1/1     Running\n\n" +
+			"NAME          TYPE        CLUSTER-IP\nservice/" + svc + "   ClusterIP   10.0.0.1\n"
+	}
+	results := []contextResult{
+		{context: "ctx1", output: getAll("web-1", "web-1")},
+		{context: "ctx2", output: getAll("web-2", "web-2")},
+		{context: "ctx3", output: "Unable to connect", err: fmt.Errorf("exit status 1")},
+	}
+
+	var stderr string
+	output := captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := formatDefaultOutput(results); err != nil {
+				t.Errorf("formatDefaultOutput() error = %v, want nil", err)
+			}
+		})
+	})
+	expected := "CONTEXT  NAME        READY   STATUS\n" +
+		"ctx1     pod/web-1   1/1     Running\n" +
+		"ctx2     pod/web-2   1/1     Running\n" +
+		"\n" +
+		"CONTEXT  NAME            TYPE        CLUSTER-IP\n" +
+		"ctx1     service/web-1   ClusterIP   10.0.0.1\n" +
+		"ctx2     service/web-2   ClusterIP   10.0.0.1\n"
+	if output != expected {
+		t.Errorf("formatDefaultOutput() output =\n%s\nwant\n%s", output, expected)
+	}
+	if strings.Count(stderr, "Context ctx3") != 1 {
+		t.Errorf("stderr = %q, want the ctx3 error once", stderr)
+	}
+}
+
+func TestTableBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected int
+	}{
+		{name: "single table", output: "NAME\npod1\n", expected: 1},
+		{name: "get all", output: "NAME\npod/a\n\nNAME\nservice/a\n", expected: 2},
+		{name: "logs with blank lines", output: "starting\n\nready\n", expected: 1},
+		{name: "empty", output: "\n", expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tableBlocks(tt.output); len(got) != tt.expected {
+				t.Errorf("tableBlocks() = %q, want %d blocks", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatDefaultOutputHeaderFrom(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "NAME   STATUS\npod1   Running"},
+		{context: "ctx2", output: "NAME    STATUS    AGE\npod2    Running   5m"},
+	}
+
+	setGlobal(t, &headerFrom, "ctx2")
+	output := captureStdout(t, func() {
+		if err := formatDefaultOutput(results); err != nil {
+			t.Errorf("formatDefaultOutput() error = %v, want nil", err)
+		}
+	})
+	expected := "CONTEXT  NAME   STATUS    AGE\nctx1     pod1   Running\nctx2     pod2   Running   5m\n"
+	if output != expected {
+		t.Errorf("formatDefaultOutput() output = %q, want %q", output, expected)
+	}
+
+	setGlobal(t, &headerFrom, "missing")
+	captureStdout(t, func() {
+		if err := formatDefaultOutput(results); err == nil {
+			t.Errorf("formatDefaultOutput() expected error for --header-from context without output")
+		}
+	})
+}
+
+func TestFormatDefaultOutputBoxStyle(t *testing.T) {
+	setGlobal(t, &tableStyle, tableStyleBox)
+	results := []contextResult{
+		{context: "ctx1", output: "NAME    STATUS    AGE\npod1    Running   5m"},
+		{context: "production", output: "NAME        STATUS    AGE\npod-ü-long  Pending   3m"},
+	}
+
+	output := captureStdout(t, func() {
+		if err := formatDefaultOutput(results); err != nil {
+			t.Errorf("formatDefaultOutput() error = %v, want nil", err)
+		}
+	})
+
+	expected := `┌────────────┬────────────┬─────────┬─────┐
+│ CONTEXT    │ NAME       │ STATUS  │ AGE │
+├────────────┼────────────┼─────────┼─────┤
+│ ctx1       │ pod1       │ Running │ 5m  │
+│ production │ pod-ü-long │ Pending │ 3m  │
+└────────────┴────────────┴─────────┴─────┘
+`
+	if output != expected {
+		t.Errorf("formatDefaultOutput() output =\n%s\nwant\n%s", output, expected)
+	}
+}
+
+func TestFormatDefaultOutputInvalidTableStyle(t *testing.T) {
+	setGlobal(t, &tableStyle, "fancy")
+	if err := formatDefaultOutput(nil); err == nil {
+		t.Errorf("formatDefaultOutput() expected error for invalid --table-style")
+	}
+}
+
+func TestFormatCustomColumnsOutput(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "NAME   NODE     IP\npod1   node-a   10.0.0.1\npod2            10.0.0.2"},
+		{context: "ctx2", output: "NAME   NODE     IP\npod3   node-b   10.1.0.1"},
+	}
+
+	output := captureStdout(t, func() {
+		if err := formatCustomColumnsOutput(results); err != nil {
+			t.Errorf("formatCustomColumnsOutput() error = %v, want nil", err)
+		}
+	})
+
+	expected := "CONTEXT  NAME   NODE     IP\n" +
+		"ctx1     pod1   node-a   10.0.0.1\n" +
+		"ctx1     pod2   <none>   10.0.0.2\n" +
+		"ctx2     pod3   node-b   10.1.0.1\n"
+	if output != expected {
+		t.Errorf("formatCustomColumnsOutput() output = %q, want %q", output, expected)
+	}
+}
+
+func TestFormatWideOutput(t *testing.T) {
+	results := []contextResult{
+		{context: "old", output: "NAME   READY   IP         NODE\npod1   1/1     10.0.0.1   node-a"},
+		{context: "new", output: "NAME   READY   IP         NODE     NOMINATED NODE   READINESS GATES\npod2   1/1     10.1.0.1   node-b   <none>           <none>"},
+	}
+
+	var output string
+	stderr := captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := formatWideOutput(results); err != nil {
+				t.Errorf("formatWideOutput() error = %v, want nil", err)
+			}
+		})
+	})
+
+	expected := "CONTEXT  NAME   READY   IP         NODE     NOMINATED NODE   READINESS GATES\n" +
+		"old      pod1   1/1     10.0.0.1   node-a   <none>           <none>\n" +
+		"new      pod2   1/1     10.1.0.1   node-b   <none>           <none>\n"
+	if output != expected {
+		t.Errorf("formatWideOutput() output = %q, want %q", output, expected)
+	}
+	if !strings.Contains(stderr, "Context old printed 4 of 6 wide columns") {
+		t.Errorf("stderr = %q, want padding note for old", stderr)
+	}
+}
+
+func TestFormatWideOutputMatchingHeaders(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "NAME   IP\npod1   10.0.0.1"},
+		{context: "ctx2", output: "NAME   IP\npod2   10.1.0.1"},
+	}
+
+	output := captureStdout(t, func() {
+		if err := formatWideOutput(results); err != nil {
+			t.Errorf("formatWideOutput() error = %v, want nil", err)
+		}
+	})
+
+	expected := "CONTEXT  NAME   IP\nctx1     pod1   10.0.0.1\nctx2     pod2   10.1.0.1\n"
+	if output != expected {
+		t.Errorf("formatWideOutput() output = %q, want %q", output, expected)
+	}
+}
+
 func TestFormatVersionOutput(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -315,7 +619,32 @@ func TestFormatVersionOutput(t *testing.T) {
 	}
 }
 
+func TestFormatVersionOutputServerOnly(t *testing.T) {
+	setGlobal(t, &serverOnly, true)
+	results := []contextResult{
+		{context: "ctx1", output: "Client Version: v1.34.3\nKustomize Version: v5.7.1\nServer Version: v1.34.0"},
+		{context: "ctx2", output: "Client Version: v1.34.3\nKustomize Version: v5.7.1\nServer Version: v1.33.2"},
+	}
+
+	output := captureStdout(t, func() {
+		if err := formatVersionOutput(results); err != nil {
+			t.Errorf("formatVersionOutput() error = %v, want nil", err)
+		}
+	})
+
+	if strings.Contains(output, "Client Version") || strings.Contains(output, "Kustomize Version") {
+		t.Errorf("formatVersionOutput() with --server-only should not print the client preamble, got %q", output)
+	}
+	expected := "CONTEXT                         SERVER VERSION\n--------------------------------------------------\nctx1                            v1.34.0\nctx2                            v1.33.2\n"
+	if output != expected {
+		t.Errorf("formatVersionOutput() output = %q, want %q", output, expected)
+	}
+}
+
 func TestFormatJSONOutput(t *testing.T) {
+	// Single-context runs are otherwise printed as kubectl returned them
+	setGlobal(t, &keepContext, true)
+	setGlobal(t, &contextPlacement, placementMetadata)
 	tests := []struct {
 		name     string
 		results  []contextResult
@@ -492,7 +821,187 @@ func TestFormatJSONOutput(t *testing.T) {
 	}
 }
 
+func TestFormatJSONOutputLargeLists(t *testing.T) {
+	// kubectl follows the continue tokens of --chunk-size itself, so each context
+	// returns one complete list. The merge must keep every item of every context.
+	const itemsPerContext = 2500
+	var results []contextResult
+	for _, ctx := range []string{"ctx1", "ctx2"} {
+		items := make([]map[string]interface{}, itemsPerContext)
+		for i := range items {
+			items[i] = map[string]interface{}{
+				"metadata": map[string]interface{}{"name": fmt.Sprintf("pod-%d", i)},
+			}
+		}
+		data, err := json.Marshal(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"metadata":   map[string]interface{}{"resourceVersion": ""},
+			"items":      items,
+		})
+		if err != nil {
+			t.Fatalf("failed to build test list: %v", err)
+		}
+		results = append(results, contextResult{context: ctx, output: string(data)})
+	}
+
+	output := captureStdout(t, func() {
+		if err := formatJSONOutput(results, "get"); err != nil {
+			t.Errorf("formatJSONOutput() error = %v, want nil", err)
+		}
+	})
+
+	var merged struct {
+		Items []struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &merged); err != nil {
+		t.Fatalf("formatJSONOutput() produced invalid JSON: %v", err)
+	}
+	if len(merged.Items) != 2*itemsPerContext {
+		t.Fatalf("merged list has %d items, want %d", len(merged.Items), 2*itemsPerContext)
+	}
+	perContext := make(map[interface{}]int)
+	for _, item := range merged.Items {
+		annotations, _ := item.Metadata["annotations"].(map[string]interface{})
+		perContext[annotations[contextAnnotation]]++
+	}
+	for _, ctx := range []string{"ctx1", "ctx2"} {
+		if perContext[ctx] != itemsPerContext {
+			t.Errorf("context %s has %d items, want %d", ctx, perContext[ctx], itemsPerContext)
+		}
+	}
+}
+
+func TestFormatJSONOutputShowOwners(t *testing.T) {
+	setGlobal(t, &showOwners, true)
+	results := []contextResult{
+		{
+			context: "ctx1",
+			output: `{"items":[{"metadata":{"name":"api","annotations":{"team":"payments"},"managedFields":[
+				{"manager":"kubectl-client-side-apply","operation":"Update","fieldsV1":{}},
+				{"manager":"helm","operation":"Apply","fieldsV1":{}},
+				{"manager":"kube-controller-manager","operation":"Update","subresource":"status"},
+				{"manager":"helm","operation":"Apply"}
+			]}}]}`,
+		},
+	}
+
+	output := captureStdout(t, func() {
+		if err := formatJSONOutput(results, "get"); err != nil {
+			t.Errorf("formatJSONOutput() error = %v, want nil", err)
+		}
+	})
+
+	var merged struct {
+		Items []struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &merged); err != nil {
+		t.Fatalf("formatJSONOutput() produced invalid JSON: %v", err)
+	}
+	metadata := merged.Items[0].Metadata
+	if _, ok := metadata["managedFields"]; ok {
+		t.Errorf("managedFields should be replaced by the owners annotation")
+	}
+	annotations := metadata["annotations"].(map[string]interface{})
+	want := "kubectl-client-side-apply (Update), helm (Apply), kube-controller-manager (Update)"
+	if annotations["multi/owners"] != want {
+		t.Errorf("multi/owners = %q, want %q", annotations["multi/owners"], want)
+	}
+	if annotations["team"] != "payments" {
+		t.Errorf("existing annotations should be preserved, got %v", annotations)
+	}
+}
+
+func TestFormatJSONOutputContextGroupLabels(t *testing.T) {
+	setGlobal(t, &contextGroup, `^(?P<env>\w+)-(?P<region>[\w-]+)$`)
+	results := []contextResult{
+		{context: "prod-us-east-1", output: `{"items":[{"metadata":{"name":"pod1"}}]}`},
+		{context: "minikube", output: `{"items":[{"metadata":{"name":"pod2"}}]}`},
+	}
+
+	output := captureStdout(t, func() {
+		if err := formatJSONOutput(results, "get"); err != nil {
+			t.Errorf("formatJSONOutput() error = %v, want nil", err)
+		}
+	})
+
+	if !strings.Contains(output, `"contextGroup": {
+          "env": "prod",
+          "region": "us-east-1"
+        }`) {
+		t.Errorf("formatJSONOutput() should contain the group labels, got %s", output)
+	}
+	if strings.Count(output, "contextGroup") != 1 {
+		t.Errorf("non-matching contexts should not get group labels, got %s", output)
+	}
+}
+
+func TestFormatJSONOutputStrict(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: `{"items":[{"metadata":{"name":"pod1"}}]}`},
+		{context: "ctx2", output: `{"items":[{"metadata":`},
+	}
+
+	t.Run("lenient skips malformed context", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			if err := formatJSONOutput(results, "get"); err != nil {
+				t.Errorf("formatJSONOutput() error = %v, want nil", err)
+			}
+		})
+		if !strings.Contains(output, `"name": "pod1"`) {
+			t.Errorf("formatJSONOutput() should still contain the valid context, got %s", output)
+		}
+	})
+
+	t.Run("strict fails on malformed context", func(t *testing.T) {
+		setGlobal(t, &strictJSON, true)
+		var err error
+		output := captureStdout(t, func() {
+			err = formatJSONOutput(results, "get")
+		})
+		if err == nil {
+			t.Fatalf("formatJSONOutput() expected error with --strict-json")
+		}
+		if !strings.Contains(err.Error(), "context ctx2") {
+			t.Errorf("error %q should name the failing context", err)
+		}
+		if output != "" {
+			t.Errorf("formatJSONOutput() should print nothing on a strict failure, got %s", output)
+		}
+	})
+}
+
+func TestFormatOutputEmptyList(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "Unable to connect to the server", err: fmt.Errorf("exit status 1")},
+		{context: "ctx2", output: `{"apiVersion":"v1","kind":"List","items":[]}`},
+	}
+
+	jsonOutput := captureStdout(t, func() {
+		if err := formatJSONOutput(results, "get"); err != nil {
+			t.Errorf("formatJSONOutput() error = %v, want nil", err)
+		}
+	})
+	if !strings.Contains(jsonOutput, `"items": []`) {
+		t.Errorf("formatJSONOutput() should emit an empty items array, got %s", jsonOutput)
+	}
+
+	yamlOutput := captureStdout(t, func() {
+		if err := formatYAMLOutput(results, "get"); err != nil {
+			t.Errorf("formatYAMLOutput() error = %v, want nil", err)
+		}
+	})
+	if !strings.Contains(yamlOutput, "items: []") {
+		t.Errorf("formatYAMLOutput() should emit an empty items sequence, got %s", yamlOutput)
+	}
+}
+
 func TestFormatYAMLOutput(t *testing.T) {
+	setGlobal(t, &keepContext, true)
 	tests := []struct {
 		name    string
 		results []contextResult
@@ -588,6 +1097,7 @@ func TestFormatYAMLOutput(t *testing.T) {
 }
 
 func TestFormatOutput(t *testing.T) {
+	setGlobal(t, &keepContext, true)
 	tests := []struct {
 		name       string
 		format     outputFormat
@@ -650,8 +1160,8 @@ func TestFormatOutput(t *testing.T) {
 				},
 			},
 			checkFn: func(t *testing.T, output string) {
-				if !strings.Contains(output, `"context": "ctx1"`) {
-					t.Errorf("formatOutput() should contain context in JSON")
+				if !strings.Contains(output, `"multi/context": "ctx1"`) {
+					t.Errorf("formatOutput() should contain context annotation in JSON")
 				}
 				if !strings.Contains(output, `"kind": "List"`) {
 					t.Errorf("formatOutput() should contain List kind")
@@ -710,3 +1220,522 @@ func TestFormatOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		context  string
+		expected string
+	}{
+		{name: "plain name", context: "prod-cluster", expected: "prod-cluster"},
+		{name: "slashes", context: "arn:aws:eks:us-east-1:123:cluster/prod", expected: "arn_aws_eks_us-east-1_123_cluster_prod"},
+		{name: "spaces and dots", context: "my ctx.v1", expected: "my_ctx.v1"},
+		{name: "dot only", context: "..", expected: "_.."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeFilename(tt.context); got != tt.expected {
+				t.Errorf("sanitizeFilename(%q) = %q, want %q", tt.context, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteOutputDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshot")
+	results := []contextResult{
+		{context: "ctx1", output: `{"items":[]}`},
+		{context: "cluster/prod", output: `{"kind":"List"}`},
+		{context: "ctx3", output: "connection refused", err: fmt.Errorf("exit status 1")},
+	}
+
+	if err := writeOutputDir(dir, results, formatJSON); err != nil {
+		t.Fatalf("writeOutputDir() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"ctx1.json":         `{"items":[]}`,
+		"cluster_prod.json": `{"kind":"List"}`,
+		"ctx3.err":          "Error: exit status 1\nconnection refused",
+	}
+	for name, content := range expected {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("expected file %s: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("file %s content = %q, want %q", name, string(data), content)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "ctx3.json")); !os.IsNotExist(err) {
+		t.Errorf("failed context should not have a .json file")
+	}
+}
+
+func TestRunCommandOutputDir(t *testing.T) {
+	podList := `{"kind":"List","items":[{"kind":"Pod","metadata":{"name":"pod1"}}]}`
+	tests := []struct {
+		name     string
+		args     []string
+		summary  bool
+		expected string
+	}{
+		{name: "forced JSON", args: []string{"pods"}, summary: true, expected: "ctx1.json"},
+		{name: "decorated JSON", args: []string{"pods", "-o", "json"}, expected: "ctx1.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeKubeconfig(t, "ctx1")
+			dir := t.TempDir()
+			setGlobal(t, &outputDir, dir)
+			setGlobal(t, &resourceSummary, tt.summary)
+			setGlobal(t, &contextPlacement, placementSibling)
+			fakeKubectl(t, func(args []string) (string, error) {
+				return podList, nil
+			})
+
+			captureStdout(t, func() {
+				if err := runCommand("get", tt.args); err != nil {
+					t.Errorf("runCommand() error = %v", err)
+				}
+			})
+
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 1 || entries[0].Name() != tt.expected {
+				t.Fatalf("output dir has %v, want only %s", entries, tt.expected)
+			}
+			data, _ := os.ReadFile(filepath.Join(dir, tt.expected))
+			if string(data) != podList {
+				t.Errorf("file %s = %s, want kubectl's output %s", tt.expected, data, podList)
+			}
+		})
+	}
+}
+
+func TestWriteOutputDirCollision(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshot")
+	results := []contextResult{
+		{context: "cluster/prod", output: "a"},
+		{context: "cluster:prod", output: "b"},
+	}
+
+	err := writeOutputDir(dir, results, formatDefault)
+	if err == nil || !strings.Contains(err.Error(), "contexts cluster/prod and cluster:prod would both be written to cluster_prod") {
+		t.Errorf("writeOutputDir() error = %v, want a collision error", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("writeOutputDir() wrote %s despite the collision", dir)
+	}
+}
+
+func TestDecorateItemContextPlacement(t *testing.T) {
+	tests := []struct {
+		placement string
+		expected  string
+	}{
+		{
+			placement: placementMetadata,
+			expected:  `{"metadata":{"context":"ctx1","name":"pod1"}}`,
+		},
+		{
+			placement: placementAnnotation,
+			expected:  `{"metadata":{"annotations":{"multi/context":"ctx1"},"name":"pod1"}}`,
+		},
+		{
+			placement: placementSibling,
+			expected:  `{"context":"ctx1","metadata":{"name":"pod1"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.placement, func(t *testing.T) {
+			setGlobal(t, &contextPlacement, tt.placement)
+			item := map[string]interface{}{"metadata": map[string]interface{}{"name": "pod1"}}
+			decorateItem(item, "ctx1", true)
+
+			data, err := json.Marshal(item)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("decorateItem() = %s, want %s", data, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecorateItemKeepsExistingAnnotations(t *testing.T) {
+	item := map[string]interface{}{"metadata": map[string]interface{}{
+		"annotations": map[string]interface{}{"team": "payments"},
+	}}
+	decorateItem(item, "ctx1", true)
+
+	annotations := item["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})
+	if annotations["team"] != "payments" || annotations[contextAnnotation] != "ctx1" {
+		t.Errorf("decorateItem() annotations = %v, want team and %s", annotations, contextAnnotation)
+	}
+}
+
+func TestFormatJSONOutputInvalidContextPlacement(t *testing.T) {
+	setGlobal(t, &contextPlacement, "labels")
+	results := []contextResult{{context: "ctx1", output: `{"items":[]}`}}
+	if err := formatJSONOutput(results, "get"); err == nil {
+		t.Errorf("formatJSONOutput() expected error for invalid --context-placement")
+	}
+}
+
+func TestFormatJSONOutputTrimEmpty(t *testing.T) {
+	setGlobal(t, &trimEmpty, true)
+	setGlobal(t, &contextPlacement, placementMetadata)
+	results := []contextResult{{
+		context: "ctx1",
+		output: `{"items":[{"metadata":{"name":"pod1","labels":{},"ownerReferences":[],"deletionTimestamp":null},` +
+			`"spec":{"nodeName":"","replicas":0,"hostNetwork":false,"tolerations":[null,{}],` +
+			`"volumes":[{"name":"cache","emptyDir":{}}],"securityContext":{"seLinuxOptions":{}}},"status":null}]}`,
+	}}
+
+	output := captureStdout(t, func() {
+		if err := formatJSONOutput(results, "get"); err != nil {
+			t.Errorf("formatJSONOutput() error = %v", err)
+		}
+	})
+
+	expected := `{
+  "apiVersion": "v1",
+  "items": [
+    {
+      "metadata": {
+        "context": "ctx1",
+        "name": "pod1"
+      },
+      "spec": {
+        "hostNetwork": false,
+        "nodeName": "",
+        "replicas": 0,
+        "volumes": [
+          {
+            "emptyDir": {},
+            "name": "cache"
+          }
+        ]
+      }
+    }
+  ],
+  "kind": "List"
+}
+`
+	if output != expected {
+		t.Errorf("formatJSONOutput() output =\n%s\nwant\n%s", output, expected)
+	}
+}
+
+func TestFormatDefaultOutputMaxColWidth(t *testing.T) {
+	results := []contextResult{{
+		context: "ctx1",
+		output:  "NAME                    IMAGE\napi-7d9f8b6c5d-x2k4j    registry.example.com/api@sha256:4f1d2c9e\n",
+	}}
+
+	t.Run("fixed width", func(t *testing.T) {
+		setGlobal(t, &maxColWidth, "10")
+		output := captureStdout(t, func() {
+			if err := formatDefaultOutput(results); err != nil {
+				t.Errorf("formatDefaultOutput() error = %v", err)
+			}
+		})
+		if !strings.Contains(output, "api-7d9f8…") || !strings.Contains(output, "registry.…") {
+			t.Errorf("formatDefaultOutput() should truncate cells to 10 characters:\n%s", output)
+		}
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		setGlobal(t, &maxColWidth, "auto")
+		setGlobal(t, &terminalWidth, func() int { return 45 })
+		output := captureStdout(t, func() {
+			if err := formatDefaultOutput(results); err != nil {
+				t.Errorf("formatDefaultOutput() error = %v", err)
+			}
+		})
+		// 45 columns shared by CONTEXT, NAME and IMAGE
+		if !strings.Contains(output, "api-7d9f8b6c5d…") || !strings.Contains(output, "registry.examp…") {
+			t.Errorf("formatDefaultOutput() should truncate cells to 15 characters:\n%s", output)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		setGlobal(t, &maxColWidth, "wide")
+		if err := formatDefaultOutput(results); err == nil {
+			t.Errorf("formatDefaultOutput() expected error for invalid --max-col-width")
+		}
+	})
+}
+
+func TestFormatYAMLOutputSplitByContext(t *testing.T) {
+	setGlobal(t, &splitByContext, true)
+	results := []contextResult{
+		{context: "ctx1", output: "items:\n- metadata:\n    name: pod-b\n- metadata:\n    name: pod-a\n"},
+		{context: "empty", output: "items: []\n"},
+		{context: "ctx2", output: "items:\n- metadata:\n    name: pod-c\n"},
+	}
+
+	output := captureStdout(t, func() {
+		if err := formatYAMLOutput(results, "get"); err != nil {
+			t.Errorf("formatYAMLOutput() error = %v", err)
+		}
+	})
+
+	expected := `apiVersion: v1
+items:
+    # === context: ctx1 ===
+    - metadata:
+        annotations:
+            multi/context: ctx1
+        name: pod-b
+    - metadata:
+        annotations:
+            multi/context: ctx1
+        name: pod-a
+    # === context: ctx2 ===
+    - metadata:
+        annotations:
+            multi/context: ctx2
+        name: pod-c
+kind: List
+`
+	if output != expected {
+		t.Errorf("formatYAMLOutput() output =\n%s\nwant\n%s", output, expected)
+	}
+
+	var parsed map[string]interface{}
+	if err := yaml.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Errorf("split output is not valid YAML: %v", err)
+	}
+}
+
+func TestFormatOutputRawKubectl(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "NAME   READY   STATUS\npod1   1/1     Running\n\n  indented note\n"},
+		{context: "ctx2", output: "NAME        READY   STATUS\nlong-pod2   0/1     Pending\n"},
+	}
+
+	formatted := captureStdout(t, func() {
+		if err := formatOutput(results, formatDefault, "get"); err != nil {
+			t.Errorf("formatOutput() error = %v", err)
+		}
+	})
+	if !strings.HasPrefix(formatted, "CONTEXT  NAME") {
+		t.Errorf("formatted output should merge the tables under one header, got:\n%s", formatted)
+	}
+
+	setGlobal(t, &rawKubectl, true)
+	raw := captureStdout(t, func() {
+		if err := formatOutput(results, formatDefault, "get"); err != nil {
+			t.Errorf("formatOutput() error = %v", err)
+		}
+	})
+	expected := "[ctx1] NAME   READY   STATUS\n" +
+		"[ctx1] pod1   1/1     Running\n" +
+		"[ctx1] \n" +
+		"[ctx1]   indented note\n" +
+		"[ctx2] NAME        READY   STATUS\n" +
+		"[ctx2] long-pod2   0/1     Pending\n"
+	if raw != expected {
+		t.Errorf("raw output =\n%q\nwant\n%q", raw, expected)
+	}
+}
+
+func TestRunCommandVersionPrometheus(t *testing.T) {
+	writeKubeconfig(t, "prod", "down")
+	fakeKubectl(t, func(args []string) (string, error) {
+		if argValue(args, "-o") != "" {
+			t.Errorf("kubectl should not receive -o prometheus, got %v", args)
+		}
+		if argValue(args, "--context") == "down" {
+			return "Client Version: v1.29.0\nUnable to connect to the server: dial tcp: i/o timeout\n", fmt.Errorf("exit status 1")
+		}
+		return "Client Version: v1.29.0\nKustomize Version: v5.0.4\nServer Version: v1.28.3\n", nil
+	})
+
+	var output string
+	captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := runCommand("version", []string{"-o", "prometheus"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+	})
+
+	expected := `# HELP kube_server_version_info Kubernetes API server version of each context.
+# TYPE kube_server_version_info gauge
+kube_server_version_info{context="prod",version="v1.28.3"} 1
+# HELP kube_server_reachable Whether the API server of each context answered.
+# TYPE kube_server_reachable gauge
+kube_server_reachable{context="down"} 0
+kube_server_reachable{context="prod"} 1
+`
+	if output != expected {
+		t.Errorf("prometheus output =\n%s\nwant\n%s", output, expected)
+	}
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	if got := escapeLabelValue("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("escapeLabelValue() = %q", got)
+	}
+}
+
+func TestRunCommandPrometheusOnlyForVersion(t *testing.T) {
+	if err := runCommand("get", []string{"pods", "-o", "prometheus"}); err == nil {
+		t.Errorf("runCommand() expected error for -o prometheus with get")
+	}
+}
+
+func TestFormatJSONOutputSingleContext(t *testing.T) {
+	single := []contextResult{{context: "ctx1", output: `{"apiVersion": "v1", "kind": "List", "items": [{"metadata": {"name": "pod1"}}]}` + "\n"}}
+	output := captureStdout(t, func() {
+		if err := formatJSONOutput(single, "get"); err != nil {
+			t.Errorf("formatJSONOutput() error = %v", err)
+		}
+	})
+	if output != single[0].output {
+		t.Errorf("single context output = %q, want kubectl's output verbatim", output)
+	}
+
+	multiple := append(single, contextResult{context: "ctx2", output: `{"items": [{"metadata": {"name": "pod2"}}]}`})
+	output = captureStdout(t, func() {
+		if err := formatJSONOutput(multiple, "get"); err != nil {
+			t.Errorf("formatJSONOutput() error = %v", err)
+		}
+	})
+	if !strings.Contains(output, `"multi/context": "ctx1"`) || !strings.Contains(output, `"multi/context": "ctx2"`) {
+		t.Errorf("multiple context output = %q, want context annotations", output)
+	}
+
+	setGlobal(t, &keepContext, true)
+	output = captureStdout(t, func() {
+		if err := formatYAMLOutput(single, "get"); err != nil {
+			t.Errorf("formatYAMLOutput() error = %v", err)
+		}
+	})
+	if !strings.Contains(output, "multi/context: ctx1") {
+		t.Errorf("--keep-context output = %q, want context annotation", output)
+	}
+}
+
+func TestSingleContextOutputDisabled(t *testing.T) {
+	single := []contextResult{{context: "ctx1", output: `{"items": [{"metadata": {"name": "pod1"}}]}`, stderr: "Warning: deprecated"}}
+	tests := []struct {
+		name string
+		set  func(t *testing.T)
+	}{
+		{name: "show-warnings", set: func(t *testing.T) { setGlobal(t, &showWarnings, true) }},
+		{name: "check-version-skew", set: func(t *testing.T) { setGlobal(t, &checkVersionSkew, true) }},
+		{name: "show-source", set: func(t *testing.T) { setGlobal(t, &showSource, true) }},
+		{name: "context-group", set: func(t *testing.T) { setGlobal(t, &contextGroup, `^(?P<env>\w+)\d$`) }},
+		{name: "merge-strategy by-context", set: func(t *testing.T) { setGlobal(t, &mergeStrategy, mergeByContext) }},
+		{name: "strict-json", set: func(t *testing.T) { setGlobal(t, &strictJSON, true) }},
+	}
+
+	if _, ok := singleContextOutput(single); !ok {
+		t.Fatalf("singleContextOutput() should pass a single context through by default")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.set(t)
+			if _, ok := singleContextOutput(single); ok {
+				t.Errorf("singleContextOutput() passed the output through with --%s", tt.name)
+			}
+		})
+	}
+
+	t.Run("strict-json rejects invalid output", func(t *testing.T) {
+		setGlobal(t, &strictJSON, true)
+		captureStdout(t, func() {
+			if err := formatJSONOutput([]contextResult{{context: "ctx1", output: "not json"}}, "get"); err == nil {
+				t.Errorf("formatJSONOutput() printed invalid JSON with --strict-json")
+			}
+		})
+	})
+}
+
+func TestFormatOutputSortKeys(t *testing.T) {
+	setGlobal(t, &sortKeys, true)
+	tests := []struct {
+		name   string
+		format func([]contextResult, string) error
+		a, b   string
+		want   string
+	}{
+		{
+			name:   "json",
+			format: formatJSONOutput,
+			a:      `{"kind": "Pod", "apiVersion": "v1", "metadata": {"name": "pod1", "labels": {"z": "1", "a": "2"}}, "spec": {"replicas": 10000000}}`,
+			b:      `{"apiVersion": "v1", "spec": {"replicas": 10000000}, "metadata": {"labels": {"a": "2", "z": "1"}, "name": "pod1"}, "kind": "Pod"}`,
+			want:   `"replicas": 10000000`,
+		},
+		{
+			name:   "yaml",
+			format: formatYAMLOutput,
+			a:      "kind: Pod\napiVersion: v1\nmetadata:\n  name: pod1\n  labels:\n    z: \"1\"\n    a: \"2\"\n",
+			b:      "apiVersion: v1\nmetadata:\n  labels:\n    a: \"2\"\n    z: \"1\"\n  name: pod1\nkind: Pod\n",
+			want:   "apiVersion: v1\nkind: Pod\nmetadata:\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, contexts := range [][]string{{"ctx1"}, {"ctx1", "ctx2"}} {
+				render := func(output string) string {
+					var results []contextResult
+					for _, context := range contexts {
+						results = append(results, contextResult{context: context, output: output})
+					}
+					return captureStdout(t, func() {
+						if err := tt.format(results, "get"); err != nil {
+							t.Errorf("format error = %v", err)
+						}
+					})
+				}
+				a, b := render(tt.a), render(tt.b)
+				if a != b {
+					t.Errorf("%d contexts: output differs by input key order:\n%s\n%s", len(contexts), a, b)
+				}
+				if len(contexts) == 1 && !strings.Contains(a, tt.want) {
+					t.Errorf("single context output = %q, want %q", a, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatJSONPathOutput(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "pod1 pod2"},
+		{context: "production", output: "pod3\npod4\n"},
+		{context: "staging", output: ""},
+		{context: "broken", output: "Unable to connect to the server", err: fmt.Errorf("exit status 1")},
+	}
+
+	var output string
+	stderr := captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := formatJSONPathOutput(results); err != nil {
+				t.Errorf("formatJSONPathOutput() error = %v, want nil", err)
+			}
+		})
+	})
+
+	expected := "ctx1        pod1 pod2\n" +
+		"production  pod3\n" +
+		"production  pod4\n" +
+		"staging     <none>\n"
+	if output != expected {
+		t.Errorf("formatJSONPathOutput() output =\n%s\nwant\n%s", output, expected)
+	}
+	if !strings.Contains(stderr, "Context broken: Error") {
+		t.Errorf("stderr = %q, want the broken context's error", stderr)
+	}
+}
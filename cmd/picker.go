@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// pickContexts lets the operator choose among contexts on stderr before
+// anything runs, with answers read from promptInput. A line of text fuzzy
+// searches the list, numbers and ranges such as 1,3-5 toggle the listed
+// contexts, * toggles all of them, an empty line runs the selection and q
+// cancels. The picked contexts keep their order.
+func pickContexts(contexts []string) ([]string, error) {
+	if !stdinIsTerminal() {
+		return nil, fmt.Errorf("--interactive needs a terminal on stdin")
+	}
+	answers := promptAnswers()
+	selected := make(map[string]bool)
+	query := ""
+	for {
+		listed := fuzzyFilter(contexts, query)
+		for i, ctx := range listed {
+			mark := " "
+			if selected[ctx] {
+				mark = "x"
+			}
+			fmt.Fprintf(os.Stderr, "%3d [%s] %s\n", i+1, mark, ctx)
+		}
+		fmt.Fprintf(os.Stderr, "%d of %d contexts selected. Search, toggle (1,3-5 or *), Enter to run, q to quit: ", len(selected), len(contexts))
+
+		line, err := answers.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if err != nil && line == "" {
+			return nil, fmt.Errorf("interactive selection ended without picking contexts")
+		}
+
+		switch {
+		case line == "":
+			if len(selected) == 0 {
+				notef("Select at least one context")
+				continue
+			}
+			var picked []string
+			for _, ctx := range contexts {
+				if selected[ctx] {
+					picked = append(picked, ctx)
+				}
+			}
+			return picked, nil
+		case line == "q":
+			return nil, fmt.Errorf("interactive selection cancelled")
+		case line == "*":
+			all := true
+			for _, ctx := range listed {
+				all = all && selected[ctx]
+			}
+			for _, ctx := range listed {
+				toggle(selected, ctx, !all)
+			}
+		default:
+			indexes, ok := parseSelection(line, len(listed))
+			if !ok {
+				query = strings.TrimPrefix(line, "/") // "/" searches for digits or clears the search
+				continue
+			}
+			for _, i := range indexes {
+				toggle(selected, listed[i], !selected[listed[i]])
+			}
+		}
+	}
+}
+
+func toggle(selected map[string]bool, ctx string, on bool) {
+	if on {
+		selected[ctx] = true
+	} else {
+		delete(selected, ctx)
+	}
+}
+
+// parseSelection parses comma-separated 1-based numbers and ranges of the n
+// listed contexts into 0-based indexes
+func parseSelection(line string, n int) ([]int, bool) {
+	var indexes []int
+	for _, part := range strings.Split(line, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, false
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil {
+				return nil, false
+			}
+		}
+		if first < 1 || last > n || first > last {
+			return nil, false
+		}
+		for i := first; i <= last; i++ {
+			indexes = append(indexes, i-1)
+		}
+	}
+	return indexes, true
+}
+
+// fuzzyFilter returns the contexts containing the characters of query in
+// order, case-insensitive, such as pe for prod-eu
+func fuzzyFilter(contexts []string, query string) []string {
+	query = strings.ToLower(query)
+	var matched []string
+	for _, ctx := range contexts {
+		rest := query
+		for _, c := range strings.ToLower(ctx) {
+			if rest != "" && strings.HasPrefix(rest, string(c)) {
+				rest = rest[len(string(c)):]
+			}
+		}
+		if rest == "" {
+			matched = append(matched, ctx)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPickContexts(t *testing.T) {
+	contexts := []string{"dev", "prod-eu", "prod-us", "staging"}
+	tests := []struct {
+		name     string
+		answers  string
+		terminal bool
+		want     []string
+		wantErr  string
+	}{
+		{name: "numbers and ranges", answers: "1,3-4\n\n", terminal: true, want: []string{"dev", "prod-us", "staging"}},
+		{name: "search then toggle all", answers: "prod\n*\n\n", terminal: true, want: []string{"prod-eu", "prod-us"}},
+		{name: "fuzzy search", answers: "pus\n1\n\n", terminal: true, want: []string{"prod-us"}},
+		{name: "toggle off", answers: "1-2\n1\n\n", terminal: true, want: []string{"prod-eu"}},
+		{name: "empty selection is refused", answers: "\n2\n\n", terminal: true, want: []string{"prod-eu"}},
+		{name: "cancel", answers: "1\nq\n", terminal: true, wantErr: "cancelled"},
+		{name: "end of input", answers: "1\n", terminal: true, wantErr: "ended without picking"},
+		{name: "no terminal", answers: "1\n\n", wantErr: "needs a terminal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGlobal(t, &stdinIsTerminal, func() bool { return tt.terminal })
+			setGlobal[io.Reader](t, &promptInput, strings.NewReader(tt.answers))
+			var picked []string
+			var err error
+			captureStderr(t, func() {
+				picked, err = pickContexts(contexts)
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("pickContexts() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pickContexts() error = %v", err)
+			}
+			if !reflect.DeepEqual(picked, tt.want) {
+				t.Errorf("pickContexts() = %v, want %v", picked, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	contexts := []string{"gke_acme_prod-eu", "prod-us", "staging"}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "", want: contexts},
+		{query: "prod", want: []string{"gke_acme_prod-eu", "prod-us"}},
+		{query: "PEU", want: []string{"gke_acme_prod-eu"}},
+		{query: "sg", want: []string{"staging"}},
+		{query: "xyz", want: nil},
+	}
+	for _, tt := range tests {
+		if got := fuzzyFilter(contexts, tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("fuzzyFilter(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// executionPlan is what a run would do, as printed by --explain-plan
+type executionPlan struct {
+	Subcommand          string     `json:"subcommand"`
+	Concurrency         int        `json:"concurrency"`
+	SerializePerCluster bool       `json:"serializePerCluster"`
+	RerunFailed         int        `json:"rerunFailed"`
+	Timeout             string     `json:"timeout"`
+	Contexts            []planStep `json:"contexts"`
+}
+
+// planStep is a single kubectl invocation of the plan, in dispatch order
+type planStep struct {
+	Order   int      `json:"order"`
+	Context string   `json:"context"`
+	Cluster string   `json:"cluster,omitempty"`
+	Argv    []string `json:"argv"`
+}
+
+// buildPlan resolves the kubectl invocations for contexts without running them
+func buildPlan(contexts []string, subcommand string, extraArgs []string) executionPlan {
+	extraArgs, _, _ = rewriteOutputArgs(subcommand, extraArgs)
+	workers := batchSize
+	if workers < 1 {
+		workers = 1
+	}
+	plan := executionPlan{
+		Subcommand:          subcommand,
+		Concurrency:         min(workers, len(contexts)),
+		SerializePerCluster: serializePerCluster,
+		RerunFailed:         rerunFailed,
+		Timeout:             requestTimeout(append(append([]string{}, kubectlArgs...), extraArgs...)),
+	}
+
+	clusters, _ := getContextClusters() // the cluster is informational only
+	for n, i := range dispatchOrder(contexts) {
+		context := contexts[i]
+		plan.Contexts = append(plan.Contexts, planStep{
+			Order:   n + 1,
+			Context: context,
+			Cluster: clusters[context],
+			Argv:    append([]string{"kubectl"}, buildKubectlArgs(context, subcommand, extraArgs)...),
+		})
+	}
+	return plan
+}
+
+// requestTimeout returns the --request-timeout kubectl is given, or "none"
+func requestTimeout(args []string) string {
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--request-timeout="); ok {
+			return value
+		}
+		if arg == "--request-timeout" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return "none"
+}
+
+// printPlan prints the plan as JSON when -o json is given and as a table otherwise
+func printPlan(plan executionPlan, format outputFormat) error {
+	if format == formatJSON {
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal plan: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Subcommand:    %s\n", plan.Subcommand)
+	fmt.Printf("Concurrency:   %d\n", plan.Concurrency)
+	if plan.SerializePerCluster {
+		fmt.Println("Serialized:    one context per cluster at a time")
+	}
+	fmt.Printf("Rerun failed:  %d\n", plan.RerunFailed)
+	fmt.Printf("Timeout:       %s\n", plan.Timeout)
+	fmt.Println()
+
+	rows := [][]string{{"ORDER", contextHeader, "CLUSTER", "COMMAND"}}
+	for _, step := range plan.Contexts {
+		rows = append(rows, []string{fmt.Sprint(step.Order), step.Context, step.Cluster, strings.Join(step.Argv, " ")})
+	}
+	printTable(rows)
+	return nil
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec (POD | TYPE/NAME | -l SELECTOR) [-c CONTAINER] -- COMMAND [ARG...]",
+	Short: "Run a command in a pod of every context (requires --allow-exec)",
+	Long: `Run the same command in a pod of every context and collect its output, prefixed with the context and pod.
+With -l, the first running pod matching the selector is used in each context. The command must be read-only:
+nothing stops it from changing the pod, which is why exec requires --allow-exec.`,
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPodExec(args)
+	},
+}
+
+// podExec is an exec invocation: the pod, or the selector finding one, and
+// the arguments passed on to kubectl exec
+type podExec struct {
+	target    string
+	selector  string
+	namespace string
+	flags     []string
+	command   []string
+}
+
+// parsePodExec parses the exec arguments. Interactive flags are refused since
+// there is no terminal per context.
+func parsePodExec(args []string) (*podExec, error) {
+	dash := -1
+	for i, arg := range args {
+		if arg == "--" {
+			dash = i
+			break
+		}
+	}
+	if dash == -1 || dash == len(args)-1 {
+		return nil, fmt.Errorf("exec needs a command after --, e.g. exec -l app=web -- curl -s localhost:8080/healthz")
+	}
+
+	e := &podExec{command: args[dash+1:]}
+	for i := 0; i < dash; i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "-i", "-t", "-it", "-ti", "--stdin", "--tty":
+			return nil, fmt.Errorf("exec: %s is not supported, commands run without a terminal", name)
+		case "-l", "--selector", "-n", "--namespace":
+			if !hasValue {
+				if i+1 == dash {
+					return nil, fmt.Errorf("exec: flag %s needs a value", name)
+				}
+				i++
+				value = args[i]
+			}
+			if name == "-l" || name == "--selector" {
+				e.selector = value
+			} else {
+				e.namespace = value
+			}
+			continue
+		case "-c", "--container":
+			if !hasValue && i+1 < dash {
+				e.flags = append(e.flags, arg, args[i+1])
+				i++
+				continue
+			}
+		}
+		if !strings.HasPrefix(arg, "-") {
+			if e.target != "" {
+				return nil, fmt.Errorf("exec: only one pod can be given, got %s and %s", e.target, arg)
+			}
+			e.target = arg
+			continue
+		}
+		e.flags = append(e.flags, arg)
+	}
+	if (e.target == "") == (e.selector == "") {
+		return nil, fmt.Errorf("exec needs either a pod or a -l selector")
+	}
+	return e, nil
+}
+
+// args returns the kubectl exec arguments to run the command in pod
+func (e *podExec) args(pod string) []string {
+	var args []string
+	if e.namespace != "" {
+		args = append(args, "-n", e.namespace)
+	}
+	args = append(args, pod)
+	args = append(args, e.flags...)
+	return append(append(args, "--"), e.command...)
+}
+
+// runPodExec runs the command in a pod of every context and prints each line
+// of output as [context pod/NAME] line. The pod lookup and the exec run like
+// any other command, with --retries, --on-error and the other run options,
+// and share one --on-error policy.
+func runPodExec(args []string) error {
+	e, err := parsePodExec(args)
+	if err != nil {
+		return err
+	}
+	contexts, finish, err := setupRun("exec", args)
+	if err != nil {
+		return err
+	}
+	defer finish()
+	rewrite, _ := parseContextRewrite(contextRewriteExpr) // validated by setupRun
+
+	policy := newErrorPolicy()
+	pods := make(map[string]string, len(contexts))
+	results := make([]contextResult, len(contexts))
+	if e.selector != "" {
+		lookup := []string{"pods", "-l", e.selector, "--field-selector=status.phase=Running", "-o", "name"}
+		if e.namespace != "" {
+			lookup = append(lookup, "-n", e.namespace)
+		}
+		for i, result := range runContextsWithPolicy(contexts, "get", sameArgs(lookup), nil, policy) {
+			pods[result.context], _, _ = strings.Cut(strings.TrimSpace(result.output), "\n")
+			if result.err == nil && pods[result.context] == "" {
+				result.err = fmt.Errorf("no running pod matches -l %s", e.selector)
+			}
+			results[i] = result
+		}
+	} else {
+		for _, context := range contexts {
+			pods[context] = e.target
+		}
+	}
+
+	var found []string
+	for i, context := range contexts {
+		if results[i].err == nil {
+			found = append(found, context)
+		}
+	}
+	execs := runContextsWithPolicy(found, "exec", func(context string) []string {
+		return e.args(pods[context])
+	}, nil, policy)
+	for i, j := 0, 0; i < len(contexts); i++ {
+		if results[i].err != nil {
+			continue
+		}
+		results[i] = execs[j]
+		results[i].output = prefixLines(execs[j].output, pods[contexts[i]], execs[j].err)
+		j++
+	}
+
+	for i := range results {
+		results[i] = rewrite.result(results[i])
+	}
+	if err := formatOutput(results, formatDefault, "exec"); err != nil {
+		return err
+	}
+	return checkFailThreshold(results)
+}
+
+// prefixLines prefixes each line of a successful command's output with the
+// pod it ran in, as kubectl logs --prefix does, so it is printed as
+// [context pod/NAME] line
+func prefixLines(output, pod string, err error) string {
+	output = strings.TrimRight(output, "\n")
+	if err != nil || output == "" {
+		return output
+	}
+	if !strings.Contains(pod, "/") {
+		pod = "pod/" + pod
+	}
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		lines[i] = "[" + pod + "] " + line
+	}
+	return strings.Join(lines, "\n")
+}
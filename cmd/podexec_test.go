@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParsePodExec(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected podExec
+		errorMsg string
+	}{
+		{
+			name:     "selector",
+			args:     []string{"-l", "app=web", "-n", "apps", "-c", "nginx", "--", "curl", "-s", "localhost/healthz"},
+			expected: podExec{selector: "app=web", namespace: "apps", flags: []string{"-c", "nginx"}, command: []string{"curl", "-s", "localhost/healthz"}},
+		},
+		{
+			name:     "pod",
+			args:     []string{"deploy/web", "--container=nginx", "--", "date"},
+			expected: podExec{target: "deploy/web", flags: []string{"--container=nginx"}, command: []string{"date"}},
+		},
+		{name: "no command", args: []string{"-l", "app=web", "--"}, errorMsg: "needs a command after --"},
+		{name: "no pod", args: []string{"--", "date"}, errorMsg: "either a pod or a -l selector"},
+		{name: "pod and selector", args: []string{"web-0", "-l", "app=web", "--", "date"}, errorMsg: "either a pod or a -l selector"},
+		{name: "interactive", args: []string{"-it", "web-0", "--", "sh"}, errorMsg: "-it is not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := parsePodExec(tt.args)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("parsePodExec() error = %v, want %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePodExec() error = %v", err)
+			}
+			if fmt.Sprintf("%+v", *e) != fmt.Sprintf("%+v", tt.expected) {
+				t.Errorf("parsePodExec() = %+v, want %+v", *e, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunPodExec(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2", "ctx3")
+	setGlobal(t, &allowExec, true)
+	var execs []string
+	var mu sync.Mutex
+	fakeKubectl(t, func(args []string) (string, error) {
+		context := argValue(args, "--context")
+		command := strings.Join(args[2:], " ")
+		if strings.HasPrefix(command, "get pods") {
+			if context == "ctx3" {
+				return "", nil
+			}
+			return "pod/web-" + context + "\npod/web-other\n", nil
+		}
+		mu.Lock()
+		execs = append(execs, command)
+		mu.Unlock()
+		return "ok\n", nil
+	})
+
+	var stderr string
+	output := captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := execCmd.RunE(execCmd, []string{"-l", "app=web", "--", "curl", "localhost/healthz"}); err != nil {
+				t.Errorf("exec error = %v", err)
+			}
+		})
+	})
+
+	expected := "[ctx1 pod/web-ctx1] ok\n[ctx2 pod/web-ctx2] ok\n"
+	if output != expected {
+		t.Errorf("exec output = %q, want %q", output, expected)
+	}
+	if len(execs) != 2 || !strings.Contains(strings.Join(execs, ","), "exec pod/web-ctx1 -- curl localhost/healthz") {
+		t.Errorf("kubectl ran %v, want exec in the first matching pod of ctx1 and ctx2", execs)
+	}
+	if !strings.Contains(stderr, "Context ctx3: Error: no running pod matches -l app=web") {
+		t.Errorf("stderr = %q, want ctx3 reported without a pod", stderr)
+	}
+}
+
+func TestRunPodExecRunOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		retries  int
+		onError  string
+		expected string
+		skipped  bool
+	}{
+		{name: "retries", retries: 1, onError: onErrorContinue, expected: "[ctx1 pod/web-0] ok\n[ctx2 pod/web-0] ok\n"},
+		{name: "on-error stop", retries: 0, onError: onErrorStop, expected: "", skipped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeKubeconfig(t, "ctx1", "ctx2")
+			setGlobal(t, &allowExec, true)
+			setGlobal(t, &batchSize, 1)
+			setGlobal(t, &retries, tt.retries)
+			setGlobal(t, &retryBackoff, time.Millisecond)
+			setGlobal(t, &onError, tt.onError)
+			var mu sync.Mutex
+			calls := 0
+			fakeKubectl(t, func(args []string) (string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls++
+				if calls == 1 {
+					return "Unable to connect to the server: dial tcp: connection refused", fmt.Errorf("exit status 1")
+				}
+				return "ok\n", nil
+			})
+
+			var stderr string
+			output := captureStdout(t, func() {
+				stderr = captureStderr(t, func() {
+					runPodExec([]string{"web-0", "--", "date"})
+				})
+			})
+			if output != tt.expected {
+				t.Errorf("exec output = %q, want %q", output, tt.expected)
+			}
+			if skipped := strings.Contains(stderr, "Context ctx2: Error: skipped after context ctx1 failed"); skipped != tt.skipped {
+				t.Errorf("ctx2 skipped = %v, want %v; stderr:\n%s", skipped, tt.skipped, stderr)
+			}
+		})
+	}
+}
+
+func TestRunPodExecRequiresAllowExec(t *testing.T) {
+	fakeKubectl(t, func(args []string) (string, error) {
+		t.Errorf("kubectl ran %v without --allow-exec", args)
+		return "", nil
+	})
+	for _, run := range []func() error{
+		func() error { return execCmd.RunE(execCmd, []string{"web-0", "--", "date"}) },
+		func() error { return runCommand("exec", []string{"web-0", "--", "date"}) },
+	} {
+		if err := run(); err == nil || !strings.Contains(err.Error(), "--allow-exec") {
+			t.Errorf("exec error = %v, want --allow-exec required", err)
+		}
+	}
+}
+
+func TestRunPodExecSharedChecks(t *testing.T) {
+	writeKubeconfig(t, "ctx1")
+	setGlobal(t, &allowExec, true)
+	fakeKubectl(t, func(args []string) (string, error) {
+		t.Errorf("kubectl ran %v despite invalid flags", args)
+		return "", nil
+	})
+
+	setGlobal(t, &retries, -1)
+	if err := runPodExec([]string{"web-0", "--", "date"}); err == nil || !strings.Contains(err.Error(), "invalid --retries") {
+		t.Errorf("exec error = %v, want --retries rejected", err)
+	}
+	setGlobal(t, &retries, 0)
+	setGlobal(t, &backend, backendNative)
+	if err := runPodExec([]string{"web-0", "--", "date"}); err == nil || !strings.Contains(err.Error(), "--backend=native") {
+		t.Errorf("exec error = %v, want --backend=native rejected", err)
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// retryBackoff is the delay before the first --retries attempt, doubled for
+// each further attempt. It is a variable so tests can shorten it.
+var retryBackoff = time.Second
+
+// maxRetryBackoff caps the delay between --retries attempts
+const maxRetryBackoff = 30 * time.Second
+
+// transientMarkers are the messages of failures that are likely to go away
+// on their own: unreachable or overloaded API servers and 5xx responses.
+// Throttling is recognized by throttleMarkers.
+var transientMarkers = []string{
+	"connection refused",
+	"connection reset by peer",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"Unable to connect to the server",
+	"the server is currently unable to handle the request",
+	"Error from server (InternalError)",
+	"Error from server (ServiceUnavailable)",
+	"Error from server (Timeout)",
+	"status code 500",
+	"status code 502",
+	"status code 503",
+	"status code 504",
+}
+
+// spentRetries counts the --retries attempts and --rerun-failed reruns of
+// the current iteration, which together may not exceed --retry-budget
+var spentRetries retryCounter
+
+type retryCounter struct {
+	mu    sync.Mutex
+	spent int
+	noted bool
+}
+
+// reset starts a new iteration with the whole budget
+func (c *retryCounter) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spent, c.noted = 0, false
+}
+
+// take spends up to n retries of the budget and returns how many it got
+func (c *retryCounter) take(n int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if retryBudget > 0 {
+		n = min(n, retryBudget-c.spent)
+	}
+	c.spent += n
+	return n
+}
+
+// noteExhausted tells, once per iteration, that --retries attempts stopped
+// because the budget ran out
+func (c *retryCounter) noteExhausted(context string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.noted {
+		c.noted = true
+		notef("Retry budget of %d exhausted: context %s and later transient failures not retried", retryBudget, context)
+	}
+}
+
+// isTransient reports whether a failed kubectl invocation is worth retrying
+func isTransient(stdout, stderr string, err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, marker := range transientMarkers {
+		if strings.Contains(stdout, marker) || strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return isThrottled(stderr, err)
+}
+
+// runKubectlWithRetries runs kubectl for a context, retrying transient
+// failures up to --retries times with exponential backoff while --retry-budget
+// allows. It returns the
+// last attempt's result, with the number of attempts made and whether any
+// of them was throttled. No retry is made once ctx is cancelled.
+func runKubectlWithRetries(ctx context.Context, context, subcommand string, extraArgs []string) contextResult {
+	backoff := retryBackoff
+	throttled := false
+	for attempts := 1; ; attempts++ {
+		stdout, stderr, err := kubectlExec(ctx, buildKubectlArgs(context, subcommand, extraArgs))
+		throttled = throttled || isThrottled(stderr, err)
+		result := contextResult{context: context, output: stdout, stderr: stderr, err: err, attempts: attempts, throttled: throttled}
+		if err != nil {
+			// As in runKubectlCommand, stderr is part of a failure's output
+			result.output, result.stderr = stdout+stderr, ""
+		}
+		if !isTransient(stdout, stderr, err) || attempts > retries {
+			return result
+		}
+		if spentRetries.take(1) == 0 {
+			spentRetries.noteExhausted(context)
+			return result
+		}
+		notef("Context %s failed with a transient error, retrying in %s (attempt %d of %d)", context, backoff, attempts+1, retries+1)
+		runLog.Info("retrying context", "context", context, "attempt", attempts+1, "backoff_ms", backoff.Milliseconds())
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return result
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
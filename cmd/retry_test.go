@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunContextRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		retries      int
+		failures     int
+		output       string
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "no retries by default", retries: 0, failures: 1, output: "Unable to connect to the server: dial tcp: connection refused", wantAttempts: 1, wantErr: true},
+		{name: "transient failure recovers", retries: 3, failures: 2, output: "Unable to connect to the server: dial tcp: connection refused", wantAttempts: 3},
+		{name: "server error recovers", retries: 1, failures: 1, output: "Error from server (ServiceUnavailable): the server is currently unable to handle the request", wantAttempts: 2},
+		{name: "throttling recovers", retries: 1, failures: 1, output: "Error from server (TooManyRequests): slow down", wantAttempts: 2},
+		{name: "retries exhausted", retries: 2, failures: 5, output: "error: net/http: TLS handshake timeout", wantAttempts: 3, wantErr: true},
+		{name: "permanent failure not retried", retries: 3, failures: 1, output: `Error from server (Forbidden): pods is forbidden`, wantAttempts: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGlobal(t, &retries, tt.retries)
+			setGlobal(t, &retryBackoff, time.Millisecond)
+			var mu sync.Mutex
+			calls := 0
+			setGlobal(t, &kubectlExec, func(_ context.Context, args []string) (string, string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls++
+				if calls <= tt.failures {
+					return "", tt.output, fmt.Errorf("exit status 1")
+				}
+				return "NAME\npod1\n", "", nil
+			})
+
+			var result contextResult
+			stderr := captureStderr(t, func() {
+				result = runContext(context.Background(), "ctx1", "get", []string{"pods"}, nil)
+			})
+			if result.attempts != tt.wantAttempts || calls != tt.wantAttempts {
+				t.Errorf("attempts = %d, kubectl calls = %d, want %d", result.attempts, calls, tt.wantAttempts)
+			}
+			if (result.err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", result.err, tt.wantErr)
+			}
+			if retried := strings.Count(stderr, "retrying in"); retried != tt.wantAttempts-1 {
+				t.Errorf("stderr = %q, want %d retry notes", stderr, tt.wantAttempts-1)
+			}
+		})
+	}
+}
+
+func TestRunCommandRetriesInvalid(t *testing.T) {
+	setGlobal(t, &retries, -1)
+	if err := runCommand("get", []string{"pods"}); err == nil || !strings.Contains(err.Error(), "invalid --retries") {
+		t.Errorf("runCommand() error = %v, want invalid --retries", err)
+	}
+}
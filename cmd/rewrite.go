@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// contextRewrite is a parsed --context-rewrite expression
+type contextRewrite struct {
+	regex       *regexp.Regexp
+	replacement string // in regexp.Expand syntax
+	global      bool
+}
+
+// parseContextRewrite parses a sed-like s/pattern/replacement/ expression,
+// with an optional g flag to replace every match instead of the first. Any
+// character may stand in for /. The replacement may refer to groups as \1
+// and to the whole match as &. An empty expression returns nil.
+func parseContextRewrite(expr string) (*contextRewrite, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	if len(expr) < 2 || expr[0] != 's' {
+		return nil, fmt.Errorf("invalid --context-rewrite %q: must be s/pattern/replacement/", expr)
+	}
+	delim := expr[1]
+	var parts []string
+	var part strings.Builder
+	for i := 2; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == '\\' && i+1 < len(expr) && expr[i+1] == delim:
+			part.WriteByte(delim)
+			i++
+		case c == delim:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(c)
+		}
+	}
+	parts = append(parts, part.String())
+	if len(parts) != 3 || (parts[2] != "" && parts[2] != "g") {
+		return nil, fmt.Errorf("invalid --context-rewrite %q: must be s/pattern/replacement/ with an optional g flag", expr)
+	}
+
+	regex, err := regexp.Compile(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid --context-rewrite pattern %q: %w", parts[0], err)
+	}
+	return &contextRewrite{regex: regex, replacement: expandSyntax(parts[1]), global: parts[2] == "g"}, nil
+}
+
+// expandSyntax converts a sed replacement to regexp.Expand syntax
+func expandSyntax(replacement string) string {
+	var b strings.Builder
+	for i := 0; i < len(replacement); i++ {
+		c := replacement[i]
+		switch {
+		case c == '\\' && i+1 < len(replacement):
+			i++
+			if next := replacement[i]; next >= '0' && next <= '9' {
+				fmt.Fprintf(&b, "${%c}", next)
+			} else if next == '$' {
+				b.WriteString("$$")
+			} else {
+				b.WriteByte(next)
+			}
+		case c == '&':
+			b.WriteString("${0}")
+		case c == '$':
+			b.WriteString("$$")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// apply returns the displayed name of a context. Names the pattern doesn't
+// match, or that would be rewritten to nothing, are kept.
+func (r *contextRewrite) apply(context string) string {
+	if r == nil {
+		return context
+	}
+	var rewritten string
+	if r.global {
+		rewritten = r.regex.ReplaceAllString(context, r.replacement)
+	} else {
+		match := r.regex.FindStringSubmatchIndex(context)
+		if match == nil {
+			return context
+		}
+		expanded := r.regex.ExpandString(nil, r.replacement, context, match)
+		rewritten = context[:match[0]] + string(expanded) + context[match[1]:]
+	}
+	if rewritten == "" {
+		return context
+	}
+	return rewritten
+}
+
+// result returns a context's result to display under its rewritten name
+func (r *contextRewrite) result(result contextResult) contextResult {
+	if name := r.apply(result.context); name != result.context {
+		result.original = result.context
+		result.context = name
+	}
+	return result
+}
+
+// kubeconfigName returns the name of the context in kubeconfig, before any
+// --context-rewrite
+func (r contextResult) kubeconfigName() string {
+	if r.original != "" {
+		return r.original
+	}
+	return r.context
+}
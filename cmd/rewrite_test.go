@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestContextRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		context  string
+		expected string
+		wantErr  bool
+	}{
+		{name: "strip prefix", expr: `s/^gke_[^_]+_[^_]+_//`, context: "gke_acme-prod_europe-west1_payments", expected: "payments"},
+		{name: "no match", expr: `s/^gke_[^_]+_[^_]+_//`, context: "kind-local", expected: "kind-local"},
+		{name: "groups", expr: `s|^arn:aws:eks:([^:]+):\d+:cluster/(.+)$|\2 (\1)|`, context: "arn:aws:eks:us-east-1:123456789012:cluster/prod", expected: "prod (us-east-1)"},
+		{name: "whole match", expr: `s/prod/[&]/`, context: "prod-eu", expected: "[prod]-eu"},
+		{name: "first match only", expr: `s/-/./`, context: "a-b-c", expected: "a.b-c"},
+		{name: "global", expr: `s/-/./g`, context: "a-b-c", expected: "a.b.c"},
+		{name: "escaped delimiter", expr: `s/\//-/g`, context: "team/prod", expected: "team-prod"},
+		{name: "empty result kept", expr: `s/.*//`, context: "ctx1", expected: "ctx1"},
+		{name: "not a substitution", expr: `^gke_`, wantErr: true},
+		{name: "missing delimiter", expr: `s/^gke_/`, wantErr: true},
+		{name: "unknown flag", expr: `s/a/b/i`, wantErr: true},
+		{name: "invalid pattern", expr: `s/(/x/`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewrite, err := parseContextRewrite(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseContextRewrite(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := rewrite.apply(tt.context); got != tt.expected {
+				t.Errorf("apply(%q) = %q, want %q", tt.context, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunCommandContextRewrite(t *testing.T) {
+	writeKubeconfig(t, "gke_acme_europe-west1_payments", "kind-local")
+	setGlobal(t, &contextRewriteExpr, `s/^gke_[^_]+_[^_]+_//`)
+	setGlobal(t, &keepContext, true)
+	var targeted []string
+	var mu sync.Mutex
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		targeted = append(targeted, argValue(args, "--context"))
+		return `{"items": [{"metadata": {"name": "pod1"}}]}`, nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods", "-o", "json"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	sort.Strings(targeted)
+	if strings.Join(targeted, ",") != "gke_acme_europe-west1_payments,kind-local" {
+		t.Errorf("targeted %v, want the kubeconfig names", targeted)
+	}
+	for _, want := range []string{`"multi/context": "payments"`, `"multi/context": "kind-local"`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output = %s, want %s", output, want)
+		}
+	}
+	if strings.Contains(output, "gke_") {
+		t.Errorf("output = %s, want rewritten context names", output)
+	}
+}
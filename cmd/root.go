@@ -1,11 +1,99 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 )
 
 var batchSize int = 25
 var filterPatterns []string
+var excludePatterns []string
+var groupNames []string
+var explicitContexts []string
+var kubeconfigFlag string
+var interactive bool
+var kubectlArgs []string
+var outputDir string
+var aggregateStatus bool
+var headerFrom string
+var rerunFailed int
+var absoluteAges bool
+var serverOnly bool
+var requiredNamespace string
+var tableStyle string = tableStylePlain
+var showOwners bool
+var barrier string
+var contextGroup string
+var strictJSON bool
+var maxLogLines int
+var failuresOnly bool
+var filterExact bool
+var durationFormat string = durationKube
+var startAfter string
+var contextLimit int
+var prettyErrors bool
+var timeline bool
+var eventType string
+var contextPlacement = placementAnnotation
+var serializePerCluster bool
+var trimEmpty bool
+var failThreshold string
+var kubeContextEnv = "KUBECTL_MULTI_CONTEXTS"
+var sortOrder = sortByName
+var showSource bool
+var maxColWidth string
+var logFormat string = logFormatText
+var logFile string
+var splitByContext bool
+var probePath string = "/readyz"
+var showWarnings bool
+var prefixFilters []string
+var suffixFilters []string
+var clusterFilters []string
+var userFilters []string
+var mergeStrategy string = mergeAll
+var retryBudget int
+var retries int
+var watchInterval time.Duration
+var contextFileWatch bool
+var rawKubectl bool
+var requireCurrentContext bool
+var validateKubeconfigFlag bool
+var warnSelectors bool
+var priorityContexts []string
+var summaryOnly bool
+var contextKubeconfigs []string
+var explainPlan bool
+var whereExpr string
+var contextHeader string = "CONTEXT"
+var batchDeadline time.Duration
+var resourceSummary bool
+var summarize bool
+var colorByContext string = colorAuto
+var expectContextsFile string
+var showSpinner bool
+var showKind bool
+var contextOrderFile string
+var keepContext bool
+var fleetCapacity bool
+var topTotals bool
+var resourceMatrix bool
+var allowExec bool
+var compareBase string
+var sortColumn string
+var groupBy string = groupByContext
+var onError string = onErrorContinue
+var sortKeys bool
+var contextRewriteExpr string
+var checkVersionSkew bool
+var hashResults bool
+var captureRaw string
+var inputFile string
+var streamOutput bool
+var backend string = backendKubectl
+var nativeQPS float32 = 50
+var nativeBurst int = 300
 
 var rootCmd = &cobra.Command{
 	Use:              "kubectl multi-context",
@@ -20,7 +108,107 @@ func Execute() error {
 
 func init() {
 	rootCmd.PersistentFlags().IntVarP(&batchSize, "batch-size", "b", 25, "Number of contexts to process in parallel")
+	rootCmd.PersistentFlags().IntVar(&batchSize, "max-parallel", 25, "Maximum number of kubectl processes to run at once; same as --batch-size")
 	rootCmd.PersistentFlags().StringArrayVar(&filterPatterns, "filter", []string{}, "Filter contexts by name using regex pattern (can be specified multiple times for OR logic)")
+	rootCmd.PersistentFlags().StringVar(&kubeconfigFlag, "kubeconfig", "", "Path to the kubeconfig file to read contexts from and pass to every kubectl invocation, instead of KUBECONFIG or ~/.kube/config")
+	rootCmd.PersistentFlags().StringSliceVar(&explicitContexts, "context", []string{}, "Run against exactly these contexts, comma-separated or repeated, instead of filtering the kubeconfig")
+	rootCmd.PersistentFlags().StringArrayVar(&groupNames, "group", []string{}, "Use the contexts of this group from ~/.config/kubectl-multi-context/config.yaml (can be specified multiple times)")
+	rootCmd.PersistentFlags().StringArrayVar(&excludePatterns, "exclude", []string{}, "Skip contexts whose name matches this regex pattern, after the other filters (can be specified multiple times)")
+	rootCmd.PersistentFlags().StringArrayVar(&kubectlArgs, "kubectl-arg", []string{}, "Extra argument passed to every kubectl invocation, e.g. --kubectl-arg=--request-timeout=5s (can be specified multiple times)")
+	rootCmd.PersistentFlags().StringVar(&outputDir, "output-dir", "", "Also write each context's raw output to its own file in this directory")
+	rootCmd.PersistentFlags().BoolVar(&aggregateStatus, "aggregate-status", false, "For get pods, print a per-context count of pods by status instead of the pod list")
+	rootCmd.PersistentFlags().StringVar(&headerFrom, "header-from", "", "Use the table header from this context instead of the first context with output")
+	rootCmd.PersistentFlags().IntVar(&rerunFailed, "rerun-failed", 0, "Rerun only the failed contexts up to this many extra passes")
+	rootCmd.PersistentFlags().BoolVar(&absoluteAges, "absolute-ages", false, "Rewrite AGE columns to absolute UTC timestamps")
+	rootCmd.PersistentFlags().BoolVar(&serverOnly, "server-only", false, "For version, only print the per-context server version table")
+	rootCmd.PersistentFlags().StringVar(&requiredNamespace, "require-namespace", "", "Skip contexts that do not have this namespace")
+	rootCmd.PersistentFlags().StringVar(&tableStyle, "table-style", tableStylePlain, "Table style for default output: plain or box")
+	rootCmd.PersistentFlags().BoolVar(&showOwners, "show-owners", false, "For JSON/YAML output, replace managedFields with a multi/owners annotation listing the field managers")
+	rootCmd.PersistentFlags().StringVar(&barrier, "barrier", barrierNone, "Hold back streamed output until all contexts are dispatched (dispatch) or finished (complete)")
+	rootCmd.PersistentFlags().Lookup("barrier").NoOptDefVal = barrierDispatch
+	rootCmd.PersistentFlags().StringVar(&contextGroup, "context-group", "", "Regex with named capture groups used to group contexts, e.g. '^(?P<env>\\w+)-(?P<region>[\\w-]+)$'")
+	rootCmd.PersistentFlags().BoolVar(&strictJSON, "strict-json", false, "Fail instead of skipping contexts whose output is not valid JSON")
+	rootCmd.PersistentFlags().IntVar(&maxLogLines, "max-log-lines-per-context", 0, "For logs, truncate each context's output after this many lines (0 for no limit)")
+	rootCmd.PersistentFlags().BoolVar(&failuresOnly, "failures-only", false, "Only print a table of the contexts that failed, exiting non-zero if any did")
+	rootCmd.PersistentFlags().BoolVar(&filterExact, "filter-exact", false, "Match --filter values against the full context name instead of as regex patterns")
+	rootCmd.PersistentFlags().StringVar(&durationFormat, "duration-format", durationKube, "How AGE columns are rendered: kube, hours or human")
+	rootCmd.PersistentFlags().StringVar(&startAfter, "start-after", "", "Sort contexts by name and only run those after this one, to resume a chunked run")
+	rootCmd.PersistentFlags().IntVar(&contextLimit, "limit", 0, "Only run against this many contexts (sorted by name), to process a fleet in chunks")
+	rootCmd.PersistentFlags().BoolVar(&prettyErrors, "pretty-errors", false, "Report failed contexts as a CONTEXT/CODE/MESSAGE table instead of raw kubectl output")
+	rootCmd.PersistentFlags().BoolVar(&timeline, "timeline", false, "For get events, print one chronological stream of events from all contexts")
+	rootCmd.PersistentFlags().StringVar(&eventType, "event-type", "", "With --timeline, only show events of this type, e.g. Warning")
+	rootCmd.PersistentFlags().StringVar(&contextPlacement, "context-placement", contextPlacement, "Where JSON and YAML output record each item's context: metadata, annotation or sibling")
+	rootCmd.PersistentFlags().BoolVar(&serializePerCluster, "serialize-per-cluster", false, "Run at most one context per cluster at a time; different clusters still run in parallel")
+	rootCmd.PersistentFlags().BoolVar(&trimEmpty, "trim-empty", false, "Remove null values and empty maps and lists from JSON and YAML output")
+	rootCmd.PersistentFlags().StringVar(&failThreshold, "fail-threshold", "", "Exit non-zero when more contexts fail than this count (e.g. 3) or percentage (e.g. 10%)")
+	rootCmd.PersistentFlags().StringVar(&kubeContextEnv, "kube-context-env", kubeContextEnv, "Environment variable with a comma-separated list of contexts to use when no --filter is given")
+	rootCmd.PersistentFlags().StringVar(&sortOrder, "sort-contexts", sortOrder, "Order contexts are run and listed in: name, cluster or current-first")
+	rootCmd.PersistentFlags().BoolVar(&showSource, "show-source", false, "Show the kubeconfig file each context was read from in the contexts listing and JSON/YAML output")
+	rootCmd.PersistentFlags().StringVar(&maxColWidth, "max-col-width", "", "Ellipsize table cells longer than this many characters, or auto to fit the terminal width")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormat, "Format of the tool's own logs: text, or json for structured records of each context's start, finish and errors")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "With --log-format=json, write log records to this file instead of stderr")
+	rootCmd.PersistentFlags().BoolVar(&splitByContext, "split-by-context", false, "With -o yaml, group the items of each context under a comment naming the context")
+	rootCmd.PersistentFlags().StringVar(&probePath, "probe-path", probePath, "API server path the health command requests, e.g. /readyz?verbose or /livez")
+	rootCmd.PersistentFlags().BoolVar(&showWarnings, "show-warnings", false, "Report what kubectl printed on stderr for successful contexts, such as deprecation warnings")
+	rootCmd.PersistentFlags().StringArrayVar(&prefixFilters, "prefix", []string{}, "Only use contexts whose name starts with this prefix, case-insensitive (can be specified multiple times for OR logic)")
+	rootCmd.PersistentFlags().StringArrayVar(&suffixFilters, "suffix", []string{}, "Only use contexts whose name ends with this suffix, case-insensitive (can be specified multiple times for OR logic)")
+	rootCmd.PersistentFlags().StringArrayVar(&clusterFilters, "cluster-filter", []string{}, "Only use contexts whose kubeconfig cluster matches this regex pattern, case-insensitive (can be specified multiple times for OR logic)")
+	rootCmd.PersistentFlags().StringArrayVar(&userFilters, "user-filter", []string{}, "Only use contexts whose kubeconfig user matches this regex pattern, case-insensitive (can be specified multiple times for OR logic)")
+	rootCmd.PersistentFlags().StringVar(&mergeStrategy, "merge-strategy", mergeStrategy, "How JSON and YAML output merges items: all, unique (dedupe identical objects across contexts) or by-context (one sub-list per context)")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 0, "Retry a context up to this many times, with exponential backoff, when it fails with a transient error such as connection refused, a 5xx or throttling")
+	rootCmd.PersistentFlags().IntVar(&retryBudget, "retry-budget", 0, "Cap the total number of --retries attempts and --rerun-failed reruns across the run (0 for no cap)")
+	rootCmd.PersistentFlags().DurationVar(&watchInterval, "watch-interval", 0, "Rerun the command at this interval, e.g. 30s, until interrupted")
+	rootCmd.PersistentFlags().BoolVar(&contextFileWatch, "context-file-watch", false, "With --watch-interval, pick up contexts added to or removed from the kubeconfig between runs")
+	rootCmd.PersistentFlags().BoolVar(&rawKubectl, "raw-kubectl", false, "Print kubectl's output untouched, each line prefixed with [context], instead of merging tables")
+	rootCmd.PersistentFlags().BoolVar(&requireCurrentContext, "require-current-context", false, "Refuse to run against more than one context unless they are selected with --interactive, --context, --group, --filter, --prefix, --suffix, --cluster-filter, --user-filter or the --kube-context-env variable")
+	rootCmd.PersistentFlags().BoolVar(&validateKubeconfigFlag, "validate-kubeconfig", false, "Validate the kubeconfig with kubectl's full rules, e.g. that every context's cluster and user exist, before running")
+	rootCmd.PersistentFlags().BoolVar(&warnSelectors, "warn-unsupported-selectors", false, "Print a prominent warning for every context that does not support the --field-selector used")
+	rootCmd.PersistentFlags().StringArrayVar(&priorityContexts, "priority", []string{}, "Dispatch this context before the others, as CONTEXT or CONTEXT=WEIGHT with higher weights (at least 1) first (can be specified multiple times)")
+	rootCmd.PersistentFlags().BoolVar(&summaryOnly, "summary-only", false, "For version, print a single line grouping the contexts by server version instead of the table")
+	rootCmd.PersistentFlags().StringArrayVar(&contextKubeconfigs, "context-kubeconfig", []string{}, "Run a context with its own kubeconfig file, as CONTEXT=PATH, adding it to the contexts if needed (can be specified multiple times)")
+	rootCmd.PersistentFlags().BoolVar(&explainPlan, "explain-plan", false, "Print the resolved contexts, dispatch order and kubectl argv per context, then exit without running anything")
+	rootCmd.PersistentFlags().StringVar(&whereExpr, "where", "", "With -o json or yaml, keep only the items matching PATH OPERATOR VALUE, e.g. '.status.containerStatuses[0].restartCount > 5'")
+	rootCmd.PersistentFlags().StringVar(&contextHeader, "context-header", "CONTEXT", "Header of the context column in text output, e.g. CLUSTER; JSON and YAML keep the --context-placement key")
+	rootCmd.PersistentFlags().DurationVar(&batchDeadline, "batch-deadline", 0, "Run contexts in consecutive batches of --batch-size and mark those still running after this long as timed out, e.g. 30s")
+	rootCmd.PersistentFlags().BoolVar(&resourceSummary, "resource-summary", false, "For get, print a count of objects by kind per context instead of the objects, e.g. with get all")
+	rootCmd.PersistentFlags().BoolVar(&summarize, "summarize", false, "For get, print the number of objects per context and in total instead of the objects")
+	rootCmd.PersistentFlags().StringVar(&colorByContext, "color-by-context", colorAuto, "Color each context name with a color derived from the name: auto (when writing to a terminal), always or never")
+	rootCmd.PersistentFlags().Lookup("color-by-context").NoOptDefVal = colorAlways
+	rootCmd.PersistentFlags().StringVar(&expectContextsFile, "expect-contexts-file", "", "Fail before running unless the selected contexts are exactly those listed in this file, one per line or as printed by the contexts command")
+	rootCmd.PersistentFlags().BoolVar(&showSpinner, "spinner", false, "Show a single self-erasing line with the contexts in flight and the elapsed time on stderr, when it is a terminal")
+	rootCmd.PersistentFlags().BoolVar(&showKind, "show-kind", false, "For get, add a KIND column and merge the output into one table per kind, e.g. with get all")
+	rootCmd.PersistentFlags().StringVar(&contextOrderFile, "context-order-file", "", "Run and list contexts in the order of this file, one per line; contexts it doesn't list follow in --sort-contexts order")
+	rootCmd.PersistentFlags().BoolVar(&keepContext, "keep-context", false, "With -o json or yaml, record the context and wrap the items in a List even when running against a single context")
+	rootCmd.PersistentFlags().BoolVar(&fleetCapacity, "fleet-capacity", false, "For top nodes, print the used and allocatable CPU and memory of every context and the fleet instead of the nodes")
+	rootCmd.PersistentFlags().BoolVar(&topTotals, "totals", false, "For top nodes and top pods, add a TOTAL row with the CPU and memory of each context")
+	rootCmd.PersistentFlags().BoolVar(&resourceMatrix, "matrix", false, "For api-resources, print which resources exist in which context instead")
+	rootCmd.PersistentFlags().BoolVar(&allowExec, "allow-exec", false, "Allow the exec command to run a command in a pod of every context")
+	rootCmd.PersistentFlags().StringVar(&compareBase, "base", "", "For compare, the context the others are diffed against (default the first)")
+	rootCmd.PersistentFlags().StringVar(&sortColumn, "sort-by-column", "", "Sort the rows of all contexts together by this table column, e.g. RESTARTS or AGE")
+	rootCmd.PersistentFlags().StringVar(&groupBy, "group-by", groupBy, "Group the rows of the default output by context or namespace")
+	rootCmd.PersistentFlags().StringVar(&onError, "on-error", onErrorContinue, "What to do when a context fails: continue with the others, stop (skip the contexts that have not started) or prompt (ask, when stdin is a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&sortKeys, "sort-keys", false, "With -o json or yaml, sort map keys alphabetically in single-context output too, for stable, diffable snapshots")
+	rootCmd.PersistentFlags().StringVar(&contextRewriteExpr, "context-rewrite", "", "Rewrite context names for display with a sed-like s/pattern/replacement/ Go regexp, e.g. 's/^gke_[^_]+_[^_]+_//'; contexts are still targeted by their kubeconfig names")
+	rootCmd.PersistentFlags().BoolVar(&checkVersionSkew, "check-version-skew", false, "Before running, warn about contexts whose server is more than one minor version away from kubectl, and annotate their JSON and YAML items")
+	rootCmd.PersistentFlags().BoolVar(&hashResults, "result-hash", false, "Print a CONTEXT  HASH table with a digest of each context's output, ignoring volatile fields, to spot drift between clusters")
+	rootCmd.PersistentFlags().StringVar(&captureRaw, "capture-raw", "", "Record the contexts and every kubectl invocation of the run to this file, for replay with --input-file")
+	rootCmd.PersistentFlags().StringVar(&inputFile, "input-file", "", "Replay the kubectl invocations recorded by --capture-raw instead of contacting any cluster, and format them as usual")
+	rootCmd.PersistentFlags().BoolVar(&interactive, "interactive", false, "Pick the contexts to run against from a searchable list on the terminal before anything runs")
+	rootCmd.PersistentFlags().BoolVar(&streamOutput, "stream", false, "For plain table output, print each context's rows as soon as it completes instead of when all contexts finish")
+	rootCmd.PersistentFlags().StringVar(&backend, "backend", backendKubectl, "How to reach the clusters: kubectl runs the kubectl binary, native uses client-go directly and supports get and version")
+	rootCmd.PersistentFlags().Float32Var(&nativeQPS, "qps", 50, "For --backend=native, the maximum queries per second to each API server")
+	rootCmd.PersistentFlags().IntVar(&nativeBurst, "burst", 300, "For --backend=native, the maximum burst of queries to each API server")
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(describeCmd)
+	rootCmd.AddCommand(eventsCmd)
+	rootCmd.AddCommand(apiResourcesCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(contextsCmd)
+	rootCmd.AddCommand(healthCmd)
+	rootCmd.AddCommand(kustomizeCmd)
+	rootCmd.AddCommand(topCmd)
 }
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxMinorSkew is how many minor versions kubectl supports on either side of
+// the server's
+const maxMinorSkew = 1
+
+// versionPattern matches the major and minor version in both the short
+// (v1.29.0) and the older struct (GitVersion:"v1.27.3") kubectl version output
+var versionPattern = regexp.MustCompile(`v(\d+)\.(\d+)`)
+
+// versionSkewNotes runs kubectl version against the contexts and returns a
+// note for each context whose server is too far from kubectl, printing it on
+// stderr. Contexts whose version can't be fetched are left to the command
+// itself to report.
+func versionSkewNotes(contexts []string) map[string]string {
+	notes := make(map[string]string)
+	for _, result := range runContexts(contexts, "version", nil) {
+		if result.err != nil {
+			continue
+		}
+		client, server := parseClientVersion(result.output), parseServerVersion(result.output)
+		if note := versionSkew(client, server); note != "" {
+			notes[result.context] = note
+			notef("Context %s: %s", result.context, note)
+		}
+	}
+	return notes
+}
+
+// parseClientVersion extracts the client version from kubectl version output
+func parseClientVersion(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if version, ok := strings.CutPrefix(strings.TrimSpace(line), "Client Version:"); ok {
+			return strings.TrimSpace(version)
+		}
+	}
+	return ""
+}
+
+// versionSkew returns a note when the client and server versions are more
+// than maxMinorSkew minor versions apart, or nothing when they are within
+// the supported skew or either can't be parsed
+func versionSkew(client, server string) string {
+	clientMajor, clientMinor, ok := majorMinor(client)
+	if !ok {
+		return ""
+	}
+	serverMajor, serverMinor, ok := majorMinor(server)
+	if !ok {
+		return ""
+	}
+	skew := clientMinor - serverMinor
+	if skew < 0 {
+		skew = -skew
+	}
+	if clientMajor == serverMajor && skew <= maxMinorSkew {
+		return ""
+	}
+	return fmt.Sprintf("kubectl v%d.%d is outside the supported skew of server v%d.%d; output may be unreliable",
+		clientMajor, clientMinor, serverMajor, serverMinor)
+}
+
+func majorMinor(version string) (major, minor int, ok bool) {
+	match := versionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return 0, 0, false
+	}
+	major, _ = strconv.Atoi(match[1])
+	minor, _ = strconv.Atoi(match[2])
+	return major, minor, true
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVersionSkew(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   string
+		server   string
+		wantNote bool
+	}{
+		{name: "same minor", client: "v1.29.0", server: "v1.29.4", wantNote: false},
+		{name: "one minor newer client", client: "v1.29.0", server: "v1.28.3-gke.1200", wantNote: false},
+		{name: "one minor older client", client: "v1.28.0", server: "v1.29.1", wantNote: false},
+		{name: "client too new", client: "v1.29.0", server: "v1.26.15-eks-1234", wantNote: true},
+		{name: "client too old", client: "v1.25.0", server: "v1.27.3", wantNote: true},
+		{name: "struct format", client: `version.Info{Major:"1", Minor:"27", GitVersion:"v1.27.3"}`, server: `version.Info{Major:"1", Minor:"24", GitVersion:"v1.24.9"}`, wantNote: true},
+		{name: "unknown server", client: "v1.29.0", server: "", wantNote: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := versionSkew(tt.client, tt.server)
+			if (note != "") != tt.wantNote {
+				t.Errorf("versionSkew(%q, %q) = %q, wantNote %v", tt.client, tt.server, note, tt.wantNote)
+			}
+		})
+	}
+}
+
+func TestRunCommandCheckVersionSkew(t *testing.T) {
+	writeKubeconfig(t, "current", "legacy")
+	setGlobal(t, &checkVersionSkew, true)
+	setGlobal(t, &keepContext, true)
+	fakeKubectl(t, func(args []string) (string, error) {
+		if args[len(args)-1] == "version" {
+			server := "v1.29.2"
+			if argValue(args, "--context") == "legacy" {
+				server = "v1.26.9"
+			}
+			return "Client Version: v1.29.0\nKustomize Version: v5.0.4\nServer Version: " + server + "\n", nil
+		}
+		return `{"items": [{"metadata": {"name": "pod1"}}]}`, nil
+	})
+
+	var stderr string
+	output := captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := runCommand("get", []string{"pods", "-o", "json"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+	})
+
+	if !strings.Contains(stderr, "Context legacy: kubectl v1.29 is outside the supported skew of server v1.26") {
+		t.Errorf("stderr = %q, want a skew warning for legacy", stderr)
+	}
+	if strings.Contains(stderr, "Context current") {
+		t.Errorf("stderr = %q, want no warning for current", stderr)
+	}
+	if strings.Count(output, `"multi/version-skew"`) != 1 {
+		t.Errorf("output = %s, want one version skew annotation", output)
+	}
+}
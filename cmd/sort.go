@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// sortedRow is a row of one of several tables, with its --sort-by-column cell
+type sortedRow struct {
+	table int
+	line  int
+	key   string
+}
+
+// sortColumnIndex returns the index of the --sort-by-column column in names,
+// matched case-insensitively, or -1 with a note when there is none
+func sortColumnIndex(names []string) int {
+	index := slices.IndexFunc(names, func(name string) bool { return strings.EqualFold(name, sortColumn) })
+	if index == -1 {
+		notef("--sort-by-column %s is not a column of the table (%s); rows are not sorted", sortColumn, strings.Join(names, ", "))
+	}
+	return index
+}
+
+// sortRows returns the rows of the tables whose header has the columns of
+// header, across all tables and ordered by --sort-by-column. Rows with equal
+// cells keep their order. It returns nil when the table has no such column.
+func sortRows(tables [][]string, header string) []sortedRow {
+	names := columnNames(parseHeader(header))
+	index := sortColumnIndex(names)
+	if index == -1 {
+		return nil
+	}
+	var rows []sortedRow
+	for i, lines := range tables {
+		if len(lines) < 2 {
+			continue
+		}
+		columns := parseHeader(lines[0])
+		if !slices.Equal(columnNames(columns), names) {
+			continue
+		}
+		for j := 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) != "" {
+				rows = append(rows, sortedRow{table: i, line: j, key: splitRow(columns, lines[j])[index]})
+			}
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b sortedRow) int {
+		return compareCells(names[index], a.key, b.key)
+	})
+	return rows
+}
+
+// compareCells orders two cells of the named column. Ages are compared as
+// durations and cells starting with a number or quantity, such as RESTARTS
+// "7 (2m ago)" or CPU "250m", numerically. Cells that don't parse, such as
+// <none>, come after those that do and are compared as text, so a column
+// mixing both still sorts in one consistent order.
+func compareCells(column, a, b string) int {
+	var okA, okB bool
+	var order int
+	if strings.HasSuffix(column, "AGE") || column == "LAST SEEN" || column == "DURATION" {
+		var da, db time.Duration
+		da, okA = parseKubeDuration(a)
+		db, okB = parseKubeDuration(b)
+		order = cmp.Compare(da, db)
+	} else {
+		var qa, qb resource.Quantity
+		qa, okA = cellQuantity(a)
+		qb, okB = cellQuantity(b)
+		if okA && okB {
+			order = qa.Cmp(qb)
+		}
+	}
+	switch {
+	case okA && okB:
+		return order
+	case okA:
+		return -1
+	case okB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// cellQuantity parses the first word of a cell as a Kubernetes quantity,
+// ignoring a trailing % as in the CPU% column of top
+func cellQuantity(cell string) (resource.Quantity, bool) {
+	word, _, _ := strings.Cut(cell, " ")
+	q, err := resource.ParseQuantity(strings.TrimSuffix(word, "%"))
+	return q, err == nil
+}
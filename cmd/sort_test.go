@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCompareCells(t *testing.T) {
+	tests := []struct {
+		column   string
+		a, b     string
+		expected int
+	}{
+		{column: "AGE", a: "5m", b: "1h", expected: -1},
+		{column: "AGE", a: "2d", b: "47h", expected: 1},
+		{column: "RESTARTS", a: "10", b: "9", expected: 1},
+		{column: "RESTARTS", a: "7 (2m ago)", b: "12 (1h ago)", expected: -1},
+		{column: "CPU(cores)", a: "250m", b: "1", expected: -1},
+		{column: "MEMORY%", a: "9%", b: "40%", expected: -1},
+		{column: "NAME", a: "web-10", b: "web-9", expected: -1},
+		{column: "STATUS", a: "Running", b: "Running", expected: 0},
+		{column: "RESTARTS", a: "<none>", b: "10", expected: 1},
+		{column: "AGE", a: "5m", b: "<unknown>", expected: -1},
+		{column: "CPU(cores)", a: "<none>", b: "<unknown>", expected: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column+" "+tt.a+" "+tt.b, func(t *testing.T) {
+			if got := compareCells(tt.column, tt.a, tt.b); got != tt.expected {
+				t.Errorf("compareCells(%q, %q, %q) = %d, want %d", tt.column, tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompareCellsMixedColumn(t *testing.T) {
+	cells := []string{"10", "<none>", "9", "<unknown>", "2", "100"}
+	slices.SortStableFunc(cells, func(a, b string) int { return compareCells("RESTARTS", a, b) })
+	expected := []string{"2", "9", "10", "100", "<none>", "<unknown>"}
+	if !slices.Equal(cells, expected) {
+		t.Errorf("sorted cells = %v, want %v", cells, expected)
+	}
+}
+
+func TestFormatDefaultOutputSortByColumn(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "NAME   RESTARTS   AGE\npod1   3          5m\npod2   12         1h"},
+		{context: "ctx2", output: "NAME   RESTARTS   AGE\npod3   0          2d\npod4   7          30s"},
+	}
+	tests := []struct {
+		column   string
+		expected string
+	}{
+		{
+			column: "restarts",
+			expected: "CONTEXT  NAME   RESTARTS   AGE\n" +
+				"ctx2     pod3   0          2d\n" +
+				"ctx1     pod1   3          5m\n" +
+				"ctx2     pod4   7          30s\n" +
+				"ctx1     pod2   12         1h\n",
+		},
+		{
+			column: "AGE",
+			expected: "CONTEXT  NAME   RESTARTS   AGE\n" +
+				"ctx2     pod4   7          30s\n" +
+				"ctx1     pod1   3          5m\n" +
+				"ctx1     pod2   12         1h\n" +
+				"ctx2     pod3   0          2d\n",
+		},
+		{
+			column: "MISSING",
+			expected: "CONTEXT  NAME   RESTARTS   AGE\n" +
+				"ctx1     pod1   3          5m\n" +
+				"ctx1     pod2   12         1h\n" +
+				"ctx2     pod3   0          2d\n" +
+				"ctx2     pod4   7          30s\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			setGlobal(t, &sortColumn, tt.column)
+			var output string
+			stderr := captureStderr(t, func() {
+				output = captureStdout(t, func() {
+					if err := formatDefaultOutput(results); err != nil {
+						t.Errorf("formatDefaultOutput() error = %v", err)
+					}
+				})
+			})
+			if output != tt.expected {
+				t.Errorf("formatDefaultOutput() output =\n%s\nwant\n%s", output, tt.expected)
+			}
+			if tt.column == "MISSING" && !strings.Contains(stderr, "not a column") {
+				t.Errorf("stderr = %q, want a note about the missing column", stderr)
+			}
+		})
+	}
+
+	setGlobal(t, &sortColumn, "RESTARTS")
+	setGlobal(t, &tableStyle, tableStyleBox)
+	output := captureStdout(t, func() {
+		if err := formatDefaultOutput(results); err != nil {
+			t.Errorf("formatDefaultOutput() error = %v", err)
+		}
+	})
+	if strings.Index(output, "pod3") > strings.Index(output, "pod1") {
+		t.Errorf("box output =\n%s\nwant pod3 before pod1", output)
+	}
+}
+
+func TestFormatDelimitedOutputSortByColumn(t *testing.T) {
+	setGlobal(t, &sortColumn, "RESTARTS")
+	results := []contextResult{
+		{context: "ctx1", output: "NAME   RESTARTS\npod1   3\npod2   12"},
+		{context: "ctx2", output: "NAME   RESTARTS\npod3   0"},
+	}
+	output := captureStdout(t, func() {
+		if err := formatDelimitedOutput(results, ','); err != nil {
+			t.Errorf("formatDelimitedOutput() error = %v", err)
+		}
+	})
+	expected := "CONTEXT,NAME,RESTARTS\nctx2,pod3,0\nctx1,pod1,3\nctx1,pod2,12\n"
+	if output != expected {
+		t.Errorf("formatDelimitedOutput() output =\n%s\nwant\n%s", output, expected)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// stderrIsTerminal reports whether stderr is a terminal. It is a variable so
+// tests can simulate one.
+var stderrIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// spinnerFrames are drawn in turn by --spinner
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// spinnerInterval is how often the spinner line is redrawn
+const spinnerInterval = 100 * time.Millisecond
+
+// spinner draws a single self-erasing status line on stderr with the number
+// of contexts in flight and done and the elapsed time. A nil spinner does
+// nothing, so callers need not check whether it is enabled.
+type spinner struct {
+	total    int
+	started  time.Time
+	inFlight atomic.Int32
+	done     atomic.Int32
+	stopOnce sync.Once
+	stopped  chan struct{}
+	exited   chan struct{}
+}
+
+// startSpinner starts a spinner for a run over total contexts. It returns nil
+// unless --spinner is set and stderr is a terminal, or when structured logs
+// or --on-error prompts are written to stderr and the line would be
+// interleaved with them.
+func startSpinner(total int) *spinner {
+	if !showSpinner || !stderrIsTerminal() || (logFormat == logFormatJSON && logFile == "") || onError == onErrorPrompt {
+		return nil
+	}
+	s := &spinner{
+		total:   total,
+		started: time.Now(),
+		stopped: make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *spinner) run() {
+	defer close(s.exited)
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		fmt.Fprintf(os.Stderr, "\r\033[K%c %d in flight, %d/%d done, %.1fs",
+			spinnerFrames[frame%len(spinnerFrames)], s.inFlight.Load(), s.done.Load(), s.total, time.Since(s.started).Seconds())
+		select {
+		case <-ticker.C:
+		case <-s.stopped:
+			fmt.Fprint(os.Stderr, "\r\033[K")
+			return
+		}
+	}
+}
+
+// contextStarted records that a context started running
+func (s *spinner) contextStarted() {
+	if s != nil {
+		s.inFlight.Add(1)
+	}
+}
+
+// contextFinished records that a context finished running
+func (s *spinner) contextFinished() {
+	if s != nil {
+		s.inFlight.Add(-1)
+		s.done.Add(1)
+	}
+}
+
+// stop erases the spinner line and waits until it is gone
+func (s *spinner) stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() { close(s.stopped) })
+	<-s.exited
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSpinnerDisabledWithoutTerminal(t *testing.T) {
+	setGlobal(t, &showSpinner, true)
+	setGlobal(t, &stderrIsTerminal, func() bool { return false })
+	fakeKubectl(t, func(args []string) (string, error) {
+		return "NAME\npod1\n", nil
+	})
+
+	stderr := captureStderr(t, func() {
+		if s := startSpinner(2); s != nil {
+			t.Errorf("startSpinner() = %v, want nil when stderr is not a terminal", s)
+		}
+		runContexts([]string{"ctx1", "ctx2"}, "get", []string{"pods"})
+	})
+	if stderr != "" {
+		t.Errorf("stderr = %q, want nothing", stderr)
+	}
+}
+
+func TestSpinnerErasesItself(t *testing.T) {
+	setGlobal(t, &showSpinner, true)
+	setGlobal(t, &stderrIsTerminal, func() bool { return true })
+	fakeKubectl(t, func(args []string) (string, error) {
+		return "NAME\npod1\n", nil
+	})
+
+	stderr := captureStderr(t, func() {
+		runContexts([]string{"ctx1", "ctx2"}, "get", []string{"pods"})
+	})
+	if !strings.Contains(stderr, "/2 done") {
+		t.Errorf("stderr = %q, want a progress line", stderr)
+	}
+	if !strings.HasSuffix(stderr, "\r\033[K") {
+		t.Errorf("stderr = %q, want the line erased at the end", stderr)
+	}
+}
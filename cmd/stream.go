@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// tableStream prints the plain table rows of each context as soon as the
+// context completes. The CONTEXT column is sized from every context name up
+// front, and a header is printed before the first rows and again whenever a
+// table has other columns, such as the kinds of get all.
+type tableStream struct {
+	width     int
+	header    string
+	maxWidth  int
+	autoWidth bool
+}
+
+// newTableStream returns a stream for the contexts, which are displayed
+// under their --context-rewrite names
+func newTableStream(contexts []string, rewrite *contextRewrite) (*tableStream, error) {
+	maxWidth, autoWidth, err := parseTableOptions()
+	if err != nil {
+		return nil, err
+	}
+	width := utf8.RuneCountInString(contextHeader)
+	for _, context := range contexts {
+		if n := len(rewrite.apply(context)); n > width {
+			width = n
+		}
+	}
+	return &tableStream{width: width, maxWidth: maxWidth, autoWidth: autoWidth}, nil
+}
+
+// streamable reports whether --stream applies to the output, which is only
+// the plain table of get and similar commands. Output that is sorted,
+// summarized or rendered as a whole is printed when all contexts finish.
+func streamable(format outputFormat, subcommand string) bool {
+	if format != formatDefault || subcommand == "version" || subcommand == "kustomize" || subcommand == "logs" || subcommand == "describe" {
+		return false
+	}
+	return tableStyle == tableStylePlain && headerFrom == "" && !failuresOnly && !prettyErrors &&
+		!aggregateStatus && !resourceSummary && !summarize && !hashResults && !timeline && !rawKubectl && !topTotals && sortColumn == "" && groupBy == groupByContext
+}
+
+// print writes the rows of a completed context
+func (s *tableStream) print(result contextResult) {
+	if result.err != nil {
+		printContextError(result.context, result.err, result.output)
+		return
+	}
+	coloredContext := colorizeContext(result.context)
+	contextPadding := strings.Repeat(" ", s.width-len(result.context))
+	for _, block := range tableBlocks(strings.TrimSpace(result.output)) {
+		lines := formatTableLines(strings.Split(strings.TrimSpace(block), "\n"), s.maxWidth, s.autoWidth)
+		if len(lines) > 1 {
+			if header := strings.Join(strings.Fields(lines[0]), " "); header != s.header {
+				if s.header != "" {
+					fmt.Println()
+				}
+				s.header = header
+				headerPadding := strings.Repeat(" ", s.width-utf8.RuneCountInString(contextHeader))
+				fmt.Printf("%s%s"+contextGap+"%s\n", contextHeader, headerPadding, lines[0])
+			}
+			lines = lines[1:]
+		}
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			fmt.Printf("%s%s"+contextGap+"%s\n", coloredContext, contextPadding, line)
+		}
+	}
+}
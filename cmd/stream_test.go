@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTableStreamPrint(t *testing.T) {
+	stream, err := newTableStream([]string{"ctx1", "long-context"}, nil)
+	if err != nil {
+		t.Fatalf("newTableStream() error = %v", err)
+	}
+	results := []contextResult{
+		{context: "ctx1", output: "NAME   READY\npod1   1/1\n"},
+		{context: "long-context", output: "NAME     READY\npod2     0/1\npod3     1/1\n"},
+		{context: "ctx1", err: errors.New("exit status 1"), output: "error: forbidden"},
+		{context: "ctx1", output: "NAME    TYPE\nsvc/a   ClusterIP\n"},
+	}
+
+	var stderr string
+	output := captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			for _, result := range results {
+				stream.print(result)
+			}
+		})
+	})
+
+	expected := []string{
+		"CONTEXT       NAME   READY",
+		"ctx1          pod1   1/1",
+		"long-context  pod2     0/1",
+		"long-context  pod3     1/1",
+		"",
+		"CONTEXT       NAME    TYPE",
+		"ctx1          svc/a   ClusterIP",
+	}
+	if got := strings.Split(strings.TrimRight(output, "\n"), "\n"); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("print() output =\n%s\nwant\n%s", output, strings.Join(expected, "\n"))
+	}
+	if !strings.Contains(stderr, "forbidden") {
+		t.Errorf("print() stderr = %q, want the context error", stderr)
+	}
+}
+
+func TestRunCommandStream(t *testing.T) {
+	writeKubeconfig(t, "a-slow", "b-fast")
+	setGlobal(t, &streamOutput, true)
+	fastDone := make(chan struct{})
+	fakeKubectl(t, func(args []string) (string, error) {
+		if argValue(args, "--context") == "b-fast" {
+			defer close(fastDone)
+			return "NAME\npod-fast\n", nil
+		}
+		<-fastDone
+		time.Sleep(50 * time.Millisecond)
+		return "NAME\npod-slow\n", nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	expected := "CONTEXT  NAME\nb-fast   pod-fast\na-slow   pod-slow\n"
+	if output != expected {
+		t.Errorf("runCommand() output =\n%s\nwant the fast context first:\n%s", output, expected)
+	}
+}
+
+func TestRunCommandStreamNotStreamable(t *testing.T) {
+	writeKubeconfig(t, "ctx1")
+	setGlobal(t, &streamOutput, true)
+	fakeKubectl(t, func(args []string) (string, error) {
+		return `{"items": []}`, nil
+	})
+
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() {
+			if err := runCommand("get", []string{"pods", "-o", "json"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+	})
+	if !strings.Contains(stderr, "--stream only applies to plain table output") {
+		t.Errorf("stderr = %q, want a note that output is not streamed", stderr)
+	}
+}
+
+func TestRunCommandStreamRerunFailed(t *testing.T) {
+	setGlobal(t, &streamOutput, true)
+	setGlobal(t, &rerunFailed, 1)
+	if err := runCommand("get", []string{"pods"}); err == nil || !strings.Contains(err.Error(), "--stream") {
+		t.Errorf("runCommand() error = %v, want --rerun-failed rejected with --stream", err)
+	}
+}
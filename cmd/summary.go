@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Pod status buckets used by --aggregate-status
+const (
+	statusRunning = "Running"
+	statusPending = "Pending"
+	statusFailed  = "Failed"
+	statusOther   = "Other"
+)
+
+var statusBuckets = []string{statusRunning, statusPending, statusFailed, statusOther}
+
+// statusBucket maps a pod STATUS column value (phase or reason) to a summary bucket
+func statusBucket(status string) string {
+	switch status {
+	case "Running":
+		return statusRunning
+	case "Pending", "ContainerCreating", "PodInitializing":
+		return statusPending
+	case "Failed", "Error", "CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "OOMKilled",
+		"CreateContainerConfigError", "CreateContainerError", "InvalidImageName", "Evicted":
+		return statusFailed
+	}
+
+	if reason, ok := strings.CutPrefix(status, "Init:"); ok {
+		if statusBucket(reason) == statusFailed {
+			return statusFailed
+		}
+		return statusPending
+	}
+	return statusOther
+}
+
+// formatStatusSummary prints a CONTEXT x status bucket matrix of pod counts with a totals row
+func formatStatusSummary(results []contextResult) error {
+	rows := [][]string{append([]string{contextHeader}, statusBuckets...)}
+	totals := make(map[string]int)
+
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+
+		counts, err := countPodStatuses(result.output)
+		if err != nil {
+			return fmt.Errorf("context %s: %w", result.context, err)
+		}
+
+		row := []string{result.context}
+		for _, bucket := range statusBuckets {
+			row = append(row, strconv.Itoa(counts[bucket]))
+			totals[bucket] += counts[bucket]
+		}
+		rows = append(rows, row)
+	}
+
+	totalRow := []string{"TOTAL"}
+	for _, bucket := range statusBuckets {
+		totalRow = append(totalRow, strconv.Itoa(totals[bucket]))
+	}
+	rows = append(rows, totalRow)
+
+	printTable(rows)
+	return nil
+}
+
+// countPodStatuses counts the pods of a kubectl get pods table by status bucket
+func countPodStatuses(output string) (map[string]int, error) {
+	counts := make(map[string]int)
+	output = strings.TrimSpace(output)
+	if output == "" || strings.HasPrefix(output, "No resources found") {
+		return counts, nil
+	}
+
+	lines := strings.Split(output, "\n")
+	columns := parseHeader(lines[0])
+	statusIdx := columnIndex(columns, "STATUS")
+	if statusIdx == -1 {
+		return nil, fmt.Errorf("--aggregate-status requires output with a STATUS column (try get pods)")
+	}
+
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		counts[statusBucket(splitRow(columns, line)[statusIdx])]++
+	}
+	return counts, nil
+}
+
+// formatResourceSummary prints a kind x context matrix of object counts from
+// -o json output, with a TOTAL column and row
+func formatResourceSummary(results []contextResult) error {
+	header := []string{"KIND"}
+	var perContext []map[string]int
+	totals := make(map[string]int)
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		counts, err := countKinds(result.output)
+		if err != nil {
+			return fmt.Errorf("context %s: %w", result.context, err)
+		}
+		header = append(header, result.context)
+		perContext = append(perContext, counts)
+		for kind, n := range counts {
+			totals[kind] += n
+		}
+	}
+
+	kinds := make([]string, 0, len(totals))
+	for kind := range totals {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	rows := [][]string{append(header, "TOTAL")}
+	for _, kind := range append(kinds, "TOTAL") {
+		row := []string{kind}
+		sum := 0
+		for _, counts := range perContext {
+			n := counts[kind]
+			if kind == "TOTAL" {
+				n = 0
+				for _, count := range counts {
+					n += count
+				}
+			}
+			row = append(row, strconv.Itoa(n))
+			sum += n
+		}
+		rows = append(rows, append(row, strconv.Itoa(sum)))
+	}
+
+	printTable(rows)
+	return nil
+}
+
+// formatCountSummary prints one line per context with the number of objects
+// of each kind, such as "prod-us: 342 pods", followed by the totals
+func formatCountSummary(results []contextResult) error {
+	totals := make(map[string]int)
+	succeeded := 0
+	for _, result := range results {
+		if result.err != nil {
+			printContextError(result.context, result.err, result.output)
+			continue
+		}
+		counts, err := countKinds(result.output)
+		if err != nil {
+			return fmt.Errorf("context %s: %w", result.context, err)
+		}
+		succeeded++
+		for kind, n := range counts {
+			totals[kind] += n
+		}
+		fmt.Printf("%s: %s\n", colorizeContext(result.context), describeCounts(counts))
+	}
+
+	contexts := fmt.Sprintf("%d contexts", succeeded)
+	if succeeded < len(results) {
+		contexts = fmt.Sprintf("%d of %d contexts", succeeded, len(results))
+	}
+	fmt.Printf("total: %s in %s\n", describeCounts(totals), contexts)
+	return nil
+}
+
+// describeCounts returns object counts by kind as "12 pods, 3 services",
+// naming each kind by its resource, or "0" when there are none
+func describeCounts(counts map[string]int) string {
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	if len(kinds) == 0 {
+		return "0"
+	}
+	parts := make([]string, len(kinds))
+	for i, kind := range kinds {
+		plural, singular := meta.UnsafeGuessKindToResource(schema.GroupVersionKind{Kind: kind})
+		resource := plural.Resource
+		if counts[kind] == 1 {
+			resource = singular.Resource
+		}
+		parts[i] = fmt.Sprintf("%d %s", counts[kind], resource)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// countKinds counts the objects of a kubectl -o json list, or single
+// object, by kind. get all returns a List mixing kinds.
+func countKinds(output string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if strings.TrimSpace(output) == "" {
+		return counts, nil
+	}
+	var data struct {
+		Kind  string `json:"kind"`
+		Items []struct {
+			Kind string `json:"kind"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if data.Items == nil && data.Kind != "List" && !strings.HasSuffix(data.Kind, "List") {
+		counts[data.Kind]++
+		return counts, nil
+	}
+	for _, item := range data.Items {
+		counts[item.Kind]++
+	}
+	return counts, nil
+}
+
+// formatFailuresOnly prints a CONTEXT/ERROR table of the failed contexts on
+// stdout, and returns an error if any context failed
+func formatFailuresOnly(results []contextResult) error {
+	rows := [][]string{{contextHeader, "ERROR"}}
+	for _, result := range results {
+		if result.err != nil {
+			rows = append(rows, []string{result.context, errorSummary(result)})
+		}
+	}
+
+	failed := len(rows) - 1
+	if failed == 0 {
+		return nil
+	}
+	printTable(rows)
+	return fmt.Errorf("%d of %d contexts failed", failed, len(results))
+}
+
+// errorSummary returns the first line of a failed context's output, or the
+// command error when there was no output
+func errorSummary(result contextResult) string {
+	for _, line := range strings.Split(result.output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return result.err.Error()
+}
+
+// formatVersionDigest prints a single line grouping the contexts by server
+// version, most common first, e.g. "4 clusters: v1.28.3 (3), unreachable (1)"
+func formatVersionDigest(results []contextResult) error {
+	counts := make(map[string]int)
+	unreachable := 0
+	for _, result := range results {
+		if result.err != nil {
+			unreachable++
+			continue
+		}
+		version := parseServerVersion(result.output)
+		if version == "" {
+			version = "unknown"
+		}
+		counts[version]++
+	}
+
+	versions := make([]string, 0, len(counts))
+	for version := range counts {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if counts[versions[i]] != counts[versions[j]] {
+			return counts[versions[i]] > counts[versions[j]]
+		}
+		return newerVersion(versions[i], versions[j])
+	})
+
+	groups := make([]string, 0, len(versions)+1)
+	for _, version := range versions {
+		groups = append(groups, fmt.Sprintf("%s (%d)", version, counts[version]))
+	}
+	if unreachable > 0 {
+		groups = append(groups, fmt.Sprintf("unreachable (%d)", unreachable))
+	}
+	fmt.Printf("%d clusters: %s\n", len(results), strings.Join(groups, ", "))
+	return nil
+}
+
+// patchPattern matches the patch number of a version such as v1.28.3-eks-1
+var patchPattern = regexp.MustCompile(`v\d+\.\d+\.(\d+)`)
+
+// newerVersion reports whether version a sorts before b, comparing the
+// major, minor and patch numbers as integers so that v1.28 is newer than
+// v1.9. Versions that don't parse sort last.
+func newerVersion(a, b string) bool {
+	aMajor, aMinor, aOK := majorMinor(a)
+	bMajor, bMinor, bOK := majorMinor(b)
+	if aOK != bOK {
+		return aOK
+	}
+	if aOK {
+		if aMajor != bMajor {
+			return aMajor > bMajor
+		}
+		if aMinor != bMinor {
+			return aMinor > bMinor
+		}
+		if aPatch, bPatch := patchNumber(a), patchNumber(b); aPatch != bPatch {
+			return aPatch > bPatch
+		}
+	}
+	return a > b
+}
+
+func patchNumber(version string) int {
+	match := patchPattern.FindStringSubmatch(version)
+	if match == nil {
+		return -1
+	}
+	patch, _ := strconv.Atoi(match[1])
+	return patch
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStatusBucket(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected string
+	}{
+		{status: "Running", expected: statusRunning},
+		{status: "Pending", expected: statusPending},
+		{status: "ContainerCreating", expected: statusPending},
+		{status: "Init:0/2", expected: statusPending},
+		{status: "CrashLoopBackOff", expected: statusFailed},
+		{status: "ImagePullBackOff", expected: statusFailed},
+		{status: "Error", expected: statusFailed},
+		{status: "Init:CrashLoopBackOff", expected: statusFailed},
+		{status: "Completed", expected: statusOther},
+		{status: "Terminating", expected: statusOther},
+		{status: "SomethingNew", expected: statusOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := statusBucket(tt.status); got != tt.expected {
+				t.Errorf("statusBucket(%q) = %q, want %q", tt.status, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatStatusSummary(t *testing.T) {
+	results := []contextResult{
+		{
+			context: "ctx1",
+			output: "NAME    READY   STATUS             RESTARTS      AGE\n" +
+				"pod1    1/1     Running            0             5m\n" +
+				"pod2    0/1     CrashLoopBackOff   7 (2m ago)    1h\n" +
+				"pod3    0/1     Pending            0             1m\n" +
+				"pod4    0/1     Completed          0             2d\n",
+		},
+		{
+			context: "production",
+			output: "NAMESPACE   NAME    READY   STATUS              RESTARTS   AGE\n" +
+				"default     pod1    1/1     Running             0          5m\n" +
+				"default     pod2    1/1     Running             0          5m\n" +
+				"kube-sys    pod3    0/1     ContainerCreating   0          10s\n" +
+				"kube-sys    pod4    0/1     Error               0          3h\n",
+		},
+	}
+
+	output := captureStdout(t, func() {
+		if err := formatStatusSummary(results); err != nil {
+			t.Errorf("formatStatusSummary() error = %v", err)
+		}
+	})
+
+	expected := "CONTEXT     Running  Pending  Failed  Other\n" +
+		"ctx1        1        1        1       1\n" +
+		"production  2        1        1       0\n" +
+		"TOTAL       3        2        2       1\n"
+	if output != expected {
+		t.Errorf("formatStatusSummary() output = %q, want %q", output, expected)
+	}
+}
+
+func TestCountPodStatusesMultiWordCells(t *testing.T) {
+	output := "NAME    RESTARTS     NOMINATED NODE   STATUS\n" +
+		"pod1    3 (5m ago)   <none>           Running\n" +
+		"pod2    0            node-1           Pending\n"
+
+	counts, err := countPodStatuses(output)
+	if err != nil {
+		t.Fatalf("countPodStatuses() error = %v", err)
+	}
+	if counts[statusRunning] != 1 || counts[statusPending] != 1 || counts[statusOther] != 0 {
+		t.Errorf("countPodStatuses() = %v, want 1 Running and 1 Pending", counts)
+	}
+}
+
+func TestFormatStatusSummaryWithoutStatusColumn(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "NAME         TYPE        CLUSTER-IP\nkubernetes   ClusterIP   10.0.0.1\n"},
+	}
+
+	captureStdout(t, func() {
+		if err := formatStatusSummary(results); err == nil {
+			t.Errorf("formatStatusSummary() expected error for output without STATUS column")
+		}
+	})
+}
+
+func TestFormatFailuresOnly(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: "NAME\npod1"},
+		{context: "ctx2", output: "Unable to connect to the server: dial tcp 10.0.0.1:443: i/o timeout\n", err: fmt.Errorf("exit status 1")},
+		{context: "ctx3", output: "NAME\npod3"},
+		{context: "production", err: fmt.Errorf("signal: killed")},
+	}
+
+	var err error
+	output := captureStdout(t, func() {
+		err = formatFailuresOnly(results)
+	})
+
+	expected := "CONTEXT     ERROR\n" +
+		"ctx2        Unable to connect to the server: dial tcp 10.0.0.1:443: i/o timeout\n" +
+		"production  signal: killed\n"
+	if output != expected {
+		t.Errorf("formatFailuresOnly() output = %q, want %q", output, expected)
+	}
+	if err == nil || err.Error() != "2 of 4 contexts failed" {
+		t.Errorf("formatFailuresOnly() error = %v, want \"2 of 4 contexts failed\"", err)
+	}
+}
+
+func TestFormatFailuresOnlyAllSucceeded(t *testing.T) {
+	results := []contextResult{{context: "ctx1", output: "NAME\npod1"}}
+
+	output := captureStdout(t, func() {
+		if err := formatFailuresOnly(results); err != nil {
+			t.Errorf("formatFailuresOnly() error = %v, want nil", err)
+		}
+	})
+	if output != "" {
+		t.Errorf("formatFailuresOnly() output = %q, want empty", output)
+	}
+}
+
+func TestFormatVersionDigest(t *testing.T) {
+	var results []contextResult
+	add := func(n int, output string, err error) {
+		for i := 0; i < n; i++ {
+			results = append(results, contextResult{context: fmt.Sprintf("ctx%d", len(results)), output: output, err: err})
+		}
+	}
+	add(10, "Client Version: v1.29.0\nServer Version: v1.28.3\n", nil)
+	add(3, "Client Version: v1.29.0\nServer Version: v1.27.9\n", nil)
+	add(1, "Unable to connect to the server: i/o timeout\n", fmt.Errorf("exit status 1"))
+
+	output := captureStdout(t, func() {
+		if err := formatVersionDigest(results); err != nil {
+			t.Errorf("formatVersionDigest() error = %v", err)
+		}
+	})
+
+	expected := "14 clusters: v1.28.3 (10), v1.27.9 (3), unreachable (1)\n"
+	if output != expected {
+		t.Errorf("formatVersionDigest() = %q, want %q", output, expected)
+	}
+}
+
+func TestFormatVersionDigestTies(t *testing.T) {
+	var results []contextResult
+	for _, version := range []string{"v1.9.11", "v1.28.3", "v1.28.10", "unparsed", "v1.9.11", "v1.28.3", "v1.28.10", "unparsed"} {
+		results = append(results, contextResult{context: fmt.Sprintf("ctx%d", len(results)), output: "Server Version: " + version + "\n"})
+	}
+
+	output := captureStdout(t, func() {
+		if err := formatVersionDigest(results); err != nil {
+			t.Errorf("formatVersionDigest() error = %v", err)
+		}
+	})
+
+	expected := "8 clusters: v1.28.10 (2), v1.28.3 (2), v1.9.11 (2), unparsed (2)\n"
+	if output != expected {
+		t.Errorf("formatVersionDigest() = %q, want %q", output, expected)
+	}
+}
+
+func TestFormatResourceSummary(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: `{"kind": "List", "items": [{"kind": "Pod"}, {"kind": "Pod"}, {"kind": "Service"}, {"kind": "Deployment"}]}`},
+		{context: "ctx2", output: `{"kind": "List", "items": [{"kind": "Pod"}, {"kind": "ReplicaSet"}]}`},
+		{context: "ctx3", output: "connection refused", err: fmt.Errorf("exit status 1")},
+	}
+
+	var output string
+	captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := formatResourceSummary(results); err != nil {
+				t.Errorf("formatResourceSummary() error = %v", err)
+			}
+		})
+	})
+
+	expected := strings.Join([]string{
+		"KIND        ctx1  ctx2  TOTAL",
+		"Deployment  1     0     1",
+		"Pod         2     1     3",
+		"ReplicaSet  0     1     1",
+		"Service     1     0     1",
+		"TOTAL       4     2     6",
+	}, "\n") + "\n"
+	if output != expected {
+		t.Errorf("formatResourceSummary() =\n%s\nwant\n%s", output, expected)
+	}
+}
+
+func TestFormatCountSummary(t *testing.T) {
+	results := []contextResult{
+		{context: "ctx1", output: `{"kind": "List", "items": [{"kind": "Pod"}, {"kind": "Pod"}, {"kind": "Ingress"}]}`},
+		{context: "ctx2", output: `{"kind": "List", "items": [{"kind": "Pod"}]}`},
+		{context: "ctx3", output: `{"kind": "List", "items": []}`},
+		{context: "ctx4", output: "connection refused", err: fmt.Errorf("exit status 1")},
+	}
+
+	var output string
+	stderr := captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := formatCountSummary(results); err != nil {
+				t.Errorf("formatCountSummary() error = %v", err)
+			}
+		})
+	})
+
+	expected := strings.Join([]string{
+		"ctx1: 1 ingress, 2 pods",
+		"ctx2: 1 pod",
+		"ctx3: 0",
+		"total: 1 ingress, 3 pods in 3 of 4 contexts",
+	}, "\n") + "\n"
+	if output != expected {
+		t.Errorf("formatCountSummary() =\n%s\nwant\n%s", output, expected)
+	}
+	if !strings.Contains(stderr, "Context ctx4") {
+		t.Errorf("stderr = %q, want the ctx4 error", stderr)
+	}
+}
+
+func TestRunCommandSummarize(t *testing.T) {
+	writeKubeconfig(t, "ctx1", "ctx2")
+	setGlobal(t, &summarize, true)
+	fakeKubectl(t, func(args []string) (string, error) {
+		if argValue(args, "-o") != "json" {
+			t.Errorf("kubectl ran %v, want -o json", args)
+		}
+		return `{"kind": "List", "items": [{"kind": "Pod"}]}`, nil
+	})
+
+	output := captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods", "--field-selector=status.phase=Failed", "-A"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+	expected := "ctx1: 1 pod\nctx2: 1 pod\ntotal: 2 pods in 2 contexts\n"
+	if output != expected {
+		t.Errorf("output =\n%s\nwant\n%s", output, expected)
+	}
+
+	if err := runCommand("describe", []string{"pods"}); err == nil {
+		t.Error("runCommand(describe) with --summarize succeeded, want an error")
+	}
+}
+
+func TestCountKinds(t *testing.T) {
+	counts, err := countKinds(`{"kind": "Namespace", "metadata": {"name": "default"}}`)
+	if err != nil || counts["Namespace"] != 1 || len(counts) != 1 {
+		t.Errorf("countKinds(single object) = %v, %v, want one Namespace", counts, err)
+	}
+	counts, err = countKinds(`{"kind": "PodList", "items": []}`)
+	if err != nil || len(counts) != 0 {
+		t.Errorf("countKinds(empty list) = %v, %v, want no kinds", counts, err)
+	}
+	if _, err := countKinds("NAME\npod1"); err == nil {
+		t.Errorf("countKinds(table) expected error")
+	}
+}
@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// now is the clock used for age conversions. It is a variable so tests can pin it.
+var now = time.Now
+
+// tableColumn is a column of a kubectl table, located by its offset (in runes) in the header line
+type tableColumn struct {
+	name  string
+	start int
+}
+
+// parseHeader splits a kubectl table header into columns. kubectl separates
+// columns by at least two spaces, so multi-word headers like "NOMINATED NODE"
+// stay intact.
+func parseHeader(header string) []tableColumn {
+	var columns []tableColumn
+	runes := []rune(header)
+	for i := 0; i < len(runes); {
+		if runes[i] == ' ' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && !(runes[i] == ' ' && (i+1 >= len(runes) || runes[i+1] == ' ')) {
+			i++
+		}
+		columns = append(columns, tableColumn{
+			name:  string(runes[start:i]),
+			start: start,
+		})
+	}
+	return columns
+}
+
+// splitRow splits a table row into cells using the header column offsets
+func splitRow(columns []tableColumn, line string) []string {
+	runes := []rune(line)
+	cells := make([]string, len(columns))
+	for i, column := range columns {
+		if column.start >= len(runes) {
+			continue
+		}
+		end := len(runes)
+		if i+1 < len(columns) && columns[i+1].start < end {
+			end = columns[i+1].start
+		}
+		cells[i] = strings.TrimSpace(string(runes[column.start:end]))
+	}
+	return cells
+}
+
+// columnNames returns the names of the columns
+func columnNames(columns []tableColumn) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// columnIndex returns the index of the named column, or -1
+func columnIndex(columns []tableColumn, name string) int {
+	for i, column := range columns {
+		if column.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderTableRows renders rows as kubectl-style columns separated by three spaces
+func renderTableRows(rows [][]string) []string {
+	widths := columnWidths(rows)
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+3))
+			}
+		}
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+	}
+	return lines
+}
+
+// alignTables re-renders the tables whose header has the same columns as
+// header as one table, so every column lines up across them, and returns the
+// realigned header. Other tables, and lines that are not tables, are left alone.
+func alignTables(tables [][]string, header string) string {
+	names := columnNames(parseHeader(header))
+	rows := make([][][]string, len(tables))
+	for i, lines := range tables {
+		if len(lines) < 2 {
+			continue
+		}
+		columns := parseHeader(lines[0])
+		if !slices.Equal(columnNames(columns), names) {
+			continue
+		}
+		for _, line := range lines[1:] {
+			if strings.TrimSpace(line) != "" {
+				rows[i] = append(rows[i], splitRow(columns, line))
+			}
+		}
+	}
+
+	headerLine, aligned := renderAligned(names, rows)
+	for i, lines := range aligned {
+		if lines != nil {
+			tables[i] = lines
+		}
+	}
+	return headerLine
+}
+
+// renderAligned renders the rows of several tables under one header as a
+// single table and returns the header line and each table's lines, starting
+// with the header. Tables without rows are returned as nil.
+func renderAligned(header []string, tables [][][]string) (string, [][]string) {
+	rows := [][]string{header}
+	for _, table := range tables {
+		rows = append(rows, table...)
+	}
+	rendered := renderTableRows(rows)
+
+	aligned := make([][]string, len(tables))
+	next := 1
+	for i, table := range tables {
+		if len(table) == 0 {
+			continue
+		}
+		aligned[i] = append([]string{rendered[0]}, rendered[next:next+len(table)]...)
+		next += len(table)
+	}
+	return rendered[0], aligned
+}
+
+// renderBoxTable renders rows inside Unicode box-drawing borders. When
+// hasHeader is set the first row is separated from the rest by a rule.
+func renderBoxTable(rows [][]string, hasHeader bool) []string {
+	widths := columnWidths(rows)
+	rule := func(left, middle, right string) string {
+		parts := make([]string, len(widths))
+		for i, width := range widths {
+			parts[i] = strings.Repeat("─", width+2)
+		}
+		return left + strings.Join(parts, middle) + right
+	}
+
+	lines := []string{rule("┌", "┬", "┐")}
+	for i, row := range rows {
+		var line strings.Builder
+		line.WriteString("│")
+		for j, width := range widths {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			line.WriteString(" " + cell + strings.Repeat(" ", width-utf8.RuneCountInString(cell)) + " │")
+		}
+		lines = append(lines, line.String())
+		if i == 0 && hasHeader && len(rows) > 1 {
+			lines = append(lines, rule("├", "┼", "┤"))
+		}
+	}
+	return append(lines, rule("└", "┴", "┘"))
+}
+
+// transformTable parses a kubectl table (header plus rows), lets fn rewrite
+// the cells of every row and renders the table again with realigned columns
+func transformTable(lines []string, fn func(columns []tableColumn, cells []string)) []string {
+	if len(lines) < 2 {
+		return lines
+	}
+
+	columns := parseHeader(lines[0])
+	rows := [][]string{columnNames(columns)}
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		cells := splitRow(columns, line)
+		fn(columns, cells)
+		rows = append(rows, cells)
+	}
+	return renderTableRows(rows)
+}
+
+// truncateColumns ellipsizes every cell, header included, that is longer
+// than width runes and realigns the table
+func truncateColumns(lines []string, width int) []string {
+	if len(lines) < 2 {
+		return lines
+	}
+
+	columns := parseHeader(lines[0])
+	rows := [][]string{columnNames(columns)}
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		rows = append(rows, splitRow(columns, line))
+	}
+	for _, row := range rows {
+		for i := range row {
+			row[i] = truncateCell(row[i], width)
+		}
+	}
+	return renderTableRows(rows)
+}
+
+// truncateCell shortens s to width runes, ending it with an ellipsis
+func truncateCell(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// parseKubeDuration parses the compact durations kubectl prints in AGE
+// columns, such as "45s", "5m", "3d4h" or "2y30d"
+func parseKubeDuration(s string) (time.Duration, bool) {
+	if s == "" {
+		return 0, false
+	}
+
+	var total time.Duration
+	for s != "" {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			return 0, false
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, false
+		}
+
+		var unit time.Duration
+		switch s[i] {
+		case 's':
+			unit = time.Second
+		case 'm':
+			unit = time.Minute
+		case 'h':
+			unit = time.Hour
+		case 'd':
+			unit = 24 * time.Hour
+		case 'y':
+			unit = 365 * 24 * time.Hour
+		default:
+			return 0, false
+		}
+		total += time.Duration(n) * unit
+		s = s[i+1:]
+	}
+	return total, true
+}
+
+// absoluteAge converts a kubectl age into the UTC timestamp it refers to.
+// Values that are not ages are returned unchanged.
+func absoluteAge(age string) string {
+	d, ok := parseKubeDuration(age)
+	if !ok {
+		return age
+	}
+	return now().Add(-d).UTC().Format(time.RFC3339)
+}
+
+// Duration formats for --duration-format
+const (
+	durationKube  = "kube"
+	durationHours = "hours"
+	durationHuman = "human"
+)
+
+// formatAge re-renders a kubectl age in the --duration-format style.
+// Values that are not ages are returned unchanged.
+func formatAge(age string) string {
+	d, ok := parseKubeDuration(age)
+	if !ok {
+		return age
+	}
+	return formatDuration(d, durationFormat)
+}
+
+// formatDuration renders d as kubectl's compact form (kube), total hours
+// (hours) or the two largest units spelled out (human)
+func formatDuration(d time.Duration, format string) string {
+	switch format {
+	case durationHours:
+		return strconv.FormatFloat(math.Round(d.Hours()*10)/10, 'f', -1, 64) + "h"
+	case durationHuman:
+		units := []struct {
+			name string
+			size time.Duration
+		}{
+			{"year", 365 * 24 * time.Hour},
+			{"day", 24 * time.Hour},
+			{"hour", time.Hour},
+			{"minute", time.Minute},
+			{"second", time.Second},
+		}
+		var parts []string
+		for _, unit := range units {
+			if n := int(d / unit.size); n > 0 && len(parts) < 2 {
+				part := fmt.Sprintf("%d %s", n, unit.name)
+				if n > 1 {
+					part += "s"
+				}
+				parts = append(parts, part)
+				d -= time.Duration(n) * unit.size
+			} else if len(parts) > 0 {
+				break
+			}
+		}
+		if len(parts) == 0 {
+			return "0 seconds"
+		}
+		return strings.Join(parts, " ")
+	default:
+		return compactDuration(d)
+	}
+}
+
+// compactDuration renders d the way kubectl prints ages
+func compactDuration(d time.Duration) string {
+	const day = 24 * time.Hour
+	const year = 365 * day
+	switch {
+	case d < 2*time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < 10*time.Minute:
+		if s := int(d.Seconds()) % 60; s != 0 {
+			return fmt.Sprintf("%dm%ds", int(d.Minutes()), s)
+		}
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 3*time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 8*time.Hour:
+		if m := int(d.Minutes()) % 60; m != 0 {
+			return fmt.Sprintf("%dh%dm", int(d.Hours()), m)
+		}
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 8*day:
+		if h := int(d.Hours()) % 24; h != 0 {
+			return fmt.Sprintf("%dd%dh", int(d/day), h)
+		}
+		return fmt.Sprintf("%dd", int(d/day))
+	case d < 2*year:
+		return fmt.Sprintf("%dd", int(d/day))
+	case d < 8*year:
+		if days := int(d/day) % 365; days != 0 {
+			return fmt.Sprintf("%dy%dd", int(d/year), days)
+		}
+		return fmt.Sprintf("%dy", int(d/year))
+	default:
+		return fmt.Sprintf("%dy", int(d/year))
+	}
+}
+
+// rewriteAgeColumn applies fn to every cell of the AGE column of a kubectl table
+func rewriteAgeColumn(lines []string, fn func(string) string) []string {
+	if len(lines) < 2 || columnIndex(parseHeader(lines[0]), "AGE") == -1 {
+		return lines
+	}
+	return transformTable(lines, func(columns []tableColumn, cells []string) {
+		idx := columnIndex(columns, "AGE")
+		cells[idx] = fn(cells[idx])
+	})
+}
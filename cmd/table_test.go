@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseHeader(t *testing.T) {
+	columns := parseHeader("NAME    READY   STATUS    NOMINATED NODE   AGE")
+	want := []tableColumn{
+		{name: "NAME", start: 0},
+		{name: "READY", start: 8},
+		{name: "STATUS", start: 16},
+		{name: "NOMINATED NODE", start: 26},
+		{name: "AGE", start: 43},
+	}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("parseHeader() = %v, want %v", columns, want)
+	}
+}
+
+func TestSplitRow(t *testing.T) {
+	columns := parseHeader("NAME    STATUS             RESTARTS      AGE")
+	got := splitRow(columns, "pod2    CrashLoopBackOff   7 (2m ago)    1h")
+	want := []string{"pod2", "CrashLoopBackOff", "7 (2m ago)", "1h"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitRow() = %q, want %q", got, want)
+	}
+
+	got = splitRow(columns, "pod3    Running")
+	want = []string{"pod3", "Running", "", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitRow() short row = %q, want %q", got, want)
+	}
+}
+
+func TestAlignTables(t *testing.T) {
+	tables := [][]string{
+		{"NAME   STATUS", "pod1   Running"},
+		{"NAME                 STATUS", "web-7d9f8b6c5d-abc   CrashLoopBackOff"},
+		{"NAME   TYPE", "svc1   ClusterIP"},
+		nil,
+	}
+	header := alignTables(tables, "NAME   STATUS")
+	if header != "NAME                 STATUS" {
+		t.Errorf("alignTables() header = %q", header)
+	}
+	want := [][]string{
+		{"NAME                 STATUS", "pod1                 Running"},
+		{"NAME                 STATUS", "web-7d9f8b6c5d-abc   CrashLoopBackOff"},
+		{"NAME   TYPE", "svc1   ClusterIP"},
+		nil,
+	}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("alignTables() tables = %q, want %q", tables, want)
+	}
+}
+
+func TestParseKubeDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		ok       bool
+	}{
+		{input: "45s", expected: 45 * time.Second, ok: true},
+		{input: "5m", expected: 5 * time.Minute, ok: true},
+		{input: "10m30s", expected: 10*time.Minute + 30*time.Second, ok: true},
+		{input: "3h", expected: 3 * time.Hour, ok: true},
+		{input: "3d4h", expected: 76 * time.Hour, ok: true},
+		{input: "2y30d", expected: (2*365 + 30) * 24 * time.Hour, ok: true},
+		{input: "<unknown>", ok: false},
+		{input: "<invalid>", ok: false},
+		{input: "Running", ok: false},
+		{input: "5", ok: false},
+		{input: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseKubeDuration(tt.input)
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("parseKubeDuration(%q) = %v, %v, want %v, %v", tt.input, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAbsoluteAge(t *testing.T) {
+	setGlobal(t, &now, func() time.Time {
+		return time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	})
+
+	tests := []struct {
+		age      string
+		expected string
+	}{
+		{age: "30s", expected: "2024-03-10T11:59:30Z"},
+		{age: "5m", expected: "2024-03-10T11:55:00Z"},
+		{age: "2h", expected: "2024-03-10T10:00:00Z"},
+		{age: "3d4h", expected: "2024-03-07T08:00:00Z"},
+		{age: "1y", expected: "2023-03-11T12:00:00Z"},
+		{age: "<unknown>", expected: "<unknown>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.age, func(t *testing.T) {
+			if got := absoluteAge(tt.age); got != tt.expected {
+				t.Errorf("absoluteAge(%q) = %q, want %q", tt.age, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRewriteAgeColumn(t *testing.T) {
+	lines := []string{
+		"NAME   STATUS    AGE   NODE",
+		"pod1   Running   5m    node-a",
+	}
+	got := rewriteAgeColumn(lines, func(age string) string { return "<" + age + ">" })
+	want := []string{
+		"NAME   STATUS    AGE    NODE",
+		"pod1   Running   <5m>   node-a",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rewriteAgeColumn() = %q, want %q", got, want)
+	}
+
+	noAge := []string{"NAME   STATUS", "pod1   Running"}
+	if got := rewriteAgeColumn(noAge, absoluteAge); !reflect.DeepEqual(got, noAge) {
+		t.Errorf("rewriteAgeColumn() without AGE column = %q, want unchanged", got)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		kube     string
+		hours    string
+		human    string
+	}{
+		{duration: 45 * time.Second, kube: "45s", hours: "0h", human: "45 seconds"},
+		{duration: 5*time.Minute + 30*time.Second, kube: "5m30s", hours: "0.1h", human: "5 minutes 30 seconds"},
+		{duration: 90 * time.Minute, kube: "90m", hours: "1.5h", human: "1 hour 30 minutes"},
+		{duration: 76 * time.Hour, kube: "3d4h", hours: "76h", human: "3 days 4 hours"},
+		{duration: 30 * 24 * time.Hour, kube: "30d", hours: "720h", human: "30 days"},
+		{duration: (3*365 + 12) * 24 * time.Hour, kube: "3y12d", hours: "26568h", human: "3 years 12 days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kube, func(t *testing.T) {
+			if got := formatDuration(tt.duration, durationKube); got != tt.kube {
+				t.Errorf("formatDuration(%v, kube) = %q, want %q", tt.duration, got, tt.kube)
+			}
+			if got := formatDuration(tt.duration, durationHours); got != tt.hours {
+				t.Errorf("formatDuration(%v, hours) = %q, want %q", tt.duration, got, tt.hours)
+			}
+			if got := formatDuration(tt.duration, durationHuman); got != tt.human {
+				t.Errorf("formatDuration(%v, human) = %q, want %q", tt.duration, got, tt.human)
+			}
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	setGlobal(t, &durationFormat, durationHours)
+	if got := formatAge("3d4h"); got != "76h" {
+		t.Errorf("formatAge(3d4h) = %q, want 76h", got)
+	}
+	if got := formatAge("<unknown>"); got != "<unknown>" {
+		t.Errorf("formatAge(<unknown>) = %q, want unchanged", got)
+	}
+}
+
+func TestTruncateCell(t *testing.T) {
+	tests := []struct {
+		in       string
+		width    int
+		expected string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"sha256:4f1d2c9e8b7a", 10, "sha256:4f…"},
+		{"ünïcödé-nämé", 5, "ünïc…"},
+	}
+	for _, tt := range tests {
+		if got := truncateCell(tt.in, tt.width); got != tt.expected {
+			t.Errorf("truncateCell(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.expected)
+		}
+	}
+}
+
+func TestTruncateColumns(t *testing.T) {
+	lines := []string{
+		"NAME                          IMAGE",
+		"api-7d9f8b6c5d-x2k4j          registry.example.com/api@sha256:4f1d2c9e",
+		"db-0                          postgres:16",
+	}
+	expected := []string{
+		"NAME           IMAGE",
+		"api-7d9f8b6…   registry.ex…",
+		"db-0           postgres:16",
+	}
+
+	if got := truncateColumns(lines, 12); !reflect.DeepEqual(got, expected) {
+		t.Errorf("truncateColumns() = %q, want %q", got, expected)
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// throttleBackoff is the dispatch delay after the first throttled response.
+// It is a variable so tests can shorten it.
+var throttleBackoff = 250 * time.Millisecond
+
+// maxThrottleBackoff caps the dispatch delay while throttling continues
+const maxThrottleBackoff = 5 * time.Second
+
+// throttleMarkers are the messages kubectl and client-go print when an API
+// server, or client-go's own rate limiter, throttles requests
+var throttleMarkers = []string{
+	"Throttling request",
+	"client-side throttling",
+	"TooManyRequests",
+	"Too Many Requests",
+	"too many requests",
+	"(429)",
+	"status code 429",
+}
+
+// isThrottled reports whether a failed kubectl invocation's stderr shows
+// throttling. Stdout is never checked, since the objects it lists may well
+// contain the markers.
+func isThrottled(stderr string, err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, marker := range throttleMarkers {
+		if strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// concurrencyLimiter adapts the number of contexts running at once to
+// throttling, AIMD-style: every throttled response halves the limit and
+// doubles the delay between dispatches, and a limit's worth of clean
+// responses raises the limit by one and halves the delay again.
+type concurrencyLimiter struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	max       int
+	limit     int
+	active    int
+	successes int
+	delay     time.Duration
+}
+
+func newConcurrencyLimiter(max int) *concurrencyLimiter {
+	l := &concurrencyLimiter{max: max, limit: max}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire blocks until a context may run, then waits out the dispatch delay
+func (l *concurrencyLimiter) acquire() {
+	l.mu.Lock()
+	for l.active >= l.limit {
+		l.cond.Wait()
+	}
+	l.active++
+	delay := l.delay
+	l.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// release frees a slot and adjusts the limit to whether context was throttled
+func (l *concurrencyLimiter) release(context string, throttled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer l.cond.Broadcast()
+	l.active--
+
+	if throttled {
+		l.successes = 0
+		l.delay = min(max(2*l.delay, throttleBackoff), maxThrottleBackoff)
+		if limit := max(l.limit/2, 1); limit != l.limit {
+			l.limit = limit
+			notef("Throttling detected on context %s, reducing concurrency to %d", context, l.limit)
+		}
+		return
+	}
+
+	l.successes++
+	if l.successes < l.limit {
+		return
+	}
+	l.successes = 0
+	l.delay /= 2
+	if l.delay < time.Millisecond {
+		l.delay = 0
+	}
+	if l.limit < l.max {
+		l.limit++
+		if l.limit == l.max {
+			notef("Throttling subsided, concurrency restored to %d", l.limit)
+		}
+	}
+}
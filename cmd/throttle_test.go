@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIsThrottled(t *testing.T) {
+	failed := fmt.Errorf("exit status 1")
+	tests := []struct {
+		name   string
+		stderr string
+		err    error
+		want   bool
+	}{
+		{name: "clean", err: failed, want: false},
+		{name: "client-go throttling", stderr: "I1017 10:00:00 request.go:697] Waited for 1.2s due to client-side throttling, not priority and fairness", err: failed, want: true},
+		{name: "throttling request", stderr: "Throttling request took 1.5s, request: GET:https://10.0.0.1/api", err: failed, want: true},
+		{name: "server 429", stderr: "Error from server (TooManyRequests): the server has received too many requests and has asked us to try again later", err: failed, want: true},
+		{name: "succeeded", stderr: "Throttling request took 1.5s, request: GET:https://10.0.0.1/api", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isThrottled(tt.stderr, tt.err); got != tt.want {
+				t.Errorf("isThrottled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	setGlobal(t, &throttleBackoff, time.Millisecond)
+	l := newConcurrencyLimiter(8)
+
+	stderr := captureStderr(t, func() {
+		for _, want := range []int{4, 2, 1, 1} {
+			l.acquire()
+			l.release("ctx1", true)
+			if l.limit != want {
+				t.Errorf("limit after throttling = %d, want %d", l.limit, want)
+			}
+		}
+	})
+	if strings.Count(stderr, "Throttling detected on context ctx1") != 3 {
+		t.Errorf("stderr = %q, want one note per reduction", stderr)
+	}
+	if l.delay != 8*time.Millisecond {
+		t.Errorf("delay = %v, want 8ms", l.delay)
+	}
+
+	stderr = captureStderr(t, func() {
+		for i := 0; i < 100 && l.limit < 8; i++ {
+			l.acquire()
+			l.release("ctx1", false)
+		}
+	})
+	if l.limit != 8 || l.delay != 0 {
+		t.Errorf("limit = %d, delay = %v after recovery, want 8 and 0", l.limit, l.delay)
+	}
+	if !strings.Contains(stderr, "concurrency restored to 8") {
+		t.Errorf("stderr = %q, want recovery note", stderr)
+	}
+}
+
+func TestRunContextsThrottling(t *testing.T) {
+	setGlobal(t, &throttleBackoff, time.Millisecond)
+	setGlobal(t, &batchSize, 4)
+
+	var mu sync.Mutex
+	active, peakAfterThrottling, calls := 0, 0, 0
+	setGlobal(t, &kubectlExec, func(_ context.Context, args []string) (string, string, error) {
+		mu.Lock()
+		calls++
+		call := calls
+		active++
+		if call > 4 && active > peakAfterThrottling {
+			peakAfterThrottling = active
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		if call <= 4 {
+			return "", "Error from server (TooManyRequests): the server has received too many requests", fmt.Errorf("exit status 1")
+		}
+		// Objects that mention throttling must not count as throttled
+		return "NAME\nthrottling-request-429\n", "", nil
+	})
+
+	var contexts []string
+	for i := 0; i < 12; i++ {
+		contexts = append(contexts, fmt.Sprintf("ctx%02d", i))
+	}
+
+	var results []contextResult
+	stderr := captureStderr(t, func() {
+		results = runContexts(contexts, "get", []string{"pods"})
+	})
+
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	if failed != 4 {
+		t.Errorf("%d contexts failed, want the 4 throttled ones", failed)
+	}
+	if !strings.Contains(stderr, "reducing concurrency to 1") {
+		t.Errorf("stderr = %q, want concurrency reduced to 1", stderr)
+	}
+	if peakAfterThrottling > 3 {
+		t.Errorf("%d contexts ran at once after throttling, want fewer than --batch-size", peakAfterThrottling)
+	}
+}
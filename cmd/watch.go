@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// watchTick waits for the next --watch-interval iteration and reports whether
+// to run it. It is a variable so tests can control the iterations.
+var watchTick = func(interval time.Duration) bool {
+	time.Sleep(interval)
+	return true
+}
+
+// watchCommand reruns the command every --watch-interval until interrupted.
+// With --context-file-watch the contexts are resolved again whenever a
+// kubeconfig file changes. Errors of an iteration are reported on stderr and
+// do not stop watching.
+func watchCommand(contexts []string, subcommand string, extraArgs []string) error {
+	stamp := kubeconfigStamp()
+	for {
+		if err := runIteration(contexts, subcommand, extraArgs); err != nil {
+			notef("Error: %v", err)
+		}
+		if !watchTick(watchInterval) {
+			return nil
+		}
+
+		if !contextFileWatch {
+			continue
+		}
+		current := kubeconfigStamp()
+		if current == stamp {
+			continue
+		}
+		stamp = current
+		updated, err := resolveContexts()
+		if err != nil {
+			notef("Kubeconfig changed but could not be used, keeping the previous contexts: %v", err)
+			continue
+		}
+		if added, removed := diffContexts(contexts, updated); len(added) > 0 || len(removed) > 0 {
+			notef("Contexts changed: added [%s], removed [%s]", strings.Join(added, ", "), strings.Join(removed, ", "))
+		}
+		contexts = updated
+	}
+}
+
+// kubeconfigStamp identifies the current version of the kubeconfig files by
+// their paths, sizes and modification times. Comparing stamps once per
+// iteration is all watchCommand needs: contexts only change between runs,
+// and a file watcher would lose kubeconfigs that kubectl replaces by rename.
+func kubeconfigStamp() string {
+	var stamp strings.Builder
+	for _, path := range filepath.SplitList(getKubeconfigPath()) {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(&stamp, "%s:missing;", path)
+			continue
+		}
+		fmt.Fprintf(&stamp, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return stamp.String()
+}
+
+// diffContexts returns the contexts that are only in updated and only in previous
+func diffContexts(previous, updated []string) (added, removed []string) {
+	inPrevious := make(map[string]bool, len(previous))
+	for _, ctx := range previous {
+		inPrevious[ctx] = true
+	}
+	inUpdated := make(map[string]bool, len(updated))
+	for _, ctx := range updated {
+		inUpdated[ctx] = true
+		if !inPrevious[ctx] {
+			added = append(added, ctx)
+		}
+	}
+	for _, ctx := range previous {
+		if !inUpdated[ctx] {
+			removed = append(removed, ctx)
+		}
+	}
+	return added, removed
+}
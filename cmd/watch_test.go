@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchRefreshesContextsOnKubeconfigChange(t *testing.T) {
+	path := writeKubeconfig(t, "ctx1", "ctx2")
+	setGlobal(t, &watchInterval, time.Second)
+	setGlobal(t, &contextFileWatch, true)
+
+	// Iterations run one after another, so ticks identifies the current one
+	ticks := 0
+	var mu sync.Mutex
+	iterations := make([][]string, 2)
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		iterations[ticks] = append(iterations[ticks], argValue(args, "--context"))
+		return "NAME\npod1\n", nil
+	})
+
+	setGlobal(t, &watchTick, func(interval time.Duration) bool {
+		mu.Lock()
+		ticks++
+		mu.Unlock()
+		if ticks == 1 {
+			// Edit the kubeconfig in place between the iterations
+			updated, err := os.ReadFile(writeKubeconfig(t, "ctx1", "ctx3", "ctx4"))
+			if err != nil {
+				t.Fatalf("failed to read kubeconfig: %v", err)
+			}
+			if err := os.WriteFile(path, updated, 0o600); err != nil {
+				t.Fatalf("failed to write kubeconfig: %v", err)
+			}
+			t.Setenv("KUBECONFIG", path)
+		}
+		return ticks < 2
+	})
+
+	var stderr string
+	captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := runCommand("get", []string{"pods"}); err != nil {
+				t.Errorf("runCommand() error = %v", err)
+			}
+		})
+	})
+
+	expected := [][]string{{"ctx1", "ctx2"}, {"ctx1", "ctx3", "ctx4"}}
+	for i, want := range expected {
+		got := iterations[i]
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("iteration %d ran against %v, want %v", i+1, got, want)
+		}
+	}
+	if !strings.Contains(stderr, "Contexts changed: added [ctx3, ctx4], removed [ctx2]") {
+		t.Errorf("stderr should note the changed contexts, got %q", stderr)
+	}
+}
+
+func TestWatchWithoutContextFileWatchKeepsContexts(t *testing.T) {
+	path := writeKubeconfig(t, "ctx1")
+	setGlobal(t, &watchInterval, time.Second)
+
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	fakeKubectl(t, func(args []string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[argValue(args, "--context")]++
+		return "NAME\npod1\n", nil
+	})
+
+	ticks := 0
+	setGlobal(t, &watchTick, func(interval time.Duration) bool {
+		ticks++
+		updated, _ := os.ReadFile(writeKubeconfig(t, "ctx1", "ctx2"))
+		os.WriteFile(path, updated, 0o600)
+		t.Setenv("KUBECONFIG", path)
+		return ticks < 2
+	})
+
+	captureStdout(t, func() {
+		if err := runCommand("get", []string{"pods"}); err != nil {
+			t.Errorf("runCommand() error = %v", err)
+		}
+	})
+
+	if seen["ctx1"] != 2 || seen["ctx2"] != 0 {
+		t.Errorf("runs per context = %v, want ctx1 twice and ctx2 never", seen)
+	}
+}
+
+func TestDiffContexts(t *testing.T) {
+	added, removed := diffContexts([]string{"a", "b", "c"}, []string{"b", "c", "d"})
+	if !reflect.DeepEqual(added, []string{"d"}) || !reflect.DeepEqual(removed, []string{"a"}) {
+		t.Errorf("diffContexts() = %v, %v, want [d], [a]", added, removed)
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// whereOperators are the comparisons --where supports, two-character ones
+// first so ">=" is not read as ">"
+var whereOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// wherePredicate is a parsed --where expression: a JSONPath into each item,
+// a comparison operator and the value to compare with
+type wherePredicate struct {
+	path    string
+	op      string
+	value   string
+	number  float64
+	numeric bool
+}
+
+// parseWhere parses a --where expression such as
+// "items[?].status.containerStatuses[0].restartCount > 5" or
+// "{.spec.nodeName} == node-a". It returns nil for an empty expression.
+func parseWhere(expr string) (*wherePredicate, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+
+	pos, op := findWhereOperator(expr)
+	if pos == -1 {
+		return nil, fmt.Errorf("invalid --where %q: expected PATH OPERATOR VALUE with one of %s", expr, strings.Join(whereOperators, " "))
+	}
+
+	path := strings.TrimSpace(expr[:pos])
+	for _, prefix := range []string{"items[?]", "items[*]"} {
+		path = strings.TrimPrefix(path, prefix)
+	}
+	if !strings.HasPrefix(path, "{") {
+		if !strings.HasPrefix(path, ".") {
+			path = "." + path
+		}
+		path = "{" + path + "}"
+	}
+	if path == "{.}" {
+		return nil, fmt.Errorf("invalid --where %q: missing path", expr)
+	}
+	if err := jsonpath.New("where").Parse(path); err != nil {
+		return nil, fmt.Errorf("invalid --where path %q: %w", path, err)
+	}
+
+	predicate := &wherePredicate{path: path, op: op}
+	value := strings.TrimSpace(expr[pos+len(op):])
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		predicate.value = unquoted
+	} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		predicate.value = value[1 : len(value)-1]
+	} else {
+		if value == "" {
+			return nil, fmt.Errorf("invalid --where %q: missing value", expr)
+		}
+		predicate.value = value
+		predicate.number, err = strconv.ParseFloat(value, 64)
+		predicate.numeric = err == nil
+	}
+	if !predicate.numeric && op != "==" && op != "!=" {
+		return nil, fmt.Errorf("invalid --where %q: %s needs a number, got %q", expr, op, value)
+	}
+	return predicate, nil
+}
+
+// findWhereOperator returns the position of the comparison operator outside
+// of brackets, braces and quotes, so JSONPath filters like [?(@.x==1)] are
+// part of the path
+func findWhereOperator(expr string) (int, string) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '"' || c == '\'':
+			quote = c
+			continue
+		case c == '[' || c == '(' || c == '{':
+			depth++
+			continue
+		case c == ']' || c == ')' || c == '}':
+			depth--
+			continue
+		}
+		if depth != 0 {
+			continue
+		}
+		for _, op := range whereOperators {
+			if strings.HasPrefix(expr[i:], op) {
+				return i, op
+			}
+		}
+	}
+	return -1, ""
+}
+
+// match reports whether any value the path selects in item satisfies the
+// comparison. Items where the path selects nothing never match.
+func (p *wherePredicate) match(item map[string]interface{}) bool {
+	if p == nil {
+		return true
+	}
+	// The parser's nodes are modified while evaluating negative indices, so
+	// every item gets a freshly parsed path
+	path := jsonpath.New("where").AllowMissingKeys(true)
+	if err := path.Parse(p.path); err != nil {
+		return false
+	}
+	results, err := path.FindResults(item)
+	if err != nil {
+		return false
+	}
+	for _, values := range results {
+		for _, value := range values {
+			if value.IsValid() && value.CanInterface() && p.compare(value.Interface()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compare applies the operator to a single value, numerically when both
+// sides are numbers
+func (p *wherePredicate) compare(value interface{}) bool {
+	text := fmt.Sprint(value)
+	if p.numeric {
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			switch p.op {
+			case "==":
+				return number == p.number
+			case "!=":
+				return number != p.number
+			case ">":
+				return number > p.number
+			case ">=":
+				return number >= p.number
+			case "<":
+				return number < p.number
+			case "<=":
+				return number <= p.number
+			}
+		}
+	}
+	switch p.op {
+	case "==":
+		return text == p.value
+	case "!=":
+		return text != p.value
+	}
+	return false
+}
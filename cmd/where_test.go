@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseWhereErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{name: "no operator", expr: ".status.phase", wantErr: "expected PATH OPERATOR VALUE"},
+		{name: "missing value", expr: ".status.phase ==", wantErr: "missing value"},
+		{name: "missing path", expr: " > 5", wantErr: "missing path"},
+		{name: "ordering a string", expr: ".status.phase > Running", wantErr: "> needs a number"},
+		{name: "bad path", expr: ".status[ == 1", wantErr: "invalid --where"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseWhere(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseWhere(%q) error = %v, want %q", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWhereMatch(t *testing.T) {
+	pod := `{
+		"metadata": {"name": "pod1", "labels": {"app": "web"}},
+		"spec": {"nodeName": "node-a"},
+		"status": {"phase": "Running", "containerStatuses": [{"name": "app", "restartCount": 7}, {"name": "sidecar", "restartCount": 0}]}
+	}`
+	var item map[string]interface{}
+	if err := json.Unmarshal([]byte(pod), &item); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "items[?].status.containerStatuses[0].restartCount > 5", want: true},
+		{expr: ".status.containerStatuses[1].restartCount > 5", want: false},
+		{expr: ".status.containerStatuses[*].restartCount >= 7", want: true},
+		{expr: ".status.containerStatuses[0].restartCount == 7", want: true},
+		{expr: ".status.containerStatuses[0].restartCount<=6", want: false},
+		{expr: "{.spec.nodeName} == node-a", want: true},
+		{expr: ".status.phase != Running", want: false},
+		{expr: `.metadata.labels.app == "web"`, want: true},
+		{expr: ".metadata.labels.app == 'api'", want: false},
+		{expr: `.status.containerStatuses[?(@.name=="sidecar")].restartCount < 1`, want: true},
+		{expr: ".status.missing == x", want: false},
+		{expr: ".status.containerStatuses[5].restartCount > 1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			predicate, err := parseWhere(tt.expr)
+			if err != nil {
+				t.Fatalf("parseWhere(%q) error = %v", tt.expr, err)
+			}
+			if got := predicate.match(item); got != tt.want {
+				t.Errorf("match(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectItemsWhere(t *testing.T) {
+	setGlobal(t, &whereExpr, ".status.phase == Failed")
+	results := []contextResult{
+		{context: "ctx1", output: `{"items": [{"metadata": {"name": "a"}, "status": {"phase": "Running"}}, {"metadata": {"name": "b"}, "status": {"phase": "Failed"}}]}`},
+		{context: "ctx2", output: `{"items": [{"metadata": {"name": "c"}, "status": {"phase": "Failed"}}]}`},
+	}
+
+	items, err := collectItems(results, json.Unmarshal, "JSON", false)
+	if err != nil {
+		t.Fatalf("collectItems() error = %v", err)
+	}
+	var names []string
+	for _, item := range items {
+		names = append(names, item["metadata"].(map[string]interface{})["name"].(string))
+	}
+	if strings.Join(names, ",") != "b,c" {
+		t.Errorf("items = %v, want [b c]", names)
+	}
+}